@@ -0,0 +1,155 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Count of nodes in a chunk of an arena
+const arenaChunkSize = 1024
+
+// Tree of nodes allocated from a per-document arena.
+// `Release()` returns the memory for reuse by subsequent parsing, which cuts GC pressure of services comparing
+// many documents. The tree must not be used after the release, including nodes referenced by differences.
+type PooledTree struct {
+	// Root element; `nil` after the release
+	Root  *Node
+	arena *nodeArena
+}
+
+// Storage of nodes of a document in large chunks.
+type nodeArena struct {
+	chunks [][]Node
+	// Index of the chunk being filled
+	current int
+	// Children collected for open elements by their depth
+	scratch [][]Node
+}
+
+var arenaPool = sync.Pool{New: func() any { return &nodeArena{} }}
+
+// Parses XML string into a tree of nodes allocated from a pooled arena.
+// The tree is the same as of `UnmarshalXMLString`, but raw contents of elements share a single copy of the input.
+//   - xmlString - XML string to unmarshal
+//
+// Returns: the tree which `Release()` should be called when it is no longer needed, and error if any
+func UnmarshalXMLPooled(xmlString string) (*PooledTree, error) {
+	arena := arenaPool.Get().(*nodeArena)
+	root, err := arena.decode(xmlString)
+	if err != nil {
+		arena.reset()
+		arenaPool.Put(arena)
+		return nil, err
+	}
+	return &PooledTree{Root: root, arena: arena}, nil
+}
+
+// Returns nodes of the tree to the pool; subsequent calls do nothing.
+func (tree *PooledTree) Release() {
+	if tree.arena == nil {
+		return
+	}
+	tree.arena.reset()
+	arenaPool.Put(tree.arena)
+	tree.arena = nil
+	tree.Root = nil
+}
+
+// Builds the tree from tokens, so that children lists are allocated from the arena.
+func (arena *nodeArena) decode(xmlString string) (*Node, error) {
+	input := []byte(xmlString)
+	dec := xml.NewDecoder(strings.NewReader(xmlString))
+	start, err := readProlog(dec, documentLimits{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Open elements; children are kept in scratch lists until their parent ends
+	open := []Node{{XMLName: start.Name, Attrs: start.Attr, startEnd: dec.InputOffset()}}
+	arena.scratchAt(0)
+	var text strings.Builder
+	texts := []string{}
+	for len(open) > 0 {
+		tokenStart := dec.InputOffset()
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("unexpected EOF")
+		} else if err != nil {
+			return nil, err
+		}
+
+		depth := len(open) - 1
+		switch t := token.(type) {
+		case xml.StartElement:
+			texts = append(texts, text.String())
+			text.Reset()
+			open = append(open, Node{XMLName: t.Name, Attrs: t.Attr, startEnd: dec.InputOffset()})
+			arena.scratchAt(depth + 1)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			node := &open[depth]
+			node.CharData = text.String()
+			node.Content = input[node.startEnd:tokenStart:tokenStart]
+			node.endOffset = dec.InputOffset()
+			node.Children = arena.alloc(arena.scratch[depth])
+			if depth == 0 {
+				root := &arena.alloc([]Node{*node})[0]
+				root.completeTree(xmlString)
+				return root, nil
+			}
+
+			arena.scratch[depth-1] = append(arena.scratch[depth-1], *node)
+			open = open[:depth]
+			text.Reset()
+			text.WriteString(texts[len(texts)-1])
+			texts = texts[:len(texts)-1]
+		}
+	}
+	return nil, errors.New("no root element")
+}
+
+// Starts collecting children of the open element at the depth.
+func (arena *nodeArena) scratchAt(depth int) {
+	for len(arena.scratch) <= depth {
+		arena.scratch = append(arena.scratch, make([]Node, 0))
+	}
+	arena.scratch[depth] = arena.scratch[depth][:0]
+}
+
+// Copies nodes into the arena.
+//
+// Returns: the list of nodes in the arena with capacity limited to its length, so appending to it reallocates
+func (arena *nodeArena) alloc(nodes []Node) []Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	for arena.current < len(arena.chunks) && cap(arena.chunks[arena.current])-len(arena.chunks[arena.current]) < len(nodes) {
+		arena.current++
+	}
+	if arena.current == len(arena.chunks) {
+		arena.chunks = append(arena.chunks, make([]Node, 0, max(arenaChunkSize, len(nodes))))
+	}
+
+	chunk := arena.chunks[arena.current]
+	start := len(chunk)
+	chunk = append(chunk, nodes...)
+	arena.chunks[arena.current] = chunk
+	return chunk[start:len(chunk):len(chunk)]
+}
+
+// Clears nodes, so that they don't keep the document from garbage collection, and makes chunks reusable.
+func (arena *nodeArena) reset() {
+	for i := range arena.chunks {
+		clear(arena.chunks[i])
+		arena.chunks[i] = arena.chunks[i][:0]
+	}
+	for i := range arena.scratch {
+		clear(arena.scratch[i][:cap(arena.scratch[i])])
+		arena.scratch[i] = arena.scratch[i][:0]
+	}
+	arena.current = 0
+}
@@ -0,0 +1,68 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Asserts that trees have the same nodes including properties that depend on the input.
+func assertSameTrees(assertT *assert.Assertions, expected *Node, actual *Node) {
+	assertT.Equal(expected.XMLName, actual.XMLName)
+	assertT.Equal(expected.Attrs, actual.Attrs)
+	assertT.Equal(string(expected.Content), string(actual.Content))
+	assertT.Equal(expected.CharData, actual.CharData)
+	assertT.Equal(expected.Hash, actual.Hash)
+	assertT.Equal(expected.CDATA, actual.CDATA)
+	assertT.Equal(expected.SelfClosing, actual.SelfClosing)
+	assertT.Equal(expected.Pos, actual.Pos)
+	assertT.Equal(expected.path(), actual.path())
+	assertT.Equal(len(expected.Children), len(actual.Children))
+	for i := range min(len(expected.Children), len(actual.Children)) {
+		assertT.Same(actual, actual.Children[i].Parent)
+		assertSameTrees(assertT, &expected.Children[i], &actual.Children[i])
+	}
+}
+
+func TestUnmarshalXMLPooled(t *testing.T) {
+	assertT := assert.New(t)
+
+	samples := []string{xmlString1, xmlMixed, catalogSample(1500, func(i int) bool { return i%2 == 0 }),
+		`<?xml version="1.0"?><!-- c --><a x="1"><b/>t<![CDATA[<c>]]><?pi x?><d>e</d> tail</a>`}
+	for _, sample := range samples {
+		expected, _ := parseXML(sample)
+		tree, err := UnmarshalXMLPooled(sample)
+		assertT.Nil(err)
+		assertSameTrees(assertT, expected, tree.Root)
+		assertT.Equal(emptyList, CompareNodes(expected, tree.Root).GetMessages())
+		tree.Release()
+		assertT.Nil(tree.Root)
+		tree.Release()
+	}
+
+	_, err := UnmarshalXMLPooled(`<a><b></a>`)
+	assertT.NotNil(err)
+	_, err = UnmarshalXMLPooled(`<a><b>`)
+	assertT.NotNil(err)
+	_, err = UnmarshalXMLPooled(``)
+	assertT.NotNil(err)
+}
+
+func BenchmarkUnmarshalXMLString(b *testing.B) {
+	sample := catalogSample(2000, func(int) bool { return false })
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = UnmarshalXMLString(sample)
+	}
+}
+
+func BenchmarkUnmarshalXMLPooled(b *testing.B) {
+	sample := catalogSample(2000, func(int) bool { return false })
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree, _ := UnmarshalXMLPooled(sample)
+		tree.Release()
+	}
+}
@@ -0,0 +1,67 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Content of an element created with `E` - child element, attribute or text.
+type NodeItem interface {
+	addTo(node *Node)
+}
+
+type attrItem xml.Attr
+
+type textItem string
+
+// Creates an element, e.g. `E("order", A("id", "1"), E("qty", Text("5")))`.
+// Texts are concatenated into own text of the element.
+//   - name - local name of the element or name with namespace in Clark notation `{uri}name`
+//   - items - attributes, texts and child elements
+//
+// Returns: the element with parent pointers and hashes set, ready for comparison
+func E(name string, items ...NodeItem) *Node {
+	node := &Node{XMLName: clarkName(name)}
+	for _, item := range items {
+		item.addTo(node)
+	}
+	node.linkChildren()
+	node.hashCode()
+	return node
+}
+
+// Creates an attribute of an element.
+//   - name - local name of the attribute or name with namespace in Clark notation `{uri}name`
+//   - value - attribute value
+func A(name string, value string) NodeItem {
+	return attrItem{Name: clarkName(name), Value: value}
+}
+
+// Creates text content of an element.
+func Text(text string) NodeItem {
+	return textItem(text)
+}
+
+// Adds a copy of the subtree as a child - `NodeItem` requirement.
+func (node *Node) addTo(parent *Node) {
+	child := node.cloneTree()
+	parent.Children = append(parent.Children, *child)
+}
+
+func (attr attrItem) addTo(node *Node) {
+	node.Attrs = append(node.Attrs, xml.Attr(attr))
+}
+
+func (text textItem) addTo(node *Node) {
+	node.CharData += string(text)
+}
+
+// Converts a name in Clark notation `{uri}name` into XML name.
+func clarkName(name string) xml.Name {
+	if strings.HasPrefix(name, "{") {
+		if end := strings.IndexByte(name, '}'); end > 0 {
+			return xml.Name{Space: name[1:end], Local: name[end+1:]}
+		}
+	}
+	return xml.Name{Local: name}
+}
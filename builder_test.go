@@ -0,0 +1,34 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	assertT := assert.New(t)
+
+	order := E("order", A("id", "1"),
+		E("qty", Text("5")),
+		E("{urn:p}price", A("{urn:c}currency", "EUR"), Text("9."), Text("99")))
+
+	assertT.Equal(xml.Name{Local: "order"}, order.XMLName)
+	assertT.Len(order.Children, 2)
+	assertT.Same(order, order.Children[0].Parent)
+	assertT.Equal("9.99", order.Children[1].CharData)
+	assertT.Equal(xml.Name{Space: "urn:p", Local: "price"}, order.Children[1].XMLName)
+	assertT.Equal(map[string]string{"{urn:c}currency": "EUR"}, order.Children[1].AttrMap())
+
+	parsed, _ := parseXML(`<order id="1"><qty>5</qty><p:price xmlns:p="urn:p" xmlns:c="urn:c" c:currency="EUR">9.99</p:price></order>`)
+	assertT.True(order.Equal(parsed))
+	assertT.Equal(parsed.Hash, order.Hash)
+
+	// Children are copied
+	qty := E("qty", Text("5"))
+	list := E("list", qty, qty)
+	list.Children[0].SetText("6")
+	assertT.Equal("5", qty.CharData)
+	assertT.Equal("5", list.Children[1].CharData)
+}
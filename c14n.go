@@ -0,0 +1,239 @@
+package xmlcomparator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Canonical XML serialization state of an element.
+type c14nScope struct {
+	// Namespace URIs by prefix in scope of the element; default namespace has empty prefix
+	inScope map[string]string
+	// Namespace declarations rendered by output ancestors
+	rendered map[string]string
+	// Count of generated prefixes
+	generated *int
+}
+
+// Serializes the node with its subtree as Canonical XML 1.0 without comments (https://www.w3.org/TR/xml-c14n).
+// Namespaces declared by ancestors are rendered at the node; names keep prefixes of the parsed input as long
+// as they are bound, and text keeps its original whitespace and order around child elements.
+//
+// Returns: canonical form of the element suitable for byte-for-byte comparison or hashing
+func (node *Node) Canonical() string {
+	return node.canonical(false, nil)
+}
+
+// Serializes the node with its subtree as Exclusive Canonical XML 1.0 without comments
+// (https://www.w3.org/TR/xml-exc-c14n) - only namespaces visibly used by an element are declared with it.
+//   - inclusivePrefixes - prefixes treated as in inclusive canonicalization ("InclusiveNamespaces PrefixList");
+//     use "#default" for the default namespace
+//
+// Returns: canonical form of the element suitable for byte-for-byte comparison or hashing
+func (node *Node) ExclusiveCanonical(inclusivePrefixes ...string) string {
+	inclusive := make(map[string]bool, len(inclusivePrefixes))
+	for _, prefix := range inclusivePrefixes {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		inclusive[prefix] = true
+	}
+	return node.canonical(true, inclusive)
+}
+
+func (node *Node) canonical(exclusive bool, inclusive map[string]bool) string {
+	scope := c14nScope{inScope: map[string]string{"": ""}, rendered: map[string]string{"": ""}, generated: new(int)}
+	ancestors := make([]*Node, 0)
+	for ancestor := range node.Ancestors() {
+		ancestors = append(ancestors, ancestor)
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		ancestors[i].declareNamespaces(scope.inScope)
+	}
+
+	var buf strings.Builder
+	node.writeCanonical(&buf, scope, exclusive, inclusive)
+	return buf.String()
+}
+
+// Adds namespace declarations of the element to the map of namespace URIs by prefix.
+func (node *Node) declareNamespaces(inScope map[string]string) {
+	for i := range node.Attrs {
+		attr := &node.Attrs[i]
+		switch {
+		case attrSpace(attr) == "xmlns":
+			inScope[attrName(attr)] = attr.Value
+		case attrSpace(attr) == "" && attrName(attr) == "xmlns":
+			inScope[""] = attr.Value
+		}
+	}
+}
+
+func (node *Node) writeCanonical(buf *strings.Builder, parentScope c14nScope, exclusive bool, inclusive map[string]bool) {
+	scope := c14nScope{inScope: make(map[string]string, len(parentScope.inScope)),
+		rendered: make(map[string]string, len(parentScope.rendered)), generated: parentScope.generated}
+	for prefix, uri := range parentScope.inScope {
+		scope.inScope[prefix] = uri
+	}
+	for prefix, uri := range parentScope.rendered {
+		scope.rendered[prefix] = uri
+	}
+	node.declareNamespaces(scope.inScope)
+
+	// Names are qualified before rendering declarations - nodes created programmatically may need new bindings
+	used := make(map[string]bool)
+	name, prefix := scope.qualify(nodeSpace(node), nodeName(node), node.prefix, true)
+	used[prefix] = true
+	attrs := make([]*xml.Attr, 0, len(node.Attrs))
+	for i := range node.Attrs {
+		if !isNameSpaceAttr(&node.Attrs[i]) {
+			attrs = append(attrs, &node.Attrs[i])
+		}
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrSpace(attrs[i]) != attrSpace(attrs[j]) {
+			return attrSpace(attrs[i]) < attrSpace(attrs[j])
+		}
+		return attrName(attrs[i]) < attrName(attrs[j])
+	})
+	attrNames := make([]string, len(attrs))
+	for i, attr := range attrs {
+		var attrPrefix string
+		attrNames[i], attrPrefix = scope.qualify(attrSpace(attr), attrName(attr), node.attrPrefixes[attr.Name], false)
+		if attrPrefix != "" {
+			used[attrPrefix] = true
+		}
+	}
+
+	prefixes := make([]string, 0, len(scope.inScope))
+	for prefix, uri := range scope.inScope {
+		rendered, ok := parentScope.rendered[prefix]
+		if ok && rendered == uri || !ok && uri == "" || exclusive && !used[prefix] && !inclusive[prefix] {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	buf.WriteString("<" + name)
+	for _, prefix := range prefixes {
+		scope.rendered[prefix] = scope.inScope[prefix]
+		if prefix == "" {
+			buf.WriteString(` xmlns="` + escapeC14NAttr(scope.inScope[prefix]) + `"`)
+		} else {
+			buf.WriteString(" xmlns:" + prefix + `="` + escapeC14NAttr(scope.inScope[prefix]) + `"`)
+		}
+	}
+	for i, attr := range attrs {
+		buf.WriteString(" " + attrNames[i] + `="` + escapeC14NAttr(attr.Value) + `"`)
+	}
+	buf.WriteString(">")
+
+	segments := node.ownTextSegments()
+	for i := range node.Children {
+		buf.WriteString(escapeC14NText(segments[i]))
+		node.Children[i].writeCanonical(buf, scope, exclusive, inclusive)
+	}
+	buf.WriteString(escapeC14NText(segments[len(node.Children)]))
+	buf.WriteString("</" + name + ">")
+}
+
+// Qualified name in the scope; binds a new prefix if the namespace is not declared.
+//   - lexical - prefix of the name in the parsed input, kept while it is bound to the namespace
+//   - element - whether the name is of an element, so the default namespace applies
+//
+// Returns: qualified name and its prefix
+func (scope *c14nScope) qualify(space string, local string, lexical string, element bool) (string, string) {
+	switch {
+	case space == "" && (!element || scope.inScope[""] == ""):
+		return local, ""
+	case space == "":
+		scope.inScope[""] = ""
+		return local, ""
+	case space == xmlNamespace:
+		return "xml:" + local, "xml"
+	case lexical != "" && scope.inScope[lexical] == space:
+		return lexical + ":" + local, lexical
+	case element && scope.inScope[""] == space:
+		return local, ""
+	}
+
+	// The lexically smallest of prefixes bound to the namespace for stable output
+	prefix := ""
+	for p, uri := range scope.inScope {
+		if p != "" && uri == space && (prefix == "" || p < prefix) {
+			prefix = p
+		}
+	}
+	switch {
+	case prefix != "":
+		return prefix + ":" + local, prefix
+	case isUndeclaredPrefix(space):
+		// Relative namespace URIs are bound in scope, so what remains is a prefix without a declaration
+		return space + ":" + local, space
+	case element:
+		scope.inScope[""] = space
+		return local, ""
+	}
+	*scope.generated++
+	prefix = "ns" + strconv.Itoa(*scope.generated)
+	scope.inScope[prefix] = space
+	return prefix + ":" + local, prefix
+}
+
+// Own text of the element split by child elements.
+//
+// Returns: `len(node.Children)+1` segments - before the first child, between children and after the last one
+func (node *Node) ownTextSegments() []string {
+	segments := make([]string, len(node.Children)+1)
+	if len(node.Content) == 0 {
+		segments[0] = node.CharData
+		return segments
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(node.Content))
+	dec.Strict = false
+	depth, index := 0, 0
+	for {
+		token, err := dec.RawToken()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				index = min(index+1, len(node.Children))
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 {
+				segments[index] += string(t)
+			}
+		}
+	}
+
+	// Children modified after parsing are not reflected by the content
+	if strings.Join(segments, "") != node.CharData {
+		segments = make([]string, len(node.Children)+1)
+		segments[0] = node.CharData
+	}
+	return segments
+}
+
+var c14nTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var c14nAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;",
+	"\r", "&#xD;")
+
+func escapeC14NText(text string) string {
+	return c14nTextEscaper.Replace(text)
+}
+
+func escapeC14NAttr(text string) string {
+	return c14nAttrEscaper.Replace(text)
+}
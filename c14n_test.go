@@ -0,0 +1,113 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Example 3.3 of the specification without DTD
+	root, _ := parseXML(`<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`)
+	assertT.Equal(`<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`, root.Canonical())
+
+	// Mixed content, CDATA and escaping
+	root, _ = parseXML("<a t=\"x&#9;&lt;&gt;\">one<b/>two &amp; <![CDATA[<three>]]><c>x</c>four</a>")
+	assertT.Equal("<a t=\"x&#x9;&lt;>\">one<b></b>two &amp; &lt;three&gt;<c>x</c>four</a>", root.Canonical())
+}
+
+func TestCanonicalSubtree(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<r xmlns="urn:d" xmlns:p="urn:p" xmlns:q="urn:q"><p:a q:k="1"><b/></p:a></r>`)
+	node := &root.Children[0]
+	assertT.Equal(`<p:a xmlns="urn:d" xmlns:p="urn:p" xmlns:q="urn:q" q:k="1"><b></b></p:a>`, node.Canonical())
+	assertT.Equal(`<p:a xmlns:p="urn:p" xmlns:q="urn:q" q:k="1"><b xmlns="urn:d"></b></p:a>`, node.ExclusiveCanonical())
+	assertT.Equal(`<p:a xmlns="urn:d" xmlns:p="urn:p" xmlns:q="urn:q" q:k="1"><b></b></p:a>`, node.ExclusiveCanonical("#default"))
+
+	// Canonical forms don't depend on prefixes order and quoting
+	root1, _ := parseXML(`<a xmlns:x="urn:x" xmlns:y="urn:y" y:b='2' x:a="1"/>`)
+	root2, _ := parseXML(`<a y:b="2" xmlns:y="urn:y" x:a="1" xmlns:x="urn:x"></a>`)
+	assertT.Equal(root1.Canonical(), root2.Canonical())
+}
+
+func TestCanonicalPrefixes(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Example of section 2.2 of Exclusive XML Canonicalization
+	root, _ := parseXML(`<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+  <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"/>
+  </n1:elem2>
+</n0:local>`)
+	node := &root.Children[0]
+	assertT.Equal(`<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+    <n3:stuff></n3:stuff>
+  </n1:elem2>`, node.Canonical())
+	assertT.Equal(`<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+    <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+  </n1:elem2>`, node.ExclusiveCanonical())
+
+	// Prefixes of the input are kept when several prefixes are bound to the namespace
+	root, _ = parseXML(`<b:r xmlns:b="http://e.com/ns" xmlns:a="http://e.com/ns" b:k="1" a:j="2"><b:x/><a:y/></b:r>`)
+	expected := `<b:r xmlns:a="http://e.com/ns" xmlns:b="http://e.com/ns" a:j="2" b:k="1"><b:x></b:x><a:y></a:y></b:r>`
+	assertT.Equal(expected, root.Canonical())
+	assertT.Equal(expected, root.ExclusiveCanonical())
+	root, _ = parseXML(`<r xmlns="urn:u" xmlns:p="urn:u"><p:x/><y/></r>`)
+	assertT.Equal(`<r xmlns="urn:u" xmlns:p="urn:u"><p:x></p:x><y></y></r>`, root.Canonical())
+	assertT.Equal(`<r xmlns="urn:u"><p:x xmlns:p="urn:u"></p:x><y></y></r>`, root.ExclusiveCanonical())
+
+	// Relative namespace URIs
+	root, _ = parseXML(`<a:r xmlns:a="u"><a:x/></a:r>`)
+	assertT.Equal(`<a:r xmlns:a="u"><a:x></a:x></a:r>`, root.Canonical())
+	assertT.Equal(`<a:r xmlns:a="u"><a:x></a:x></a:r>`, root.ExclusiveCanonical())
+	root, _ = parseXML(`<r xmlns="u"><x/></r>`)
+	assertT.Equal(`<r xmlns="u"><x></x></r>`, root.ExclusiveCanonical())
+
+	// Modified trees keep prefixes while they are bound
+	root, _ = parseXML(`<b:r xmlns:b="urn:x" xmlns:a="urn:x"><b:x/></b:r>`)
+	root.SetAttr("k", "v")
+	root.AppendChild(E("{urn:x}y"))
+	assertT.Equal(`<b:r xmlns:a="urn:x" xmlns:b="urn:x" k="v"><b:x></b:x><a:y></a:y></b:r>`, root.Canonical())
+}
+
+func TestCanonicalBuiltNodes(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b>1</b></a>`)
+	root.AppendChild(&Node{XMLName: xml.Name{Space: "urn:x", Local: "c"}, CharData: "2"})
+	root.SetAttr("k", "v")
+	assertT.Equal(`<a k="v"><b>1</b><c xmlns="urn:x">2</c></a>`, root.Canonical())
+}
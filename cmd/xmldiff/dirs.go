@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+// Outcome of comparison of files with the same relative path.
+type fileStatus string
+
+const (
+	statusEqual     fileStatus = "equal"
+	statusDifferent fileStatus = "different"
+	// The file is only in the first set
+	statusMissing fileStatus = "missing"
+	// The file is only in the second set
+	statusExtra fileStatus = "extra"
+	statusError fileStatus = "error"
+)
+
+// Result of comparison of files with the same relative path.
+type fileResult struct {
+	Path   string                 `json:"path"`
+	Status fileStatus             `json:"status"`
+	Diffs  xmlcomparator.DiffList `json:"diffs"`
+}
+
+// Checks whether the argument denotes a set of files - a directory or a glob pattern.
+func isFileSet(arg string) bool {
+	if isURL(arg) || arg == "-" {
+		return false
+	}
+	if strings.ContainsAny(arg, "*?[") {
+		return true
+	}
+	stat, err := os.Stat(arg)
+	return err == nil && stat.IsDir()
+}
+
+// Extensions of compared files in directories; compressed files are decompressed by the library.
+var xmlExtensions = []string{".xml", ".xml.gz", ".xml.bz2", ".zip"}
+
+// Checks whether the file in a directory is compared.
+func isXMLFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range xmlExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lists files of a directory tree or matching a glob pattern.
+//
+// Returns: paths of files by their paths relative to the directory or the fixed part of the pattern
+func listFiles(arg string) (map[string]string, error) {
+	files := make(map[string]string)
+	if !strings.ContainsAny(arg, "*?[") {
+		err := filepath.WalkDir(arg, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !isXMLFile(entry.Name()) {
+				return err
+			}
+			rel, err := filepath.Rel(arg, path)
+			files[filepath.ToSlash(rel)] = path
+			return err
+		})
+		return files, err
+	}
+
+	matches, err := filepath.Glob(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", arg, err)
+	}
+	base := filepath.Dir(arg[:strings.IndexAny(arg, "*?[")+1])
+	for _, match := range matches {
+		if stat, err := os.Stat(match); err != nil || stat.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(base, match)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(rel)] = match
+	}
+	return files, nil
+}
+
+// Compares files of two sets pairwise by relative paths.
+//
+// Returns: results sorted by relative paths
+func compareFileSets(arg1 string, arg2 string, opts []xmlcomparator.Option) ([]fileResult, error) {
+	files1, err := listFiles(arg1)
+	if err != nil {
+		return nil, err
+	}
+	files2, err := listFiles(arg2)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := xmlcomparator.NewComparator(opts...)
+	results := make([]fileResult, 0, len(files1)+len(files2))
+	for rel, path1 := range files1 {
+		path2, ok := files2[rel]
+		if !ok {
+			results = append(results, fileResult{Path: rel, Status: statusMissing})
+			continue
+		}
+		diffs := cmp.CompareFiles(path1, path2).GetStructuredDiffs()
+		results = append(results, fileResult{Path: rel, Status: diffsStatus(diffs), Diffs: diffs})
+	}
+	for rel := range files2 {
+		if _, ok := files1[rel]; !ok {
+			results = append(results, fileResult{Path: rel, Status: statusExtra})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, nil
+}
+
+func diffsStatus(diffs xmlcomparator.DiffList) fileStatus {
+	switch exitStatus(diffs) {
+	case exitError:
+		return statusError
+	case exitDifferent:
+		return statusDifferent
+	}
+	return statusEqual
+}
+
+// Writes differences of each file followed by a summary table, or all results as a JSON array.
+func printResults(w io.Writer, results []fileResult, output settings) error {
+	if output.json {
+		for i := range results {
+			if results[i].Diffs == nil {
+				results[i].Diffs = xmlcomparator.DiffList{}
+			}
+		}
+		data, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	counts := make(map[fileStatus]int)
+	for i := range results {
+		counts[results[i].Status]++
+		if len(results[i].Diffs) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "=== %s\n", results[i].Path); err != nil {
+			return err
+		}
+		if err := report.WriteText(w, results[i].Diffs, output.color); err != nil {
+			return err
+		}
+	}
+
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "FILE\tSTATUS\tDIFFERENCES")
+	for i := range results {
+		diffs := "-"
+		if results[i].Status == statusEqual || results[i].Status == statusDifferent {
+			diffs = fmt.Sprint(len(results[i].Diffs))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", results[i].Path, results[i].Status, diffs)
+	}
+	fmt.Fprintf(table, "\n%d files: %d equal, %d different, %d missing, %d extra, %d errors\n", len(results),
+		counts[statusEqual], counts[statusDifferent], counts[statusMissing], counts[statusExtra], counts[statusError])
+	return table.Flush()
+}
+
+// Exit status of comparison of file sets - the most severe of statuses of files.
+func resultsStatus(results []fileResult) int {
+	status := exitEqual
+	for i := range results {
+		switch results[i].Status {
+		case statusError:
+			return exitError
+		case statusDifferent, statusMissing, statusExtra:
+			status = exitDifferent
+		}
+	}
+	return status
+}
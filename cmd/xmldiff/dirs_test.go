@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates a directory tree with files.
+//   - files - contents by relative paths
+func writeTree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestDiffDirectories(t *testing.T) {
+	assertT := assert.New(t)
+
+	dir1 := writeTree(t, map[string]string{"a.xml": `<a/>`, "sub/b.xml": `<b>1</b>`, "c.xml": `<c/>`, "notes.txt": "x"})
+	dir2 := writeTree(t, map[string]string{"a.xml": `<a/>`, "sub/b.xml": `<b>2</b>`, "d.xml": `<d/>`})
+
+	status, stdout, stderr := runCommand("--color=never", dir1, dir2)
+	assertT.Equal(exitDifferent, status)
+	assertT.Empty(stderr)
+	assertT.Equal(`=== sub/b.xml
+~ /b TextChanged: '1' -> '2'
+FILE       STATUS     DIFFERENCES
+a.xml      equal      0
+c.xml      missing    -
+d.xml      extra      -
+sub/b.xml  different  1
+
+4 files: 1 equal, 1 different, 1 missing, 1 extra, 0 errors
+`, stdout)
+
+	status, stdout, _ = runCommand("--json", dir1, dir2)
+	assertT.Equal(exitDifferent, status)
+	var results []map[string]any
+	assertT.Nil(json.Unmarshal([]byte(stdout), &results))
+	assertT.Len(results, 4)
+	assertT.Equal(map[string]any{"path": "c.xml", "status": "missing", "diffs": []any{}}, results[1])
+	assertT.Len(results[3]["diffs"], 1)
+
+	status, _, _ = runCommand(dir1, dir1)
+	assertT.Equal(exitEqual, status)
+}
+
+func TestDiffCompressedFiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write([]byte(`<a>1</a>`))
+	assertT.Nil(writer.Close())
+	dir1 := writeTree(t, map[string]string{"a.xml.gz": buf.String(), "b.xml": `<b/>`})
+	dir2 := writeTree(t, map[string]string{"a.xml.gz": buf.String(), "b.xml": `<b/>`})
+
+	status, stdout, _ := runCommand(dir1, dir2)
+	assertT.Equal(exitEqual, status)
+	assertT.Contains(stdout, "a.xml.gz  equal   0")
+
+	status, _, _ = runCommand(filepath.Join(dir1, "a.xml.gz"), filepath.Join(dir1, "b.xml"))
+	assertT.Equal(exitDifferent, status)
+}
+
+func TestDiffGlobs(t *testing.T) {
+	assertT := assert.New(t)
+
+	dir1 := writeTree(t, map[string]string{"a.xml": `<a/>`, "b.xml": `<b/>`, "b.txt": `<b>`})
+	dir2 := writeTree(t, map[string]string{"a.xml": `<a/>`, "b.xml": `<b/>`, "b.txt": `<c/>`})
+
+	status, stdout, _ := runCommand(filepath.Join(dir1, "*.xml"), filepath.Join(dir2, "*.xml"))
+	assertT.Equal(exitEqual, status)
+	assertT.Contains(stdout, "2 files: 2 equal, 0 different, 0 missing, 0 extra, 0 errors")
+
+	status, stdout, _ = runCommand(filepath.Join(dir1, "b.*"), filepath.Join(dir2, "b.*"))
+	assertT.Equal(exitError, status)
+	assertT.Contains(stdout, "b.txt  error   -")
+}
+
+func TestDiffFileSetErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	dir := writeTree(t, map[string]string{"a.xml": `<a/>`})
+
+	status, _, stderr := runCommand(dir, filepath.Join(dir, "a.xml"))
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: a file can't be compared with a directory or a pattern\n", stderr)
+
+	status, _, stderr = runCommand(dir, filepath.Join(dir, "[.xml"))
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "invalid pattern")
+}
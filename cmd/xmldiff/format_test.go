@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a y="2" x="1"><b> t </b><c></c></a>`)
+
+	status, stdout, _ := runCommand("fmt", paths[0])
+	assertT.Equal(exitEqual, status)
+	assertT.Equal("<a y=\"2\" x=\"1\">\n  <b>t</b>\n  <c/>\n</a>\n", stdout)
+
+	status, stdout, _ = runCommand("fmt", "--indent", "4", paths[0])
+	assertT.Equal(exitEqual, status)
+	assertT.Equal("<a y=\"2\" x=\"1\">\n    <b>t</b>\n    <c/>\n</a>\n", stdout)
+
+	status, stdout, _ = runCommandWithInput(`<a><b/></a>`, "fmt", "--tabs")
+	assertT.Equal(exitEqual, status)
+	assertT.Equal("<a>\n\t<b/>\n</a>\n", stdout)
+}
+
+func TestCanonicalize(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample := `<p:a xmlns:p="urn:p" xmlns:q="urn:q" y="2" x="1"><b> t </b><c/></p:a>`
+
+	status, stdout, _ := runCommandWithInput(sample, "c14n", "-")
+	assertT.Equal(exitEqual, status)
+	assertT.Equal(`<p:a xmlns:p="urn:p" xmlns:q="urn:q" x="1" y="2"><b> t </b><c></c></p:a>`+"\n", stdout)
+
+	status, stdout, _ = runCommandWithInput(sample, "c14n", "--exclusive")
+	assertT.Equal(exitEqual, status)
+	assertT.Equal(`<p:a xmlns:p="urn:p" x="1" y="2"><b> t </b><c></c></p:a>`+"\n", stdout)
+
+	status, stdout, _ = runCommandWithInput(sample, "c14n", "--exclusive", "--inclusive-prefixes", "q")
+	assertT.Equal(exitEqual, status)
+	assertT.Equal(`<p:a xmlns:p="urn:p" xmlns:q="urn:q" x="1" y="2"><b> t </b><c></c></p:a>`+"\n", stdout)
+}
+
+func TestFormatErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	status, _, stderr := runCommandWithInput(`<a>`, "fmt")
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "xmldiff: can't parse -: ")
+
+	status, _, stderr = runCommand("c14n", "1.xml", "2.xml")
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "Usage: xmldiff fmt")
+
+	status, _, _ = runCommand("fmt", "--exclusive")
+	assertT.Equal(exitError, status)
+	status, _, _ = runCommand("c14n", "-h")
+	assertT.Equal(exitEqual, status)
+}
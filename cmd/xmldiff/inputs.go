@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Sources of compared documents.
+type inputSettings struct {
+	// Document read for `-` argument
+	stdin io.Reader
+	// Settings of downloading documents from URLs
+	timeout time.Duration
+	headers http.Header
+}
+
+// Creates input settings with flags of downloading documents.
+func newInputSettings(flags *flag.FlagSet, stdin io.Reader) *inputSettings {
+	inputs := &inputSettings{stdin: stdin, headers: make(http.Header)}
+	flags.DurationVar(&inputs.timeout, "timeout", 30*time.Second, "timeout of downloading URLs")
+	flags.Var(headerFlags(inputs.headers), "header", "HTTP header 'Name: value' of URL requests; repeatable")
+	return inputs
+}
+
+// Repeatable `--header 'Name: value'` flag.
+type headerFlags http.Header
+
+func (headers headerFlags) String() string {
+	lines := make([]string, 0, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return strings.Join(lines, ", ")
+}
+
+func (headers headerFlags) Set(header string) error {
+	name, value, ok := strings.Cut(header, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header '%s' should have the form 'Name: value'", header)
+	}
+	http.Header(headers).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+func isURL(arg string) bool {
+	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
+}
+
+// Opens a compared document - standard input for `-`, a URL or a file, decompressed by the extension.
+func openInput(arg string, inputs inputSettings) (io.ReadCloser, error) {
+	switch {
+	case arg == "-":
+		return io.NopCloser(inputs.stdin), nil
+	case isURL(arg):
+		return download(arg, inputs)
+	}
+	return xmlcomparator.OpenXmlFile(arg)
+}
+
+// Sends GET request to the URL.
+//
+// Returns: body of the successful response
+func download(url string, inputs inputSettings) (io.ReadCloser, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range inputs.headers {
+		request.Header[name] = values
+	}
+
+	client := &http.Client{Timeout: inputs.timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	return response.Body, nil
+}
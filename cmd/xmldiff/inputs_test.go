@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffURLs(t *testing.T) {
+	assertT := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.xml":
+			fmt.Fprint(w, `<a>1</a>`)
+		case "/b.xml":
+			fmt.Fprint(w, `<a>2</a>`)
+		case "/private.xml":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			fmt.Fprint(w, `<a>1</a>`)
+		case "/slow.xml":
+			time.Sleep(200 * time.Millisecond)
+			fmt.Fprint(w, `<a>1</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	status, stdout, _ := runCommand(server.URL+"/a.xml", server.URL+"/b.xml")
+	assertT.Equal(exitDifferent, status)
+	assertT.Equal("~ /a TextChanged: '1' -> '2'\n", stdout)
+
+	paths := writeFiles(t, `<a>1</a>`)
+	status, _, _ = runCommand(paths[0], server.URL+"/a.xml?v=1")
+	assertT.Equal(exitEqual, status)
+
+	status, _, stderr := runCommand(server.URL+"/a.xml", server.URL+"/private.xml")
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: GET "+server.URL+"/private.xml: 401 Unauthorized\n", stderr)
+	status, _, _ = runCommand("--header", "Authorization: Bearer token", server.URL+"/a.xml", server.URL+"/private.xml")
+	assertT.Equal(exitEqual, status)
+
+	status, _, _ = runCommand("--timeout", "50ms", server.URL+"/a.xml", server.URL+"/slow.xml")
+	assertT.Equal(exitError, status)
+	status, _, _ = runCommand("--timeout", "5s", server.URL+"/a.xml", server.URL+"/slow.xml")
+	assertT.Equal(exitEqual, status)
+
+	status, _, stderr = runCommand("--header", "no colon", server.URL+"/a.xml", server.URL+"/a.xml")
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "header 'no colon' should have the form 'Name: value'")
+}
@@ -0,0 +1,298 @@
+// Command xmldiff compares two XML files and prints their differences.
+//
+// Usage:
+//
+//	xmldiff [flags] file1.xml file2.xml
+//	xmldiff [flags] https://a.example/feed.xml https://b.example/feed.xml
+//	transform input.xml | xmldiff --quiet expected.xml -
+//	xmldiff [flags] dir1 dir2
+//	xmldiff [flags] 'glob1/*.xml' 'glob2/*.xml'
+//	xmldiff --report diff.html file1.xml file2.xml
+//	xmldiff watch [--interval d] file1.xml file2.xml
+//	xmldiff merge [--output file] BASE MINE THEIRS
+//	xmldiff fmt [--indent n] [file.xml]
+//	xmldiff c14n [--exclusive] [file.xml]
+//
+// Argument `-` reads one of documents from the standard input.
+// Directory trees and files matching glob patterns are compared pairwise by relative paths.
+// Files with extensions `.gz`, `.bz2` and `.zip` are decompressed.
+//
+// Subcommand `watch` re-compares files whenever either of them changes and prints new and fixed differences.
+// Subcommand `merge` performs a three-way merge and prints conflicts, e.g. as a git merge driver.
+// Subcommands `fmt` and `c14n` pretty-print or canonicalize a single document, e.g. for textual diff tools.
+//
+// Exit status is 0 if documents are equal, 1 if they differ and 2 on errors, e.g. unreadable or malformed files.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+// Exit statuses
+const (
+	exitEqual     = 0
+	exitDifferent = 1
+	exitError     = 2
+)
+
+const usage = `Usage: xmldiff [flags] file1.xml file2.xml
+       xmldiff [flags] https://a.example/feed.xml https://b.example/feed.xml
+       xmldiff [flags] dir1 dir2
+       xmldiff [flags] 'glob1/*.xml' 'glob2/*.xml'
+       xmldiff watch [flags] file1.xml file2.xml
+       xmldiff merge [flags] BASE MINE THEIRS
+       xmldiff fmt|c14n [flags] [file.xml]
+
+Compares XML documents and prints their differences; "-" reads a document from the standard input,
+--quiet only sets the exit status. Directory trees (*.xml, *.xml.gz, *.xml.bz2
+and *.zip files) and files matching glob patterns are compared pairwise by relative paths followed
+by a summary table. Compressed files are decompressed. Subcommand watch re-compares files on
+changes, merge merges versions three-way, fmt and c14n pretty-print or canonicalize a single
+document; run them with -h for flags.
+Exit status is 0 if documents are equal, 1 if they differ and 2 on errors.
+
+Flags:
+`
+
+// Output settings from command line flags.
+type settings struct {
+	json  bool
+	color report.ColorMode
+	// Path of the HTML report; no report if empty
+	report string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Runs the command with arguments without the program name.
+//
+// Returns: exit status
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) != 0 {
+		switch args[0] {
+		case "fmt", "c14n":
+			return runFormat(args[0], args[1:], stdin, stdout, stderr)
+		case "merge":
+			return runMerge(args[1:], stdin, stdout, stderr)
+		case "watch":
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return runWatch(ctx, args[1:], stdout, stderr)
+		}
+	}
+
+	flags := flag.NewFlagSet("xmldiff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	var output settings
+	var color string
+	var stopOnFirst, quiet bool
+	inputs := newInputSettings(flags, stdin)
+	comparison := newComparisonSettings(flags)
+	flags.BoolVar(&output.json, "json", false, "print differences as a JSON array")
+	flags.StringVar(&color, "color", "auto", "colors of differences: auto, always or never")
+	flags.StringVar(&output.report, "report", "", "write side-by-side HTML report of two documents to the file")
+	flags.BoolVar(&quiet, "quiet", false, "print no differences, only set the exit status")
+	flags.BoolVar(&stopOnFirst, "stop-on-first", false, "stop at the first difference")
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return exitEqual
+	} else if err != nil {
+		return exitError
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitError
+	}
+
+	var err error
+	if output.color, err = parseColorMode(color); err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+	opts, err := comparison.options()
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+	// Any difference decides the exit status unless the report shows them
+	if stopOnFirst || quiet && output.report == "" {
+		opts = append(opts, xmlcomparator.WithStopOnFirst())
+	}
+
+	if quiet {
+		stdout = io.Discard
+	}
+
+	arg1, arg2 := flags.Arg(0), flags.Arg(1)
+	if arg1 == "-" && arg2 == "-" {
+		fmt.Fprintln(stderr, "xmldiff: only one of documents can be read from the standard input")
+		return exitError
+	}
+	if (isFileSet(arg1) || isFileSet(arg2)) && output.report != "" {
+		fmt.Fprintln(stderr, "xmldiff: --report requires two documents")
+		return exitError
+	}
+	if isFileSet(arg1) || isFileSet(arg2) {
+		return runFileSets(arg1, arg2, opts, output, stdout, stderr)
+	}
+
+	diffs, err := compareInputs(arg1, arg2, *inputs, opts, output.report)
+	if err == nil {
+		err = printDiffs(stdout, diffs, output)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+	return exitStatus(diffs)
+}
+
+// Comparison rules from command line flags.
+type comparisonSettings struct {
+	configPath  string
+	ignoreOrder bool
+}
+
+// Creates comparison settings with their flags.
+func newComparisonSettings(flags *flag.FlagSet) *comparisonSettings {
+	comparison := &comparisonSettings{}
+	flags.StringVar(&comparison.configPath, "config", "", "YAML or JSON file with comparison rules")
+	flags.BoolVar(&comparison.ignoreOrder, "ignore-order", false, "match children regardless of their order")
+	return comparison
+}
+
+// Comparison options of the rules file followed by options of flags.
+func (comparison *comparisonSettings) options() ([]xmlcomparator.Option, error) {
+	opts := make([]xmlcomparator.Option, 0)
+	if comparison.configPath != "" {
+		config, err := xmlcomparator.LoadConfig(comparison.configPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, config.Options()...)
+	}
+	if comparison.ignoreOrder {
+		opts = append(opts, xmlcomparator.WithIgnoreOrder())
+	}
+	return opts, nil
+}
+
+// Compares documents from files or URLs.
+//   - reportPath - path of the HTML report, if not empty
+//
+// Returns: differences or error if an input can't be opened or the report can't be written
+func compareInputs(arg1 string, arg2 string, inputs inputSettings, opts []xmlcomparator.Option,
+	reportPath string) (xmlcomparator.DiffList, error) {
+	input1, err := openInput(arg1, inputs)
+	if err != nil {
+		return nil, err
+	}
+	defer input1.Close()
+	input2, err := openInput(arg2, inputs)
+	if err != nil {
+		return nil, err
+	}
+	defer input2.Close()
+
+	if reportPath == "" {
+		return xmlcomparator.CompareXmlReaders(input1, input2, opts...).GetStructuredDiffs(), nil
+	}
+
+	// The report renders documents, so they are kept in memory
+	sample1, err := io.ReadAll(input1)
+	if err != nil {
+		return nil, err
+	}
+	sample2, err := io.ReadAll(input2)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeReport(reportPath, string(sample1), string(sample2), opts); err != nil {
+		return nil, err
+	}
+	return xmlcomparator.Compare(string(sample1), string(sample2), opts...).GetStructuredDiffs(), nil
+}
+
+// Writes the side-by-side HTML report of documents comparison.
+func writeReport(path string, sample1 string, sample2 string, opts []xmlcomparator.Option) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = report.WriteHTML(file, sample1, sample2, opts...); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Compares directory trees or files matching glob patterns.
+//
+// Returns: exit status
+func runFileSets(arg1 string, arg2 string, opts []xmlcomparator.Option, output settings, stdout io.Writer, stderr io.Writer) int {
+	if !isFileSet(arg1) || !isFileSet(arg2) {
+		fmt.Fprintln(stderr, "xmldiff: a file can't be compared with a directory or a pattern")
+		return exitError
+	}
+	results, err := compareFileSets(arg1, arg2, opts)
+	if err == nil {
+		err = printResults(stdout, results, output)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+	return resultsStatus(results)
+}
+
+func parseColorMode(color string) (report.ColorMode, error) {
+	switch color {
+	case "auto":
+		return report.ColorAuto, nil
+	case "always":
+		return report.ColorAlways, nil
+	case "never":
+		return report.ColorNever, nil
+	}
+	return report.ColorAuto, fmt.Errorf("invalid color mode '%s'", color)
+}
+
+// Writes differences as text lines or a JSON array.
+func printDiffs(w io.Writer, diffs xmlcomparator.DiffList, output settings) error {
+	if !output.json {
+		return report.WriteText(w, diffs, output.color)
+	}
+
+	data, err := xmlcomparator.DiffsToJSON(diffs)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Exit status of the comparison - documents that failed to parse are errors rather than differences.
+func exitStatus(diffs xmlcomparator.DiffList) int {
+	switch {
+	case len(diffs.OfKind(xmlcomparator.ParseFailed)) != 0:
+		return exitError
+	case len(diffs) != 0:
+		return exitDifferent
+	}
+	return exitEqual
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes files to a temporary directory.
+//
+// Returns: paths of files in the order of arguments
+func writeFiles(t *testing.T, contents ...string) []string {
+	dir := t.TempDir()
+	paths := make([]string, len(contents))
+	for i, content := range contents {
+		paths[i] = filepath.Join(dir, "sample"+string(rune('1'+i))+".xml")
+		assert.Nil(t, os.WriteFile(paths[i], []byte(content), 0o644))
+	}
+	return paths
+}
+
+// Runs the command with arguments.
+//
+// Returns: exit status, standard output and standard error
+func runCommand(args ...string) (int, string, string) {
+	return runCommandWithInput("", args...)
+}
+
+// Runs the command with arguments and the standard input.
+//
+// Returns: exit status, standard output and standard error
+func runCommandWithInput(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestDiffFiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a x="1"><b>1</b></a>`, `<a x="2"><b>1</b></a>`, `<a x="1"><b>1</b></a>`)
+
+	status, stdout, stderr := runCommand(paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	assertT.Equal("~ /a @x: '1' -> '2'\n", stdout)
+	assertT.Empty(stderr)
+
+	status, stdout, _ = runCommand(paths[0], paths[2])
+	assertT.Equal(exitEqual, status)
+	assertT.Empty(stdout)
+
+	status, stdout, _ = runCommand("--json", paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	assertT.True(strings.HasPrefix(stdout, `[{"kind":"AttrChanged","code":"XC008","name":"x",`))
+
+	status, stdout, _ = runCommand("--json", paths[0], paths[2])
+	assertT.Equal(exitEqual, status)
+	assertT.Equal("[]\n", stdout)
+}
+
+func TestDiffOptions(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a><b/><c/></a>`, `<a><c/><b/></a>`)
+
+	status, _, _ := runCommand(paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	status, _, _ = runCommand("-ignore-order", paths[0], paths[1])
+	assertT.Equal(exitEqual, status)
+}
+
+func TestDiffStdin(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a><b>1</b></a>`)
+
+	status, stdout, _ := runCommandWithInput(`<a><b>2</b></a>`, paths[0], "-")
+	assertT.Equal(exitDifferent, status)
+	assertT.Equal("~ /a/b TextChanged: '1' -> '2'\n", stdout)
+	status, _, _ = runCommandWithInput(`<a><b>1</b></a>`, "-", paths[0])
+	assertT.Equal(exitEqual, status)
+
+	status, _, stderr := runCommandWithInput(`<a/>`, "-", "-")
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: only one of documents can be read from the standard input\n", stderr)
+}
+
+func TestDiffQuiet(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a><b>1</b><c>1</c></a>`, `<a><b>2</b><c>2</c></a>`, `<a>`)
+
+	status, stdout, stderr := runCommand("--quiet", paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	assertT.Empty(stdout)
+	assertT.Empty(stderr)
+	status, stdout, _ = runCommand("--quiet", paths[0], paths[0])
+	assertT.Equal(exitEqual, status)
+	assertT.Empty(stdout)
+	status, stdout, _ = runCommand("--quiet", "--json", paths[0], paths[2])
+	assertT.Equal(exitError, status)
+	assertT.Empty(stdout)
+	status, stdout, _ = runCommand("--quiet", filepath.Dir(paths[0]), filepath.Dir(paths[0]))
+	assertT.Equal(exitError, status)
+	assertT.Empty(stdout)
+}
+
+func TestDiffReport(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a><b>1</b><c>1</c></a>`, `<a><b>2</b><c>2</c></a>`)
+	reportPath := filepath.Join(t.TempDir(), "report.html")
+
+	status, stdout, _ := runCommand("--quiet", "--report", reportPath, paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	assertT.Empty(stdout)
+	page, err := os.ReadFile(reportPath)
+	assertT.Nil(err)
+	assertT.True(strings.HasPrefix(string(page), "<!DOCTYPE html>"))
+	assertT.Contains(string(page), "<p>2 difference(s)</p>")
+
+	status, _, _ = runCommandWithInput(`<a><b>1</b><c>1</c></a>`, "--report", reportPath, paths[0], "-")
+	assertT.Equal(exitEqual, status)
+	page, _ = os.ReadFile(reportPath)
+	assertT.Contains(string(page), "<p>0 difference(s)</p>")
+
+	status, _, stderr := runCommand("--report", reportPath, filepath.Dir(paths[0]), filepath.Dir(paths[1]))
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: --report requires two documents\n", stderr)
+
+	status, _, _ = runCommand("--report", filepath.Join(reportPath, "missing", "report.html"), paths[0], paths[1])
+	assertT.Equal(exitError, status)
+}
+
+func TestDiffConfig(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a ts="1"><b id="1">1</b><b id="2">2</b></a>`, `<a ts="2"><b id="2">2</b><b id="1">1</b></a>`,
+		"ignoredAttributes: [ts]\nignoreOrder: true\n", "ignoredAttrs: [ts]\n")
+
+	status, _, _ := runCommand(paths[0], paths[1])
+	assertT.Equal(exitDifferent, status)
+	status, _, _ = runCommand("--config", paths[2], paths[0], paths[1])
+	assertT.Equal(exitEqual, status)
+
+	status, _, stderr := runCommand("--config", paths[3], paths[0], paths[1])
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "xmldiff: invalid config")
+}
+
+func TestDiffErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a/>`, `<a>`)
+
+	status, stdout, _ := runCommand(paths[0], paths[1])
+	assertT.Equal(exitError, status)
+	assertT.Contains(stdout, "! Can't parse the second sample")
+
+	status, _, _ = runCommand(paths[0], filepath.Join(t.TempDir(), "missing.xml"))
+	assertT.Equal(exitError, status)
+
+	status, _, stderr := runCommand(paths[0])
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "Usage: xmldiff")
+
+	status, _, stderr = runCommand("--color", "pink", paths[0], paths[0])
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: invalid color mode 'pink'\n", stderr)
+
+	status, _, _ = runCommand("--unknown", paths[0], paths[0])
+	assertT.Equal(exitError, status)
+
+	status, _, _ = runCommand("-h")
+	assertT.Equal(exitEqual, status)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+const mergeUsage = `Usage: xmldiff merge [flags] BASE MINE THEIRS
+
+Merges changes of MINE and THEIRS versions since their common BASE and prints conflicts.
+Conflicting parts keep MINE version. The merged document keeps the prolog, comments and
+formatting of MINE version. Exit status is 0 without conflicts, 1 with conflicts and 2 on
+errors. As a git merge driver (see gitattributes(5)):
+
+    git config merge.xml.driver 'xmldiff merge --output %A %O %A %B'
+
+Flags:
+`
+
+// Runs `merge` subcommand performing a three-way merge.
+//   - args - arguments after the subcommand
+//
+// Returns: exit status
+func runMerge(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("xmldiff merge", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, mergeUsage)
+		flags.PrintDefaults()
+	}
+
+	inputs := newInputSettings(flags, stdin)
+	comparison := newComparisonSettings(flags)
+	var outputPath string
+	flags.StringVar(&outputPath, "output", "", "write the merged document to the file, e.g. MINE for git")
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return exitEqual
+	} else if err != nil {
+		return exitError
+	}
+	if flags.NArg() != 3 {
+		flags.Usage()
+		return exitError
+	}
+
+	result, err := mergeInputs(flags.Args(), *inputs, comparison)
+	if err == nil && outputPath != "" {
+		err = os.WriteFile(outputPath, []byte(result.Text), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+
+	for _, conflict := range result.Conflicts {
+		fmt.Fprintln(stdout, conflict)
+	}
+	if len(result.Conflicts) != 0 {
+		return exitDifferent
+	}
+	return exitEqual
+}
+
+// Reads versions and merges them.
+//   - args - base, mine and theirs versions
+func mergeInputs(args []string, inputs inputSettings, comparison *comparisonSettings) (*xmlcomparator.MergeResult, error) {
+	opts, err := comparison.options()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]string, len(args))
+	for i, arg := range args {
+		input, err := openInput(arg, inputs)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(input)
+		input.Close()
+		if err != nil {
+			return nil, err
+		}
+		samples[i] = string(data)
+	}
+	return xmlcomparator.Merge(samples[0], samples[1], samples[2], opts...)
+}
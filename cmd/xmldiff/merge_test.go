@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a x="1"><b>1</b><c>1</c></a>`, `<a x="2"><b>2</b><c>1</c></a>`, `<a x="1"><b>1</b><c>3</c></a>`,
+		`<a x="3"><b>3</b><c>1</c></a>`)
+
+	status, stdout, _ := runCommand("merge", "--output", paths[1], paths[0], paths[1], paths[2])
+	assertT.Equal(exitEqual, status)
+	assertT.Empty(stdout)
+	merged, err := os.ReadFile(paths[1])
+	assertT.Nil(err)
+	assertT.Equal(`<a x="2"><b>2</b><c>3</c></a>`, string(merged))
+
+	status, stdout, _ = runCommand("merge", paths[0], paths[1], paths[3])
+	assertT.Equal(exitDifferent, status)
+	assertT.Equal(`Conflicting attribute 'x': base '1', mine '2', theirs '3', path='/a'
+Conflicting text: base '1', mine '2', theirs '3', path='/a/b[0]'
+`, stdout)
+
+	status, _, _ = runCommandWithInput(`<a x="1"><b>1</b><c>1</c></a>`, "merge", "-", paths[1], paths[1])
+	assertT.Equal(exitEqual, status)
+
+	// Formatting of mine version is kept
+	paths = writeFiles(t, "<a>\n  <b>1</b>\n  <c/>\n</a>\n", "<?xml version=\"1.0\"?>\n<a>\n  <!-- b -->\n  <b>2</b>\n  <c/>\n</a>\n",
+		`<a><b>1</b><c/><d/></a>`)
+	status, _, _ = runCommand("merge", "--output", paths[1], paths[0], paths[1], paths[2])
+	assertT.Equal(exitEqual, status)
+	merged, _ = os.ReadFile(paths[1])
+	assertT.Equal("<?xml version=\"1.0\"?>\n<a>\n  <!-- b -->\n  <b>2</b>\n  <c/>\n  <d/>\n</a>\n", string(merged))
+}
+
+func TestMergeErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a/>`, `<a>`)
+
+	status, _, stderr := runCommand("merge", paths[0], paths[0])
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "Usage: xmldiff merge")
+
+	status, _, stderr = runCommand("merge", paths[0], paths[1], paths[0])
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "xmldiff: can't parse the mine version")
+
+	status, _, _ = runCommand("merge", paths[0], paths[0], paths[0]+".missing")
+	assertT.Equal(exitError, status)
+	status, _, _ = runCommand("merge", "-h")
+	assertT.Equal(exitEqual, status)
+}
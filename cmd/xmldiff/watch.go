@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+const watchUsage = `Usage: xmldiff watch [flags] file1.xml file2.xml
+
+Compares files whenever either of them changes and prints new and fixed differences
+until interrupted. Exit status is the one of the last comparison.
+
+Flags:
+`
+
+// State of a watched file.
+type watchedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// Checks whether the file was modified since the previous check.
+func (file *watchedFile) changed() bool {
+	stat, err := os.Stat(file.path)
+	if err != nil {
+		// Editors may replace files - the file will be compared when it reappears
+		return false
+	}
+	if stat.ModTime().Equal(file.modTime) && stat.Size() == file.size {
+		return false
+	}
+	file.modTime, file.size = stat.ModTime(), stat.Size()
+	return true
+}
+
+// Runs `watch` subcommand until the context is done.
+//   - args - arguments after the subcommand
+//
+// Returns: exit status
+func runWatch(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("xmldiff watch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, watchUsage)
+		flags.PrintDefaults()
+	}
+
+	comparison := newComparisonSettings(flags)
+	var color string
+	var interval time.Duration
+	flags.StringVar(&color, "color", "auto", "colors of differences: auto, always or never")
+	flags.DurationVar(&interval, "interval", 500*time.Millisecond, "interval of checking files for changes")
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return exitEqual
+	} else if err != nil {
+		return exitError
+	}
+	if flags.NArg() != 2 || interval <= 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	mode, err := parseColorMode(color)
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+	opts, err := comparison.options()
+	if err != nil {
+		fmt.Fprintln(stderr, "xmldiff:", err)
+		return exitError
+	}
+
+	watcher := &watcher{session: xmlcomparator.NewComparator(opts...).Incremental(), mode: mode, status: exitError}
+	file1, file2 := &watchedFile{path: flags.Arg(0)}, &watchedFile{path: flags.Arg(1)}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Both files are checked, so that their states are updated
+		if changed1, changed2 := file1.changed(), file2.changed(); changed1 || changed2 {
+			watcher.compare(file1.path, file2.path, stdout, stderr)
+		}
+		select {
+		case <-ctx.Done():
+			return watcher.status
+		case <-ticker.C:
+		}
+	}
+}
+
+// Repeated comparison of files.
+type watcher struct {
+	// Equal subtrees are remembered between comparisons
+	session *xmlcomparator.IncrementalComparison
+	mode    report.ColorMode
+	// Exit status of the last comparison
+	status int
+	// Differences found by the last comparison
+	previous xmlcomparator.DiffList
+}
+
+// Compares files and prints differences which appeared or disappeared since the previous comparison.
+func (watcher *watcher) compare(path1 string, path2 string, stdout io.Writer, stderr io.Writer) {
+	root1, err := xmlcomparator.UnmarshalXMLFile(path1)
+	if err == nil {
+		var root2 *xmlcomparator.Node
+		if root2, err = xmlcomparator.UnmarshalXMLFile(path2); err == nil {
+			watcher.report(watcher.session.CompareNodes(root1, root2).GetStructuredDiffs(), stdout)
+			return
+		}
+	}
+	// Files are often saved partially written
+	fmt.Fprintln(stderr, "xmldiff:", err)
+	watcher.status = exitError
+}
+
+func (watcher *watcher) report(diffs xmlcomparator.DiffList, stdout io.Writer) {
+	added := changedDiffs(diffs, watcher.previous)
+	fixed := changedDiffs(watcher.previous, diffs)
+
+	fmt.Fprintf(stdout, "== %d differences: %d new, %d fixed\n", len(diffs), len(added), len(fixed))
+	_ = report.WriteText(stdout, added, watcher.mode)
+	if len(fixed) != 0 {
+		var buf strings.Builder
+		_ = report.WriteText(&buf, fixed, watcher.mode)
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			fmt.Fprintln(stdout, "fixed:", line)
+		}
+	}
+	watcher.previous = diffs
+	watcher.status = exitStatus(diffs)
+}
+
+// Selects differences absent in the other list; differences are identified by their descriptions.
+func changedDiffs(diffs xmlcomparator.DiffList, other xmlcomparator.DiffList) xmlcomparator.DiffList {
+	counts := make(map[string]int, len(other))
+	for i := range other {
+		counts[other[i].String()]++
+	}
+	ret := make(xmlcomparator.DiffList, 0)
+	for i := range diffs {
+		key := diffs[i].String()
+		if counts[key] > 0 {
+			counts[key]--
+		} else {
+			ret = append(ret, diffs[i])
+		}
+	}
+	return ret
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Buffer written by the watching goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (buffer *syncBuffer) Write(data []byte) (int, error) {
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+	return buffer.buf.Write(data)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+	return buffer.buf.String()
+}
+
+// Rewrites the file making sure its modification time changes.
+func rewriteFile(t *testing.T, path string, content string, modTime time.Time) {
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestWatch(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := writeFiles(t, `<a><b>1</b><c>1</c></a>`, `<a><b>2</b><c>1</c></a>`)
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout, stderr syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- runWatch(ctx, []string{"--interval", "5ms", "--color", "never", paths[0], paths[1]}, &stdout, &stderr)
+	}()
+
+	output := "== 1 differences: 1 new, 0 fixed\n~ /a/b[0] TextChanged: '1' -> '2'\n"
+	assertT.Eventually(func() bool { return stdout.String() == output }, 5*time.Second, 5*time.Millisecond)
+
+	rewriteFile(t, paths[1], `<a><b>1</b><c>2</c></a>`, time.Now().Add(time.Second))
+	output += "== 1 differences: 1 new, 1 fixed\n~ /a/c[1] TextChanged: '1' -> '2'\nfixed: ~ /a/b[0] TextChanged: '1' -> '2'\n"
+	assertT.Eventually(func() bool { return stdout.String() == output }, 5*time.Second, 5*time.Millisecond)
+
+	rewriteFile(t, paths[1], `<a><b>1`, time.Now().Add(2*time.Second))
+	assertT.Eventually(func() bool { return strings.Contains(stderr.String(), "xmldiff: ") }, 5*time.Second, 5*time.Millisecond)
+
+	rewriteFile(t, paths[0], `<a><b>1</b><c>2</c></a>`, time.Now().Add(3*time.Second))
+	rewriteFile(t, paths[1], `<a><b>1</b><c>2</c></a>`, time.Now().Add(3*time.Second))
+	output += "== 0 differences: 0 new, 1 fixed\nfixed: ~ /a/c[1] TextChanged: '1' -> '2'\n"
+	assertT.Eventually(func() bool { return stdout.String() == output }, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	assertT.Equal(exitEqual, <-done)
+}
+
+func TestWatchErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	status, _, stderr := runCommand("watch", "1.xml")
+	assertT.Equal(exitError, status)
+	assertT.Contains(stderr, "Usage: xmldiff watch")
+	status, _, _ = runCommand("watch", "--interval", "0s", "1.xml", "2.xml")
+	assertT.Equal(exitError, status)
+	status, _, stderr = runCommand("watch", "--color", "pink", "1.xml", "2.xml")
+	assertT.Equal(exitError, status)
+	assertT.Equal("xmldiff: invalid color mode 'pink'\n", stderr)
+}
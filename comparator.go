@@ -0,0 +1,76 @@
+package xmlcomparator
+
+import (
+	"context"
+	"io"
+	"regexp"
+)
+
+// Comparison settings prepared once and reusable for many comparisons.
+// Comparator is safe for concurrent use as long as custom value comparators are.
+type Comparator struct {
+	opts                 *compareOptions
+	ignoredDiscrepancies []*regexp.Regexp
+}
+
+// Creates a comparator; options are applied and regular expressions are compiled once.
+//   - opts - comparison options like `WithIgnoreOrder()` or `WithIgnoredPaths(...)`
+func NewComparator(opts ...Option) *Comparator {
+	options := newCompareOptions(opts)
+	return &Comparator{opts: options, ignoredDiscrepancies: compileDiscrepancies(options.ignoredDiscrepancies)}
+}
+
+// Compares two XML strings - see `Compare`.
+func (cmp *Comparator) Compare(sample1 string, sample2 string) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return parseLimitedXML(sample1, limits) },
+		func() (*Node, error) { return parseLimitedXML(sample2, limits) }, cmp.newRecorder())
+}
+
+// Compares two XML strings with cancellation - see `CompareContext`.
+func (cmp *Comparator) CompareContext(ctx context.Context, sample1 string, sample2 string) (DiffRecorder, error) {
+	diffRecorder := cmp.newRecorder()
+	diffRecorder.ctx = ctx
+	if diffRecorder.isCanceled() {
+		diffRecorder.finish()
+		return diffRecorder, diffRecorder.ctxErr
+	}
+
+	limits := cmp.opts.documentLimits()
+	compareInputs(func() (*Node, error) { return parseLimitedXML(sample1, limits) },
+		func() (*Node, error) { return parseLimitedXML(sample2, limits) }, diffRecorder)
+	return diffRecorder, diffRecorder.Err()
+}
+
+// Compares parsed trees - see `CompareNodes`.
+func (cmp *Comparator) CompareNodes(node1 *Node, node2 *Node) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return existingNode(node1, limits) },
+		func() (*Node, error) { return existingNode(node2, limits) }, cmp.newRecorder())
+}
+
+// Compares documents converted to trees by the parser - see `CompareParsed`.
+func (cmp *Comparator) CompareParsed(sample1 string, sample2 string, parse Parser) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return parseLimited(sample1, parse, limits) },
+		func() (*Node, error) { return parseLimited(sample2, parse, limits) }, cmp.newRecorder())
+}
+
+// Compares XML documents read from readers - see `CompareXmlReaders`.
+func (cmp *Comparator) CompareReaders(r1 io.Reader, r2 io.Reader) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return parseLimitedReader(r1, limits) },
+		func() (*Node, error) { return parseLimitedReader(r2, limits) }, cmp.newRecorder())
+}
+
+// Compares XML files - see `CompareXmlFiles`.
+func (cmp *Comparator) CompareFiles(path1 string, path2 string) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return parseFile(path1, limits) },
+		func() (*Node, error) { return parseFile(path2, limits) }, cmp.newRecorder())
+}
+
+// Recorder of a single comparison; settings are shared and not modified during comparison.
+func (cmp *Comparator) newRecorder() *diffRecorder {
+	return newDiffRecorder(cmp.opts, cmp.ignoredDiscrepancies)
+}
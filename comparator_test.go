@@ -0,0 +1,88 @@
+package xmlcomparator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparator(t *testing.T) {
+	assertT := assert.New(t)
+
+	opts := []Option{WithIgnoredDiscrepancies("Attribute"), WithRegexPlaceholders(), WithIgnoredPaths("/note/heading")}
+	cmp := NewComparator(opts...)
+	expected := Compare(xmlString1, xmlMixed, opts...).GetMessages()
+	assertT.Equal(expected, cmp.Compare(xmlString1, xmlMixed).GetMessages())
+
+	root1, _ := parseXML(xmlString1)
+	root2, _ := parseXML(xmlMixed)
+	assertT.Equal(expected, cmp.CompareNodes(root1, root2).GetMessages())
+	assertT.Equal(expected, cmp.CompareReaders(strings.NewReader(xmlString1), strings.NewReader(xmlMixed)).GetMessages())
+
+	// Recorders of comparisons are independent
+	diffs := cmp.Compare(`<a>x</a>`, `<a>y</a>`).GetDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Empty(cmp.Compare(`<a id="${regex:\d+}"/>`, `<a id="12"/>`).GetDiffs())
+}
+
+func TestComparatorConcurrency(t *testing.T) {
+	assertT := assert.New(t)
+
+	cmp := NewComparator(WithRegexPlaceholders(), WithIgnoreOrder(), WithIgnoredXPath("//skip"))
+	var wg sync.WaitGroup
+	counts := make([]int, 16)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts[i] = len(cmp.Compare(`<a><b>${regex:\d+}</b><c/><skip>1</skip></a>`,
+				`<a><c/><b>`+strings.Repeat("1", i+1)+`</b><skip>2</skip><d/></a>`).GetDiffs())
+		}()
+	}
+	wg.Wait()
+	for _, count := range counts {
+		assertT.Equal(1, count)
+	}
+}
+
+func TestCompareContext(t *testing.T) {
+	assertT := assert.New(t)
+
+	recorder, err := CompareContext(context.Background(), xmlString1, xmlMixed)
+	assertT.Nil(err)
+	assertT.Equal(Compare(xmlString1, xmlMixed).GetMessages(), recorder.GetMessages())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder, err = CompareContext(ctx, xmlString1, xmlMixed)
+	assertT.Equal(context.Canceled, err)
+	assertT.Empty(recorder.GetDiffs())
+
+	// Cancellation in the middle of comparison
+	sample1 := catalogSample(1000, func(int) bool { return false })
+	sample2 := catalogSample(1000, func(int) bool { return true })
+	for _, opts := range [][]Option{{}, {WithParallelism(4)}} {
+		var count atomic.Int32
+		ctx, cancel = context.WithCancel(context.Background())
+		recorder, err = NewComparator(append(opts, WithValueComparator(func(string, string, string) (bool, bool) {
+			if count.Add(1) == 100 {
+				cancel()
+			}
+			return false, false
+		}))...).CompareContext(ctx, sample1, sample2)
+		assertT.Equal(context.Canceled, err)
+		assertT.Less(len(recorder.GetDiffs()), 1000)
+		cancel()
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	_, err = CompareContext(ctx, sample1, sample2)
+	assertT.Equal(context.DeadlineExceeded, err)
+}
@@ -0,0 +1,92 @@
+package xmlcomparator
+
+import (
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reader of a decompressed file closing the underlying file.
+type decompressedFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (file *decompressedFile) Close() error {
+	var err error
+	for i := len(file.closers) - 1; i >= 0; i-- {
+		if closeErr := file.closers[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Opens the file for reading decompressing it by the extension as `CompareXmlFiles` does, e.g. to pass it
+// to `CompareXmlReaders` with documents from other sources.
+//   - path - path of the file; `.gz`, `.bz2` and `.zip` files are decompressed, a ZIP archive should contain
+//     a single file or a single `.xml` file
+//
+// Returns: reader of the document and error if the file can't be opened
+func OpenXmlFile(path string) (io.ReadCloser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &decompressedFile{reader, []io.Closer{file, reader}}, nil
+	case ".bz2":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressedFile{bzip2.NewReader(file), []io.Closer{file}}, nil
+	case ".zip":
+		return openZipEntry(path)
+	}
+	return os.Open(path)
+}
+
+func openZipEntry(path string) (io.ReadCloser, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry, xmlEntry *zip.File
+	files, xmlFiles := 0, 0
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		entry, files = file, files+1
+		if strings.EqualFold(filepath.Ext(file.Name), ".xml") {
+			xmlEntry, xmlFiles = file, xmlFiles+1
+		}
+	}
+	switch {
+	case xmlFiles == 1:
+		entry = xmlEntry
+	case files != 1:
+		archive.Close()
+		return nil, fmt.Errorf("%s: archive should contain a single XML file, found %d files", path, files)
+	}
+
+	reader, err := entry.Open()
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return &decompressedFile{reader, []io.Closer{archive, reader}}, nil
+}
@@ -0,0 +1,79 @@
+package xmlcomparator
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compressedSample = `<a><b>1</b></a>`
+
+// `compressedSample` compressed with bzip2
+const bzip2Sample = "\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xa8\x6e\x79\xbb\x00\x00\x02\x99\x00\x00\x00\xa0\x05\x30\x00\x20\x00" +
+	"\x21\x29\xa6\x9e\xa0\xc0\x34\xa5\x82\x22\x93\xc5\xdc\x91\x4e\x14\x24\x2a\x1b\x9e\x6e\xc0"
+
+func gzipped(content string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write([]byte(content))
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+// ZIP archive with files by names.
+func zipped(files ...string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		entry, _ := writer.Create(files[i])
+		_, _ = entry.Write([]byte(files[i+1]))
+	}
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+func TestCompressedFiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"plain.xml":      []byte(compressedSample),
+		"sample.xml.gz":  gzipped(compressedSample),
+		"sample.xml.bz2": []byte(bzip2Sample),
+		"single.zip":     zipped("data", compressedSample),
+		"mixed.zip":      zipped("readme.txt", "text", "data/sample.xml", compressedSample),
+		"other.xml.gz":   gzipped(`<a><b>2</b></a>`),
+		"many.zip":       zipped("1.txt", "", "2.txt", ""),
+		"invalid.gz":     []byte(compressedSample),
+	}
+	for name, content := range files {
+		assertT.Nil(os.WriteFile(filepath.Join(dir, name), content, 0o600))
+	}
+
+	plain := filepath.Join(dir, "plain.xml")
+	for _, name := range []string{"sample.xml.gz", "sample.xml.bz2", "single.zip", "mixed.zip"} {
+		assertT.Empty(CompareXmlFiles(plain, filepath.Join(dir, name)).GetDiffs(), name)
+	}
+	assertT.Equal([]string{"Node texts differ: '1' vs '2', path='/a/b'"},
+		CompareXmlFiles(plain, filepath.Join(dir, "other.xml.gz")).GetMessages())
+
+	root, err := UnmarshalXMLFile(filepath.Join(dir, "sample.xml.bz2"))
+	assertT.NoError(err)
+	assertT.Equal("b", root.Children[0].XMLName.Local)
+
+	_, err = UnmarshalXMLFile(filepath.Join(dir, "many.zip"))
+	assertT.ErrorContains(err, "archive should contain a single XML file, found 2 files")
+	_, err = UnmarshalXMLFile(filepath.Join(dir, "invalid.gz"))
+	assertT.ErrorContains(err, "invalid.gz: gzip: invalid header")
+	_, err = UnmarshalXMLFile(filepath.Join(dir, "missing.zip"))
+	assertT.Error(err)
+
+	// The limit applies to decompressed documents
+	diffs := CompareXmlFiles(plain, filepath.Join(dir, "sample.xml.gz"), WithMaxDocumentSize(10))
+	assertT.Equal(&LimitError{Unit: "bytes", Limit: 10}, diffs.Err())
+}
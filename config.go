@@ -0,0 +1,139 @@
+package xmlcomparator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Comparison rules kept in a YAML or JSON file, so that policies can be versioned and shared between
+// the `xmldiff` command and code - see `LoadConfig`.
+type Config struct {
+	// Elements excluded with their subtrees - see `WithIgnoredXPath`
+	IgnoredXPaths []string `yaml:"ignoredXPaths"`
+	// Paths of excluded elements - see `WithIgnoredPaths`
+	IgnoredPaths []string `yaml:"ignoredPaths"`
+	// Names of excluded attributes with wildcards - see `WithIgnoredAttributes`
+	IgnoredAttributes []string `yaml:"ignoredAttributes"`
+	// Regular expressions of ignored messages - see `WithIgnoredDiscrepancies`
+	IgnoredDiscrepancies []string `yaml:"ignoredDiscrepancies"`
+	// Absolute tolerance of numeric values - see `WithNumericTolerance`
+	NumericTolerance float64 `yaml:"numericTolerance"`
+	// Relative tolerance of numeric values - see `WithRelativeTolerance`
+	RelativeTolerance float64 `yaml:"relativeTolerance"`
+	// Maximal skew of timestamps like "1s" - see `WithTimestampTolerance`
+	TimestampTolerance time.Duration `yaml:"timestampTolerance"`
+	// Matching of all children regardless of order - see `WithIgnoreOrder`
+	IgnoreOrder bool `yaml:"ignoreOrder"`
+	// Paths of elements which children are matched regardless of order - see `WithUnorderedPaths`
+	UnorderedPaths []string `yaml:"unorderedPaths"`
+	// Attributes identifying sibling elements - see `WithKeyAttributes`
+	KeyAttributes []string `yaml:"keyAttributes"`
+	// Whitespace normalization of texts - "trim" (default), "exact" or "collapse"; see `WithWhitespace`
+	Whitespace string `yaml:"whitespace"`
+}
+
+var whitespaceModes = map[string]WhitespaceMode{
+	"":         WhitespaceTrim,
+	"trim":     WhitespaceTrim,
+	"exact":    WhitespaceExact,
+	"collapse": WhitespaceCollapse,
+}
+
+// Reads comparison rules from a YAML or JSON file, e.g.
+//
+//	ignoredXPaths: ["//metadata"]
+//	ignoredAttributes: [timestamp, "session-*"]
+//	numericTolerance: 0.001
+//	unorderedPaths: [/catalog]
+//	keyAttributes: [id]
+//
+// Returns: rules or error if the file can't be read, has unknown fields or invalid expressions
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config '%s': %w", path, err)
+	}
+	return config, nil
+}
+
+// Parses comparison rules in YAML or JSON format - see `LoadConfig`.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Checks expressions, so that options don't panic.
+func (config *Config) validate() error {
+	for _, expr := range config.IgnoredXPaths {
+		if _, err := compileXPath(expr); err != nil {
+			return err
+		}
+	}
+	for _, expr := range config.IgnoredDiscrepancies {
+		if _, err := regexp.Compile(expr); err != nil {
+			return err
+		}
+	}
+	if _, ok := whitespaceModes[config.Whitespace]; !ok {
+		return fmt.Errorf("unknown whitespace mode '%s'", config.Whitespace)
+	}
+	return nil
+}
+
+// Converts rules into comparison options; options passed after them override the rules.
+func (config *Config) Options() []Option {
+	opts := make([]Option, 0)
+	if len(config.IgnoredXPaths) != 0 {
+		opts = append(opts, WithIgnoredXPath(config.IgnoredXPaths...))
+	}
+	if len(config.IgnoredPaths) != 0 {
+		opts = append(opts, WithIgnoredPaths(config.IgnoredPaths...))
+	}
+	if len(config.IgnoredAttributes) != 0 {
+		opts = append(opts, WithIgnoredAttributes(config.IgnoredAttributes...))
+	}
+	if len(config.IgnoredDiscrepancies) != 0 {
+		opts = append(opts, WithIgnoredDiscrepancies(config.IgnoredDiscrepancies...))
+	}
+	if config.NumericTolerance != 0 {
+		opts = append(opts, WithNumericTolerance(config.NumericTolerance))
+	}
+	if config.RelativeTolerance != 0 {
+		opts = append(opts, WithRelativeTolerance(config.RelativeTolerance))
+	}
+	if config.TimestampTolerance != 0 {
+		opts = append(opts, WithTimestampTolerance(config.TimestampTolerance))
+	}
+	if config.IgnoreOrder {
+		opts = append(opts, WithIgnoreOrder())
+	}
+	if len(config.UnorderedPaths) != 0 {
+		opts = append(opts, WithUnorderedPaths(config.UnorderedPaths...))
+	}
+	if len(config.KeyAttributes) != 0 {
+		opts = append(opts, WithKeyAttributes(config.KeyAttributes...))
+	}
+	if config.Whitespace != "" {
+		opts = append(opts, WithWhitespace(whitespaceModes[config.Whitespace]))
+	}
+	return opts
+}
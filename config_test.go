@@ -0,0 +1,70 @@
+package xmlcomparator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	assertT := assert.New(t)
+
+	config, err := ParseConfig([]byte(`
+ignoredXPaths: ["//meta"]
+ignoredAttributes: [ts, "session-*"]
+numericTolerance: 0.01
+timestampTolerance: 2s
+unorderedPaths: [/a]
+keyAttributes: [id]
+whitespace: collapse
+`))
+	assertT.NoError(err)
+	assertT.Equal(&Config{IgnoredXPaths: []string{"//meta"}, IgnoredAttributes: []string{"ts", "session-*"}, NumericTolerance: 0.01,
+		TimestampTolerance: 2 * time.Second, UnorderedPaths: []string{"/a"}, KeyAttributes: []string{"id"}, Whitespace: "collapse"}, config)
+
+	sample1 := `<a ts="1"><meta>x</meta><b id="1">1.00</b><b id="2">a  b</b></a>`
+	sample2 := `<a ts="2"><b id="2">a b</b><b id="1">1.001</b><meta>y</meta></a>`
+	assertT.Equal(emptyList, Compare(sample1, sample2, config.Options()...).GetMessages())
+	assertT.NotEqual(emptyList, Compare(sample1, sample2).GetMessages())
+
+	config, err = ParseConfig([]byte(`{"ignoreOrder": true, "ignoredPaths": ["/a/meta"]}`))
+	assertT.NoError(err)
+	assertT.Equal(&Config{IgnoreOrder: true, IgnoredPaths: []string{"/a/meta"}}, config)
+
+	config, err = ParseConfig([]byte{})
+	assertT.NoError(err)
+	assertT.Empty(config.Options())
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	_, err := ParseConfig([]byte(`ignoreOrdr: true`))
+	assertT.ErrorContains(err, "field ignoreOrdr not found")
+	_, err = ParseConfig([]byte(`ignoredXPaths: ["//a["]`))
+	assertT.Error(err)
+	_, err = ParseConfig([]byte(`ignoredDiscrepancies: ["("]`))
+	assertT.Error(err)
+	_, err = ParseConfig([]byte(`whitespace: none`))
+	assertT.EqualError(err, "unknown whitespace mode 'none'")
+}
+
+func TestLoadConfig(t *testing.T) {
+	assertT := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	assertT.Nil(os.WriteFile(path, []byte(`{"keyAttributes": ["id"]}`), 0o644))
+	config, err := LoadConfig(path)
+	assertT.NoError(err)
+	assertT.Equal([]string{"id"}, config.KeyAttributes)
+
+	assertT.Nil(os.WriteFile(path, []byte(`{"keyAttributes": "id"}`), 0o644))
+	_, err = LoadConfig(path)
+	assertT.ErrorContains(err, "invalid config '"+path+"'")
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assertT.Error(err)
+}
@@ -0,0 +1,257 @@
+package xmlcomparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Kind of an elementary difference between two samples.
+type DiffKind int
+
+const (
+	ElementAdded DiffKind = iota + 1
+	ElementRemoved
+	NameChanged
+	NamespaceChanged
+	TextChanged
+	AttrAdded
+	AttrRemoved
+	AttrChanged
+	OrderChanged
+	ParseFailed
+	CommentChanged
+	ProcInstChanged
+	RepresentationChanged
+	AttrOrderChanged
+	ElementMoved
+	FaultChanged
+)
+
+var diffKindNames = map[DiffKind]string{
+	ElementAdded:          "ElementAdded",
+	ElementRemoved:        "ElementRemoved",
+	NameChanged:           "NameChanged",
+	NamespaceChanged:      "NamespaceChanged",
+	TextChanged:           "TextChanged",
+	AttrAdded:             "AttrAdded",
+	AttrRemoved:           "AttrRemoved",
+	AttrChanged:           "AttrChanged",
+	OrderChanged:          "OrderChanged",
+	ParseFailed:           "ParseFailed",
+	CommentChanged:        "CommentChanged",
+	ProcInstChanged:       "ProcInstChanged",
+	RepresentationChanged: "RepresentationChanged",
+	AttrOrderChanged:      "AttrOrderChanged",
+	ElementMoved:          "ElementMoved",
+	FaultChanged:          "FaultChanged",
+}
+
+// Stable codes of kinds - codes are never reused or changed between releases.
+var diffKindCodes = map[DiffKind]string{
+	ElementRemoved:        "XC001",
+	TextChanged:           "XC002",
+	ElementAdded:          "XC003",
+	NameChanged:           "XC004",
+	NamespaceChanged:      "XC005",
+	AttrAdded:             "XC006",
+	AttrRemoved:           "XC007",
+	AttrChanged:           "XC008",
+	OrderChanged:          "XC009",
+	ParseFailed:           "XC010",
+	CommentChanged:        "XC011",
+	ProcInstChanged:       "XC012",
+	RepresentationChanged: "XC013",
+	AttrOrderChanged:      "XC014",
+	ElementMoved:          "XC015",
+	FaultChanged:          "XC016",
+}
+
+// Stable machine-readable code of the kind, e.g. "XC002" for `TextChanged`; empty for unknown kinds.
+func (kind DiffKind) Code() string {
+	return diffKindCodes[kind]
+}
+
+// Finds the kind by its code.
+//
+// Returns: the kind and whether the code is known
+func DiffKindOfCode(code string) (DiffKind, bool) {
+	for kind, kindCode := range diffKindCodes {
+		if kindCode == code {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+func (kind DiffKind) String() string {
+	if name, ok := diffKindNames[kind]; ok {
+		return name
+	}
+	return fmt.Sprintf("DiffKind(%d)", int(kind))
+}
+
+// Significance of a difference.
+type Severity int
+
+const (
+	// Default severity of differences
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "Error",
+	SeverityWarning: "Warning",
+	SeverityInfo:    "Info",
+}
+
+func (severity Severity) String() string {
+	if name, ok := severityNames[severity]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(severity))
+}
+
+// Serializes the severity as its name, e.g. `"Info"`.
+func (severity Severity) MarshalText() ([]byte, error) {
+	return []byte(severity.String()), nil
+}
+
+// Elementary difference between two samples.
+//
+// Fields related to the sample where the node is missing are left empty.
+type Diff struct {
+	Kind DiffKind
+	// Name of the element or attribute
+	Name string
+	// Paths to the node in the first and second samples
+	Path1 string
+	Path2 string
+	// Values from the first (expected) and second (actual) samples
+	Expected string
+	Actual   string
+	// Nodes in the first and second samples
+	Node1 *Node
+	Node2 *Node
+	// Positions of nodes in the first and second samples; zero for missing nodes
+	Pos1 Position
+	Pos2 Position
+	// Numbered lines of samples around nodes - see `WithContextLines`
+	Context1 string
+	Context2 string
+	// Message of the discrepancy the difference belongs to
+	Message string
+	// Significance of the difference according to `WithSeverity` rules
+	Severity Severity
+}
+
+// List of structured differences.
+type DiffList []Diff
+
+// Describes the difference in a single line.
+func (diff Diff) String() string {
+	switch diff.Kind {
+	case ElementAdded:
+		return fmt.Sprintf("%s: '%s', path='%s'", diff.Kind, diff.Name, diff.Path2)
+	case ElementRemoved, OrderChanged:
+		return fmt.Sprintf("%s: '%s', path='%s'", diff.Kind, diff.Name, diff.Path1)
+	case ElementMoved:
+		return fmt.Sprintf("%s: '%s', path='%s' to '%s'", diff.Kind, diff.Name, diff.Path1, diff.Path2)
+	case AttrAdded, AttrRemoved, AttrChanged:
+		return fmt.Sprintf("%s: '%s' '%s' vs '%s', path='%s'", diff.Kind, diff.Name, diff.Expected, diff.Actual, diff.Path1)
+	case ParseFailed:
+		return fmt.Sprintf("%s: %s", diff.Kind, diff.Message)
+	default:
+		return fmt.Sprintf("%s: '%s' vs '%s', path='%s'", diff.Kind, diff.Expected, diff.Actual, diff.Path1)
+	}
+}
+
+// JSON representation of the difference - nodes are not serialized.
+type jsonDiff struct {
+	Kind     DiffKind `json:"kind"`
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Path1    string   `json:"path1"`
+	Path2    string   `json:"path2"`
+	Expected string   `json:"expected"`
+	Actual   string   `json:"actual"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+	Line1    int      `json:"line1"`
+	Column1  int      `json:"column1"`
+	Line2    int      `json:"line2"`
+	Column2  int      `json:"column2"`
+	Context1 string   `json:"context1"`
+	Context2 string   `json:"context2"`
+}
+
+// Serializes the difference as a JSON object with fields `kind`, `code`, `name`, `path1`, `path2`, `expected`, `actual`,
+// `message`, `severity`, positions `line1`, `column1`, `line2`, `column2` and source snippets `context1`, `context2`;
+// all fields are always present.
+func (diff Diff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonDiff{Kind: diff.Kind, Code: diff.Kind.Code(), Name: diff.Name, Path1: diff.Path1, Path2: diff.Path2,
+		Expected: diff.Expected, Actual: diff.Actual, Message: diff.Message, Severity: diff.Severity,
+		Line1: diff.Pos1.Line, Column1: diff.Pos1.Column, Line2: diff.Pos2.Line, Column2: diff.Pos2.Column,
+		Context1: diff.Context1, Context2: diff.Context2})
+}
+
+// Serializes the kind as its name, e.g. `"TextChanged"`.
+func (kind DiffKind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// Parses the kind from its name.
+func (kind *DiffKind) UnmarshalText(text []byte) error {
+	for k, name := range diffKindNames {
+		if name == string(text) {
+			*kind = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown diff kind '%s'", text)
+}
+
+// Serializes differences as a JSON array.
+func DiffsToJSON(diffs DiffList) ([]byte, error) {
+	if diffs == nil {
+		diffs = DiffList{}
+	}
+	return json.Marshal(diffs)
+}
+
+// Checks whether any of differences has `SeverityError`.
+func (diffs DiffList) HasErrors() bool {
+	for i := range diffs {
+		if diffs[i].Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Drops differences which kinds have the specified codes, e.g. from a suppression list.
+func (diffs DiffList) WithoutCodes(codes ...string) DiffList {
+	ret := make(DiffList, 0, len(diffs))
+	for i := range diffs {
+		if !slices.Contains(codes, diffs[i].Kind.Code()) {
+			ret = append(ret, diffs[i])
+		}
+	}
+	return ret
+}
+
+// Selects differences of the specified kinds.
+func (diffs DiffList) OfKind(kinds ...DiffKind) DiffList {
+	ret := make(DiffList, 0)
+	for i := range diffs {
+		for _, kind := range kinds {
+			if diffs[i].Kind == kind {
+				ret = append(ret, diffs[i])
+				break
+			}
+		}
+	}
+	return ret
+}
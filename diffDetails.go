@@ -18,6 +18,13 @@ const (
 	DiffChildren
 	DiffChildrenOrder
 	ParseError
+	DiffComments
+	DiffProcInsts
+	DiffRepresentation
+	DiffAttributesOrder
+	DiffSerialization
+	DiffFault
+	DiffMove
 )
 
 type XmlDiff interface {
@@ -30,30 +37,67 @@ type parserError struct {
 	text string
 }
 
+// Compared nodes from both samples
+type nodePair struct {
+	node1 *Node
+	node2 *Node
+}
+
 type textualDiff struct {
+	nodePair
 	diffType DiffType
 	text1    string
 	text2    string
 	xmlPath  string
+	// Length of values in the message, see `WithValueTruncation`
+	truncation int
 }
 
 type attributeDiff struct {
+	nodePair
 	diffs   []diffT[xml.Attr]
 	len1    int
 	len2    int
 	xmlPath string
+	// Key of attributes matching, `attrQName` if not set
+	namer func(*xml.Attr) string
+	// Length of values in the message, see `WithValueTruncation`
+	truncation int
 }
 
 type orderDiff struct {
+	nodePair
 	len     int
 	xmlPath string
 }
 
 type childrenDiff struct {
-	diffs   []diffT[parseNode]
+	nodePair
+	diffs   []diffT[Node]
 	len1    int
 	len2    int
 	xmlPath string
+	// Key of elements matching, `nodeName` if not set
+	namer func(*Node) string
+	// Indices of removed and added children reported as moves, see `WithMoveDetection`
+	moved map[int]void
+}
+
+type moveDiff struct {
+	nodePair
+}
+
+// Discrepancy that can be broken into structured differences.
+type structuredDiff interface {
+	details() []Diff
+}
+
+// Path of the node or an empty string for the missing node.
+func pathOf(node *Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.path()
 }
 
 // ------------
@@ -70,20 +114,40 @@ func (err parserError) XmlPath() string {
 	return ""
 }
 
+func (err parserError) details() []Diff {
+	return []Diff{{Kind: ParseFailed, Message: err.text}}
+}
+
 // ------------
 
-func createTextDiff(diffType DiffType, text1 string, text2 string, xmlPath string) *textualDiff {
-	return &textualDiff{diffType: diffType, text1: text1, text2: text2, xmlPath: xmlPath}
+func createTextDiff(diffType DiffType, text1 string, text2 string, xmlPath string, node1 *Node, node2 *Node) *textualDiff {
+	return &textualDiff{nodePair: nodePair{node1, node2}, diffType: diffType, text1: text1, text2: text2, xmlPath: xmlPath}
 }
 
 func (diff textualDiff) DescribeDiff() string {
+	text1, text2 := truncateValue(diff.text1, diff.truncation), truncateValue(diff.text2, diff.truncation)
 	switch diff.diffType {
 	case DiffName:
-		return fmt.Sprintf("Node names differ: '%s' vs '%s', path='%s'", diff.text1, diff.text2, diff.xmlPath)
+		return fmt.Sprintf("Node names differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
 	case DiffSpace:
-		return fmt.Sprintf("Node namespaces differ: '%s' vs '%s', path='%s'", diff.text1, diff.text2, diff.xmlPath)
+		return fmt.Sprintf("Node namespaces differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
 	case DiffContent:
-		return fmt.Sprintf("Node texts differ: '%s' vs '%s', path='%s'", diff.text1, diff.text2, diff.xmlPath)
+		if changes := describeTextChanges(diff.text1, diff.text2); changes != "" {
+			return fmt.Sprintf("Long node texts differ: '%s', path='%s'", changes, diff.xmlPath)
+		}
+		return fmt.Sprintf("Node texts differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffComments:
+		return fmt.Sprintf("Node comments differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffRepresentation:
+		return fmt.Sprintf("Node text representations differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffAttributesOrder:
+		return fmt.Sprintf("Attributes order differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffSerialization:
+		return fmt.Sprintf("Element serializations differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffFault:
+		return fmt.Sprintf("SOAP faults differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
+	case DiffProcInsts:
+		return fmt.Sprintf("Node processing instructions differ: '%s' vs '%s', path='%s'", text1, text2, diff.xmlPath)
 	default:
 		panic("Unexpected textual diff type")
 	}
@@ -97,14 +161,34 @@ func (diff textualDiff) XmlPath() string {
 	return diff.xmlPath
 }
 
+func (diff textualDiff) details() []Diff {
+	kinds := map[DiffType]DiffKind{DiffName: NameChanged, DiffSpace: NamespaceChanged, DiffContent: TextChanged,
+		DiffComments: CommentChanged, DiffProcInsts: ProcInstChanged, DiffRepresentation: RepresentationChanged,
+		DiffAttributesOrder: AttrOrderChanged, DiffSerialization: RepresentationChanged, DiffFault: FaultChanged}
+	name := diff.text1
+	if diff.diffType != DiffName && diff.node1 != nil {
+		name = nodeName(diff.node1)
+	}
+
+	return []Diff{{Kind: kinds[diff.diffType], Name: name, Path1: diff.xmlPath, Path2: pathOf(diff.node2),
+		Expected: diff.text1, Actual: diff.text2, Node1: diff.node1, Node2: diff.node2, Message: diff.DescribeDiff()}}
+}
+
 // ------------
 
-func createAttributeDiff(diffs []diffT[xml.Attr], len1 int, len2 int, xmlPath string) *attributeDiff {
-	return &attributeDiff{diffs: diffs, len1: len1, len2: len2, xmlPath: xmlPath}
+func createAttributeDiff(diffs []diffT[xml.Attr], len1 int, len2 int, xmlPath string, node1 *Node, node2 *Node) *attributeDiff {
+	return &attributeDiff{nodePair: nodePair{node1, node2}, diffs: diffs, len1: len1, len2: len2, xmlPath: xmlPath}
+}
+
+func (diff attributeDiff) attrKey() func(*xml.Attr) string {
+	if diff.namer == nil {
+		return attrQName
+	}
+	return diff.namer
 }
 
 func (diff attributeDiff) DescribeDiff() string {
-	matchingdMap := createMatchingElementsMap(diff.diffs, attrName)
+	matchingdMap := createMatchingElementsMap(diff.diffs, diff.attrKey())
 
 	unmatchedDiffs := make([]diffT[xml.Attr], 0, len(diff.diffs)/2)
 	for i := 0; i < len(diff.diffs); i++ {
@@ -116,7 +200,7 @@ func (diff attributeDiff) DescribeDiff() string {
 	// Log first mismatched attributes...
 	var sDiffs string
 	if len(unmatchedDiffs) > 0 {
-		sDiffs = fmt.Sprintf("counts %d vs %d: %s", diff.len1, diff.len2, extractNames(unmatchedDiffs, attrName))
+		sDiffs = fmt.Sprintf("counts %d vs %d: %s", diff.len1, diff.len2, extractNames(unmatchedDiffs, attrQName))
 	}
 	// ... then matching with different content
 	it := matchingdMap.Iterator()
@@ -127,7 +211,8 @@ func (diff attributeDiff) DescribeDiff() string {
 		if len(sDiffs) != 0 {
 			sDiffs += ", "
 		}
-		sDiffs += fmt.Sprintf("'%s=%s' vs '%s=%s'", attrName(attr1), attr1.Value, attrName(attr2), attr2.Value)
+		sDiffs += fmt.Sprintf("'%s=%s' vs '%s=%s'", attrQName(attr1), truncateValue(attr1.Value, diff.truncation),
+			attrQName(attr2), truncateValue(attr2.Value, diff.truncation))
 	}
 
 	return fmt.Sprintf("Attributes differ: %s, path='%s'", sDiffs, diff.xmlPath)
@@ -141,10 +226,42 @@ func (diff attributeDiff) XmlPath() string {
 	return diff.xmlPath
 }
 
+func (diff attributeDiff) details() []Diff {
+	matchingdMap := createMatchingElementsMap(diff.diffs, diff.attrKey())
+	message := diff.DescribeDiff()
+	path2 := pathOf(diff.node2)
+
+	ret := make([]Diff, 0, len(diff.diffs))
+	for i := range diff.diffs {
+		attr := &diff.diffs[i].e
+		aDiff := Diff{Name: attrQName(attr), Path1: diff.xmlPath, Path2: path2, Node1: diff.node1, Node2: diff.node2, Message: message}
+		switch {
+		case matchingdMap.ContainsKey(i):
+			j, _ := matchingdMap.GetValue(i)
+			attr1, attr2 := attr, &diff.diffs[j].e
+			if diff.diffs[i].t == diffAdd {
+				attr1, attr2 = attr2, attr1
+			}
+			aDiff.Kind, aDiff.Expected, aDiff.Actual = AttrChanged, attrValue(attr1), attrValue(attr2)
+		case matchingdMap.ContainsValue(i):
+			continue
+		case diff.diffs[i].t == diffDelete:
+			aDiff.Kind, aDiff.Expected = AttrRemoved, attrValue(attr)
+		case diff.diffs[i].t == diffAdd:
+			aDiff.Kind, aDiff.Actual = AttrAdded, attrValue(attr)
+		default:
+			continue
+		}
+		ret = append(ret, aDiff)
+	}
+
+	return ret
+}
+
 // ------------
 
-func createOrderDiff(len int, xmlPath string) *orderDiff {
-	return &orderDiff{len: len, xmlPath: xmlPath}
+func createOrderDiff(len int, xmlPath string, node1 *Node, node2 *Node) *orderDiff {
+	return &orderDiff{nodePair: nodePair{node1, node2}, len: len, xmlPath: xmlPath}
 }
 
 func (diff orderDiff) DescribeDiff() string {
@@ -159,21 +276,33 @@ func (diff orderDiff) XmlPath() string {
 	return diff.xmlPath
 }
 
+func (diff orderDiff) details() []Diff {
+	name := ""
+	if diff.node1 != nil {
+		name = nodeName(diff.node1)
+	}
+
+	return []Diff{{Kind: OrderChanged, Name: name, Path1: diff.xmlPath, Path2: pathOf(diff.node2),
+		Node1: diff.node1, Node2: diff.node2, Message: diff.DescribeDiff()}}
+}
+
 // ------------
 
-func createChildrenDiff(diffs []diffT[parseNode], len1 int, len2 int, xmlPath string) *childrenDiff {
-	return &childrenDiff{diffs: diffs, len1: len1, len2: len2, xmlPath: xmlPath}
+func createChildrenDiff(diffs []diffT[Node], len1 int, len2 int, xmlPath string, node1 *Node, node2 *Node) *childrenDiff {
+	return &childrenDiff{nodePair: nodePair{node1, node2}, diffs: diffs, len1: len1, len2: len2, xmlPath: xmlPath}
 }
 
-func (diff childrenDiff) DescribeDiff() string {
-	// return fmt.Sprintf("Children differ: counts %d vs %d, path='%s'", diff.Len1, diff.Len2, diff.XmlPath)
-	matchingdMap := createMatchingElementsMap(diff.diffs, nodeName)
+func (diff childrenDiff) nodeKey() func(*Node) string {
+	if diff.namer == nil {
+		return nodeName
+	}
+	return diff.namer
+}
 
-	unmatchedDiffs := make([]diffT[parseNode], 0, len(diff.diffs)/2)
-	for i := 0; i < len(diff.diffs); i++ {
-		if !matchingdMap.ContainsValue(i) && !matchingdMap.ContainsKey(i) {
-			unmatchedDiffs = append(unmatchedDiffs, diff.diffs[i])
-		}
+func (diff childrenDiff) DescribeDiff() string {
+	unmatchedDiffs := make([]diffT[Node], 0, len(diff.diffs)/2)
+	for _, i := range diff.unmatched() {
+		unmatchedDiffs = append(unmatchedDiffs, diff.diffs[i])
 	}
 
 	// Log first message for this node
@@ -184,6 +313,27 @@ func (diff childrenDiff) DescribeDiff() string {
 	return ""
 }
 
+// Indices of children that are neither paired as modified nor moved.
+func (diff childrenDiff) unmatched() []int {
+	matchingdMap := createMatchingElementsMap(diff.diffs, diff.nodeKey())
+
+	ret := make([]int, 0, len(diff.diffs))
+	for i := range diff.diffs {
+		if _, ok := diff.moved[i]; !ok && !matchingdMap.ContainsValue(i) && !matchingdMap.ContainsKey(i) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// Excludes the removed or added child from the discrepancy as it is reported as a move.
+func (diff *childrenDiff) markMoved(i int) {
+	if diff.moved == nil {
+		diff.moved = make(map[int]void)
+	}
+	diff.moved[i] = empty
+}
+
 func (diff childrenDiff) GetType() DiffType {
 	return DiffChildren
 }
@@ -192,6 +342,53 @@ func (diff childrenDiff) XmlPath() string {
 	return diff.xmlPath
 }
 
+func (diff childrenDiff) details() []Diff {
+	message := diff.DescribeDiff()
+
+	ret := make([]Diff, 0, len(diff.diffs))
+	for _, i := range diff.unmatched() {
+		child := originalNode(&diff.diffs[i])
+		switch diff.diffs[i].t {
+		case diffDelete:
+			ret = append(ret, Diff{Kind: ElementRemoved, Name: nodeName(child), Path1: child.path(), Node1: child, Message: message})
+		case diffAdd:
+			ret = append(ret, Diff{Kind: ElementAdded, Name: nodeName(child), Path2: child.path(), Node2: child, Message: message})
+		case diffSame:
+			continue
+		}
+	}
+
+	return ret
+}
+
+// Finds the node in the parsed tree - diffs contain copies of nodes.
+func originalNode(diff *diffT[Node]) *Node {
+	child := &diff.e
+	if child.Parent != nil && diff.aIdx < len(child.Parent.Children) {
+		return &child.Parent.Children[diff.aIdx]
+	}
+	return child
+}
+
+// ------------
+
+func (diff moveDiff) DescribeDiff() string {
+	return fmt.Sprintf("Element moved: '%s' from '%s' to '%s'", nodeName(diff.node1), diff.node1.path(), diff.node2.path())
+}
+
+func (diff moveDiff) GetType() DiffType {
+	return DiffMove
+}
+
+func (diff moveDiff) XmlPath() string {
+	return diff.node1.path()
+}
+
+func (diff moveDiff) details() []Diff {
+	return []Diff{{Kind: ElementMoved, Name: nodeName(diff.node1), Path1: diff.node1.path(), Path2: diff.node2.path(),
+		Node1: diff.node1, Node2: diff.node2, Message: diff.DescribeDiff()}}
+}
+
 // ------------
 
 // Matches nodes in diff list there were modified and can be further compared.
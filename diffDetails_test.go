@@ -10,16 +10,16 @@ import (
 func TestCreators(t *testing.T) {
 	assertT := assert.New(t)
 
-	textDiff := createTextDiff(DiffName, "a", "b", "/")
+	textDiff := createTextDiff(DiffName, "a", "b", "/", nil, nil)
 	assertT.IsType(&textualDiff{}, textDiff)
 
-	attribDiff := createAttributeDiff([]diffT[xml.Attr]{}, 0, 0, "/")
+	attribDiff := createAttributeDiff([]diffT[xml.Attr]{}, 0, 0, "/", nil, nil)
 	assertT.IsType(&attributeDiff{}, attribDiff)
 
-	ordrDiff := createOrderDiff(0, "/")
+	ordrDiff := createOrderDiff(0, "/", nil, nil)
 	assertT.IsType(&orderDiff{}, ordrDiff)
 
-	childDiff := createChildrenDiff([]diffT[parseNode]{}, 0, 0, "/")
+	childDiff := createChildrenDiff([]diffT[Node]{}, 0, 0, "/", nil, nil)
 	assertT.IsType(&childrenDiff{}, childDiff)
 }
 
@@ -29,18 +29,18 @@ func TestDescribeDiff(t *testing.T) {
 	parseError := parserError{"some error"}
 	assertT.Equal("some error", parseError.DescribeDiff())
 
-	textDiff := createTextDiff(DiffName, "a", "b", "/")
+	textDiff := createTextDiff(DiffName, "a", "b", "/", nil, nil)
 	assertT.Equal("Node names differ: 'a' vs 'b', path='/'", textDiff.DescribeDiff())
 
 	diffs1 := []diffT[xml.Attr]{{e: xml.Attr{Name: xml.Name{Space: "spc", Local: "name"}, Value: "val"}, t: diffSame}}
-	attribDiff := createAttributeDiff(diffs1, 0, 0, "/")
+	attribDiff := createAttributeDiff(diffs1, 0, 0, "/", nil, nil)
 	assertT.Equal("Attributes differ: counts 0 vs 0: , path='/'", attribDiff.DescribeDiff())
 
-	ordrDiff := createOrderDiff(1, "/")
+	ordrDiff := createOrderDiff(1, "/", nil, nil)
 	assertT.Equal("Children order differ for 1 nodes, path='/'", ordrDiff.DescribeDiff())
 
-	diffs2 := []diffT[parseNode]{{e: parseNode{XMLName: xml.Name{Space: "spc", Local: "name"}}, t: diffSame}}
-	childDiff := createChildrenDiff(diffs2, 0, 0, "/")
+	diffs2 := []diffT[Node]{{e: Node{XMLName: xml.Name{Space: "spc", Local: "name"}}, t: diffSame}}
+	childDiff := createChildrenDiff(diffs2, 0, 0, "/", nil, nil)
 	assertT.Equal("Children differ: counts 0 vs 0: , path='/'", childDiff.DescribeDiff())
 }
 
@@ -52,12 +52,12 @@ func TestGetType(t *testing.T) {
 		dType DiffType
 	}{
 		{&parserError{"some error"}, ParseError},
-		{createTextDiff(DiffName, "a", "b", "/"), DiffName},
-		{createTextDiff(DiffSpace, "a", "b", "/"), DiffSpace},
-		{createTextDiff(DiffContent, "a", "b", "/"), DiffContent},
-		{createAttributeDiff(make([]diffT[xml.Attr], 0), 0, 0, "/"), DiffAttributes},
-		{createOrderDiff(0, "/"), DiffChildrenOrder},
-		{createChildrenDiff(make([]diffT[parseNode], 0), 0, 0, "/"), DiffChildren},
+		{createTextDiff(DiffName, "a", "b", "/", nil, nil), DiffName},
+		{createTextDiff(DiffSpace, "a", "b", "/", nil, nil), DiffSpace},
+		{createTextDiff(DiffContent, "a", "b", "/", nil, nil), DiffContent},
+		{createAttributeDiff(make([]diffT[xml.Attr], 0), 0, 0, "/", nil, nil), DiffAttributes},
+		{createOrderDiff(0, "/", nil, nil), DiffChildrenOrder},
+		{createChildrenDiff(make([]diffT[Node], 0), 0, 0, "/", nil, nil), DiffChildren},
 	}
 
 	for _, tt := range tests {
@@ -68,6 +68,6 @@ func TestGetType(t *testing.T) {
 func TestInvalidDescribeDiff(t *testing.T) {
 	assertT := assert.New(t)
 
-	invalidDiff := createTextDiff(DiffChildren, "a", "b", "/")
+	invalidDiff := createTextDiff(DiffChildren, "a", "b", "/", nil, nil)
 	assertT.Panics(func() { invalidDiff.DescribeDiff() })
 }
@@ -1,7 +1,9 @@
 package xmlcomparator
 
 import (
+	"context"
 	"regexp"
+	"time"
 )
 
 type void struct{}
@@ -19,14 +21,51 @@ type DiffRecorder interface {
 	GetDiffs() []XmlDiff
 	// List of serialized differences
 	GetMessages() []string
+	// List of elementary differences
+	GetStructuredDiffs() DiffList
+	// Whether any elementary difference has `SeverityError`
+	HasErrors() bool
+	// Statistics of the comparison
+	GetSummary() Summary
+	// Elementary differences satisfying all filters
+	Filter(filters ...DiffFilter) DiffList
+	// Error which aborted the comparison - error of the context or `*LimitError`; `nil` if the comparison was complete
+	Err() error
 }
 
 // Discrepancy messages collected while walking the trees.
 type diffRecorder struct {
+	opts                 *compareOptions
 	ignoredDiscrepancies []*regexp.Regexp
 	diffs                []XmlDiff
 	messages             []string
 	namespaces           map[keyValue]void
+	ignoredNodes         map[*Node]void
+	// Count of recorded discrepancies
+	recorded int
+	// Receiver of elementary differences as they are found instead of collecting them
+	handler func(Diff) bool
+	// Discrepancies held until removals and additions are matched as moves, see `WithMoveDetection`
+	pending []XmlDiff
+	aborted bool
+	// Only presence of discrepancies matters
+	equalityOnly bool
+	// Compares a subtree in a pool of workers
+	isWorker bool
+	// Context of the comparison checked periodically, `nil` if there is none
+	ctx    context.Context
+	ctxErr error
+	// Exceeded limit of documents
+	limitErr error
+	// Incremental comparison remembering equal subtrees, `nil` if there is none
+	session *IncrementalComparison
+	// Count of namespace differences not recorded as already reported
+	suppressed int
+	// Compared subtree of the first sample
+	root1         *Node
+	nodesCompared int
+	started       time.Time
+	elapsed       time.Duration
 }
 
 func (recorder diffRecorder) GetDiffs() []XmlDiff {
@@ -37,29 +76,165 @@ func (recorder diffRecorder) GetMessages() []string {
 	return recorder.messages
 }
 
+func (recorder diffRecorder) GetStructuredDiffs() DiffList {
+	ret := make(DiffList, 0, len(recorder.diffs))
+	for _, diff := range recorder.diffs {
+		ret = append(ret, recorder.details(diff)...)
+	}
+	return ret
+}
+
+// Breaks the discrepancy into elementary differences with severities and positions.
+func (recorder *diffRecorder) details(diff XmlDiff) DiffList {
+	structured, ok := diff.(structuredDiff)
+	if !ok {
+		return DiffList{}
+	}
+
+	ret := structured.details()
+	for i := range ret {
+		ret[i].Severity = recorder.opts.severityOf(&ret[i])
+		if ret[i].Node1 != nil {
+			ret[i].Pos1 = ret[i].Node1.Pos
+			ret[i].Context1 = recorder.opts.sourceContext(ret[i].Node1)
+		}
+		if ret[i].Node2 != nil {
+			ret[i].Pos2 = ret[i].Node2.Pos
+			ret[i].Context2 = recorder.opts.sourceContext(ret[i].Node2)
+		}
+	}
+	return ret
+}
+
+func (recorder diffRecorder) Err() error {
+	if recorder.ctxErr != nil {
+		return recorder.ctxErr
+	}
+	return recorder.limitErr
+}
+
+func (recorder diffRecorder) HasErrors() bool {
+	return recorder.GetStructuredDiffs().HasErrors()
+}
+
 // Creates an instance of DiffRecorder.
 func createDiffRecorder(ignoredDiscrepancies []string) *diffRecorder {
+	return createDiffRecorderEx(newCompareOptions([]Option{WithIgnoredDiscrepancies(ignoredDiscrepancies...)}))
+}
+
+// Creates an instance of DiffRecorder for the comparison settings.
+func createDiffRecorderEx(opts *compareOptions) *diffRecorder {
+	return newDiffRecorder(opts, compileDiscrepancies(opts.ignoredDiscrepancies))
+}
+
+func compileDiscrepancies(ignoredDiscrepancies []string) []*regexp.Regexp {
 	regexes := make([]*regexp.Regexp, len(ignoredDiscrepancies))
 	for i := range ignoredDiscrepancies {
 		regexes[i] = regexp.MustCompile(ignoredDiscrepancies[i])
 	}
+	return regexes
+}
 
+// Creates an instance of DiffRecorder for the comparison settings with compiled regexes of ignored discrepancies.
+func newDiffRecorder(opts *compareOptions, ignoredDiscrepancies []*regexp.Regexp) *diffRecorder {
 	return &diffRecorder{
-		ignoredDiscrepancies: regexes,
+		opts:                 opts,
+		ignoredDiscrepancies: ignoredDiscrepancies,
 		diffs:                make([]XmlDiff, 0),
 		messages:             make([]string, 0),
 		namespaces:           make(map[keyValue]void),
+		ignoredNodes:         make(map[*Node]void),
+		started:              time.Now(),
 	}
 }
 
 func (recorder *diffRecorder) addDiff(diff XmlDiff) {
-	msg := diff.DescribeDiff()
-	if len(msg) != 0 && !recorder.isIgnored(msg) {
+	if recorder.isComplete() {
+		return
+	}
+	if recorder.equalityOnly {
+		// Only discrepancies that `Compare` would report count
+		if msg := recorder.describe(diff); len(msg) != 0 && !recorder.isIgnored(msg) {
+			recorder.recorded++
+		}
+		return
+	}
+	if !recorder.opts.detectMoves || recorder.isWorker {
+		recorder.record(diff)
+		return
+	}
+
+	// Held discrepancies are counted, so the comparison stops and subtrees differ as usual
+	if msg := recorder.describe(diff); len(msg) != 0 && !recorder.isIgnored(msg) {
+		recorder.recorded++
+		recorder.pending = append(recorder.pending, diff)
+	}
+}
+
+// Records the discrepancy unless it is ignored, or passes its elementary differences to the handler.
+func (recorder *diffRecorder) record(diff XmlDiff) {
+	if recorder.isComplete() {
+		return
+	}
+	msg := recorder.describe(diff)
+	if len(msg) == 0 || recorder.isIgnored(msg) {
+		return
+	}
+
+	recorder.recorded++
+	if recorder.handler == nil {
 		recorder.diffs = append(recorder.diffs, diff)
 		recorder.messages = append(recorder.messages, msg)
+		return
+	}
+	for _, details := range recorder.details(diff) {
+		if !recorder.handler(details) {
+			recorder.aborted = true
+			return
+		}
 	}
 }
 
+// Message of the discrepancy with values truncated according to `WithValueTruncation`.
+func (recorder *diffRecorder) describe(diff XmlDiff) string {
+	if truncator, ok := diff.(valueTruncator); ok && recorder.opts.valueTruncation > 0 {
+		truncator.truncateValues(recorder.opts.valueTruncation)
+	}
+	return diff.DescribeDiff()
+}
+
+// Records held discrepancies with removals and additions of identical subtrees replaced by moves.
+func (recorder *diffRecorder) recordMoves() {
+	if len(recorder.pending) == 0 {
+		return
+	}
+
+	// Differences found before cancellation are kept
+	pending, aborted := recorder.pending, recorder.aborted
+	recorder.pending, recorder.recorded, recorder.aborted = nil, 0, false
+	for _, diff := range detectMoves(pending) {
+		recorder.record(diff)
+	}
+	recorder.aborted = recorder.aborted || aborted
+}
+
+// Checks whether the context of the comparison is done; comparison is aborted then.
+func (recorder *diffRecorder) isCanceled() bool {
+	if recorder.ctx == nil || recorder.ctxErr != nil {
+		return recorder.ctxErr != nil
+	}
+	if err := recorder.ctx.Err(); err != nil {
+		recorder.ctxErr = err
+		recorder.aborted = true
+	}
+	return recorder.ctxErr != nil
+}
+
+// Checks whether enough differences are collected to stop comparison.
+func (recorder *diffRecorder) isComplete() bool {
+	return recorder.aborted || (recorder.opts.maxDiffs > 0 && recorder.recorded >= recorder.opts.maxDiffs)
+}
+
 func (recorder *diffRecorder) isIgnored(msg string) bool {
 	for _, d := range recorder.ignoredDiscrepancies {
 		if d.MatchString(msg) {
@@ -77,3 +252,73 @@ func (recorder *diffRecorder) areNamespacesNew(space1 string, space2 string) boo
 	recorder.namespaces[aPair] = empty
 	return true
 }
+
+// Remembers nodes of the tree selected by ignored XPath expressions.
+func (recorder *diffRecorder) selectIgnoredNodes(root *Node) {
+	for _, xpath := range recorder.opts.ignoredXPaths {
+		for _, node := range xpath.selectNodes(root) {
+			recorder.ignoredNodes[node] = empty
+		}
+	}
+}
+
+// Checks whether the node with its subtree is excluded from comparison.
+func (recorder *diffRecorder) isIgnoredNode(node *Node) bool {
+	if _, ok := recorder.ignoredNodes[node]; ok {
+		return true
+	}
+	if recorder.opts.nilAsAbsent && node.Parent != nil && isNil(node) {
+		return true
+	}
+	return recorder.opts.isIgnoredPath(node)
+}
+
+// Location of a removed child in a discrepancy of children.
+type removedChild struct {
+	diff *childrenDiff
+	idx  int
+}
+
+// Replaces removals and additions of identical subtrees with moves; a move follows the discrepancy of the addition.
+// Removed subtrees are indexed by hashes, so each addition is matched in constant time on average.
+func detectMoves(diffs []XmlDiff) []XmlDiff {
+	removed := make(map[uint64][]removedChild)
+	for _, diff := range diffs {
+		if children, ok := diff.(*childrenDiff); ok {
+			for _, i := range children.unmatched() {
+				if children.diffs[i].t == diffDelete {
+					hash := originalNode(&children.diffs[i]).hashCode()
+					removed[hash] = append(removed[hash], removedChild{children, i})
+				}
+			}
+		}
+	}
+
+	ret := make([]XmlDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		ret = append(ret, diff)
+		children, ok := diff.(*childrenDiff)
+		if !ok {
+			continue
+		}
+
+		for _, j := range children.unmatched() {
+			if children.diffs[j].t != diffAdd {
+				continue
+			}
+			added := originalNode(&children.diffs[j])
+			candidates := removed[added.hashCode()]
+			for k, candidate := range candidates {
+				node := originalNode(&candidate.diff.diffs[candidate.idx])
+				if sameElementNames(node, added) && identicalSubtrees(node, added) {
+					removed[added.hashCode()] = append(candidates[:k], candidates[k+1:]...)
+					candidate.diff.markMoved(candidate.idx)
+					children.markMoved(j)
+					ret = append(ret, &moveDiff{nodePair: nodePair{node, added}})
+					break
+				}
+			}
+		}
+	}
+	return ret
+}
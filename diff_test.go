@@ -0,0 +1,180 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredTextDiffs(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(xmlString1, xmlMixed).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+	assertT.Equal(3, len(diffs.OfKind(TextChanged)))
+
+	diff := diffs[1]
+	assertT.Equal(TextChanged, diff.Kind)
+	assertT.Equal("to", diff.Name)
+	assertT.Equal("/note/to[0]", diff.Path1)
+	assertT.Equal("/note/to[0]", diff.Path2)
+	assertT.Equal("Tove", diff.Expected)
+	assertT.Equal("Jani", diff.Actual)
+	assertT.Equal("to", nodeName(diff.Node1))
+	assertT.Equal("Jani", diff.Node2.CharData)
+	assertT.Equal("Node texts differ: 'Tove' vs 'Jani', path='/note/to[0]'", diff.Message)
+	assertT.Equal("TextChanged: 'Tove' vs 'Jani', path='/note/to[0]'", diff.String())
+}
+
+func TestStructuredAttributeDiffs(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(`<a attr1="12" attr2="xy"/>`, `<a attr2="ab" attr3="1"/>`).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+
+	removed := diffs.OfKind(AttrRemoved)
+	assertT.Equal(1, len(removed))
+	assertT.Equal("attr1", removed[0].Name)
+	assertT.Equal("12", removed[0].Expected)
+
+	added := diffs.OfKind(AttrAdded)
+	assertT.Equal(1, len(added))
+	assertT.Equal("attr3", added[0].Name)
+	assertT.Equal("1", added[0].Actual)
+
+	changed := diffs.OfKind(AttrChanged)
+	assertT.Equal(1, len(changed))
+	assertT.Equal("attr2", changed[0].Name)
+	assertT.Equal("xy", changed[0].Expected)
+	assertT.Equal("ab", changed[0].Actual)
+	assertT.Equal("/a", changed[0].Path2)
+}
+
+func TestStructuredChildrenDiffs(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(`<a><b><c/><d>1</d></b></a>`, `<a><b><d>2</d><e/></b></a>`).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+
+	assertT.Equal(ElementRemoved, diffs[0].Kind)
+	assertT.Equal("c", diffs[0].Name)
+	assertT.Equal("/a/b/c[0]", diffs[0].Path1)
+	assertT.Equal("", diffs[0].Path2)
+	assertT.Nil(diffs[0].Node2)
+	assertT.Equal(&diffs[0].Node1.Parent.Children[0], diffs[0].Node1)
+
+	assertT.Equal(ElementAdded, diffs[1].Kind)
+	assertT.Equal("e", diffs[1].Name)
+	assertT.Equal("/a/b/e[1]", diffs[1].Path2)
+	assertT.Nil(diffs[1].Node1)
+
+	assertT.Equal(TextChanged, diffs[2].Kind)
+	assertT.Equal("/a/b/d[1]", diffs[2].Path1)
+	assertT.Equal("/a/b/d[0]", diffs[2].Path2)
+}
+
+func TestStructuredOtherDiffs(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(`<a><b/><c/></a>`, `<a><c/><b/></a>`).GetStructuredDiffs()
+	assertT.Equal(DiffList{{Kind: OrderChanged, Name: "a", Path1: "/a", Path2: "/a", Node1: diffs[0].Node1, Node2: diffs[0].Node2,
+		Pos1: Position{1, 1}, Pos2: Position{1, 1}, Message: "Children order differ for 2 nodes, path='/a'"}}, diffs)
+
+	diffs = Compare(`<a/>`, `<b/>`).GetStructuredDiffs()
+	assertT.Equal(NameChanged, diffs[0].Kind)
+	assertT.Equal("a", diffs[0].Expected)
+	assertT.Equal("b", diffs[0].Actual)
+
+	diffs = Compare(`<a/>`, ``).GetStructuredDiffs()
+	assertT.Equal(DiffList{{Kind: ParseFailed, Message: "Can't parse the second sample: EOF"}}, diffs)
+	assertT.Equal("ParseFailed: Can't parse the second sample: EOF", diffs[0].String())
+}
+
+func TestDiffKindNames(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal("ElementAdded", ElementAdded.String())
+	assertT.Equal("ParseFailed", ParseFailed.String())
+	assertT.Equal("DiffKind(0)", DiffKind(0).String())
+}
+
+func TestDiffsToJSON(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(`<a><b x="1">t</b></a>`, `<a><b x="2">t</b><c/></a>`).GetStructuredDiffs()
+	data, err := DiffsToJSON(diffs)
+	assertT.Nil(err)
+	assertT.Equal(`[{"kind":"ElementAdded","code":"XC003","name":"c","path1":"","path2":"/a/c[1]","expected":"","actual":"",`+
+		`"message":"Children differ: counts 1 vs 2: c[1]:-1, path='/a'","severity":"Error",`+
+		`"line1":0,"column1":0,"line2":1,"column2":18,"context1":"","context2":""},`+
+		`{"kind":"AttrChanged","code":"XC008","name":"x","path1":"/a/b","path2":"/a/b[0]","expected":"1","actual":"2",`+
+		`"message":"Attributes differ: 'x=1' vs 'x=2', path='/a/b'","severity":"Error",`+
+		`"line1":1,"column1":4,"line2":1,"column2":4,"context1":"","context2":""}]`, string(data))
+
+	data, err = DiffsToJSON(nil)
+	assertT.Nil(err)
+	assertT.Equal("[]", string(data))
+}
+
+func TestDiffKindText(t *testing.T) {
+	assertT := assert.New(t)
+
+	var kind DiffKind
+	assertT.Nil(kind.UnmarshalText([]byte("OrderChanged")))
+	assertT.Equal(OrderChanged, kind)
+	assertT.NotNil(kind.UnmarshalText([]byte("Bogus")))
+
+	text, _ := TextChanged.MarshalText()
+	assertT.Equal("TextChanged", string(text))
+}
+
+func TestSeverity(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><metadata at="1"><b>1</b></metadata><c>1</c></a>`
+	xmlSample2 := `<a><metadata at="2"><b>2</b></metadata><c>2</c></a>`
+
+	result := Compare(xmlSample1, xmlSample2, WithSeverity(SeverityInfo, "/a/metadata", AttrChanged))
+	diffs := result.GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+	assertT.Equal(SeverityInfo, diffs[0].Severity)
+	assertT.Equal(SeverityError, diffs[1].Severity)
+	assertT.True(result.HasErrors())
+
+	result = Compare(xmlSample1, xmlSample2, WithSeverity(SeverityInfo, "/a/metadata"), WithSeverity(SeverityWarning, "/a/metadata/b"),
+		WithSeverity(SeverityWarning, "", TextChanged))
+	diffs = result.GetStructuredDiffs()
+	assertT.Equal([]Severity{SeverityInfo, SeverityWarning, SeverityWarning}, []Severity{diffs[0].Severity, diffs[1].Severity, diffs[2].Severity})
+	assertT.False(result.HasErrors())
+
+	assertT.False(Compare(xmlSample1, xmlSample1).HasErrors())
+	assertT.True(Compare(xmlSample1, `<a/`, WithSeverity(SeverityInfo, "/a")).HasErrors())
+	assertT.Equal("Warning", SeverityWarning.String())
+	assertT.Equal("Severity(7)", Severity(7).String())
+}
+
+func TestDiffCodes(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal("XC001", ElementRemoved.Code())
+	assertT.Equal("XC002", TextChanged.Code())
+	assertT.Equal("", DiffKind(0).Code())
+
+	codes := make(map[string]void)
+	for kind := range diffKindNames {
+		assertT.NotEmpty(kind.Code())
+		codes[kind.Code()] = empty
+		found, ok := DiffKindOfCode(kind.Code())
+		assertT.True(ok)
+		assertT.Equal(kind, found)
+	}
+	assertT.Equal(len(diffKindNames), len(codes))
+	_, ok := DiffKindOfCode("XC999")
+	assertT.False(ok)
+
+	diffs := Compare(`<a><b x="1">t</b></a>`, `<a><b x="2">u</b><c/></a>`).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+	filtered := diffs.WithoutCodes("XC002", "XC003")
+	assertT.Equal(1, len(filtered))
+	assertT.Equal(AttrChanged, filtered[0].Kind)
+}
@@ -0,0 +1,105 @@
+package xmlcomparator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Stable content address of a subtree - see `Node.Digest()`.
+type Digest [sha256.Size]byte
+
+// Version of the digest format; changes of the format change the prefix of the textual form.
+const digestPrefix = "xd1:"
+
+// Textual form of the digest, e.g. `xd1:5f0c...`, suitable for persisting.
+func (digest Digest) String() string {
+	return digestPrefix + hex.EncodeToString(digest[:])
+}
+
+func (digest Digest) MarshalText() ([]byte, error) {
+	return []byte(digest.String()), nil
+}
+
+func (digest *Digest) UnmarshalText(text []byte) error {
+	parsed, err := ParseDigest(string(text))
+	if err != nil {
+		return err
+	}
+	*digest = parsed
+	return nil
+}
+
+// Parses the textual form of the digest produced by `Digest.String()`.
+//
+// Returns: the digest or error if the text is not a digest of the current format
+func ParseDigest(text string) (Digest, error) {
+	var digest Digest
+	encoded, ok := strings.CutPrefix(text, digestPrefix)
+	if !ok {
+		return digest, fmt.Errorf("digest %q has no %q prefix", text, digestPrefix)
+	}
+	decoded, err := hex.DecodeString(encoded)
+	if err != nil || len(decoded) != len(digest) {
+		return digest, fmt.Errorf("invalid digest %q", text)
+	}
+	copy(digest[:], decoded)
+	return digest, nil
+}
+
+// Merkle-style SHA-256 digest of the subtree, which can be persisted and compared across runs and versions of the library,
+// e.g. to skip comparison of unchanged files. Unlike `Node.Hash` used for matching, the digest is a documented format:
+// it covers namespace URIs and local names of the element and its attributes, attribute values, own text with leading
+// and trailing whitespace trimmed and digests of children in order. Namespace prefixes, namespace declarations,
+// order of attributes, comments, processing instructions and formatting outside of texts don't affect the digest.
+//
+// Digests are cached in nodes and recomputed after modifications made with `Node` methods.
+func (node *Node) Digest() Digest {
+	if node.digest != nil {
+		return *node.digest
+	}
+
+	attrs := node.extractAttributes()
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrSpace(&attrs[i]) != attrSpace(&attrs[j]) {
+			return attrSpace(&attrs[i]) < attrSpace(&attrs[j])
+		}
+		return attrName(&attrs[i]) < attrName(&attrs[j])
+	})
+
+	// Fields are prefixed with lengths, so that no two trees have the same input
+	hash := sha256.New()
+	writeDigestField(hash, nodeSpace(node))
+	writeDigestField(hash, nodeName(node))
+	writeDigestCount(hash, len(attrs))
+	for i := range attrs {
+		writeDigestField(hash, attrSpace(&attrs[i]))
+		writeDigestField(hash, attrName(&attrs[i]))
+		writeDigestField(hash, attrValue(&attrs[i]))
+	}
+	writeDigestField(hash, strings.TrimSpace(node.CharData))
+	writeDigestCount(hash, len(node.Children))
+	for i := range node.Children {
+		childDigest := node.Children[i].Digest()
+		_, _ = hash.Write(childDigest[:])
+	}
+
+	digest := Digest(hash.Sum(nil))
+	node.digest = &digest
+	return digest
+}
+
+func writeDigestCount(hash hash.Hash, count int) {
+	var buf [binary.MaxVarintLen64]byte
+	_, _ = hash.Write(buf[:binary.PutUvarint(buf[:], uint64(count))])
+}
+
+func writeDigestField(hash hash.Hash, value string) {
+	writeDigestCount(hash, len(value))
+	_, _ = io.WriteString(hash, value)
+}
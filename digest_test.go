@@ -0,0 +1,66 @@
+package xmlcomparator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func digestOf(xmlString string) Digest {
+	root, _ := parseXML(xmlString)
+	return root.Digest()
+}
+
+func TestDigestStable(t *testing.T) {
+	assertT := assert.New(t)
+
+	digest := digestOf(`<a xmlns="urn:x" id="1" kind="k"><b>text</b><c/></a>`)
+	// Prefixes, attribute order, comments and formatting don't matter
+	assertT.Equal(digest, digestOf(`<p:a xmlns:p="urn:x" kind="k" id="1">
+  <!-- comment -->
+  <p:b> text </p:b>
+  <p:c></p:c>
+</p:a>`))
+
+	assertT.NotEqual(digest, digestOf(`<a xmlns="urn:y" id="1" kind="k"><b>text</b><c/></a>`))
+	assertT.NotEqual(digest, digestOf(`<a xmlns="urn:x" id="2" kind="k"><b>text</b><c/></a>`))
+	assertT.NotEqual(digest, digestOf(`<a xmlns="urn:x" id="1" kind="k"><b>text2</b><c/></a>`))
+	assertT.NotEqual(digest, digestOf(`<a xmlns="urn:x" id="1" kind="k"><c/><b>text</b></a>`))
+	// Fields don't run into each other
+	assertT.NotEqual(digestOf(`<a x="bc"/>`), digestOf(`<a xb="c"/>`))
+	assertT.NotEqual(digestOf(`<a><b/></a>`), digestOf(`<a><b/><b/></a>`))
+
+	// The format is fixed
+	assertT.Equal("xd1:e776564876ad45b4643030e89189bd83727db9b94b3b7e26026ff4bd6e7a9db3", digestOf(`<a id="1">text</a>`).String())
+}
+
+func TestDigestRecomputedOnModification(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b>text</b></a>`)
+	digest := root.Digest()
+	root.Children[0].SetText("other")
+	assertT.NotEqual(digest, root.Digest())
+	assertT.Equal(digestOf(`<a><b>other</b></a>`), root.Digest())
+}
+
+func TestDigestText(t *testing.T) {
+	assertT := assert.New(t)
+
+	digest := digestOf(`<a><b>text</b></a>`)
+	parsed, err := ParseDigest(digest.String())
+	assertT.NoError(err)
+	assertT.Equal(digest, parsed)
+
+	data, err := json.Marshal(map[string]Digest{"file.xml": digest})
+	assertT.NoError(err)
+	var digests map[string]Digest
+	assertT.NoError(json.Unmarshal(data, &digests))
+	assertT.Equal(digest, digests["file.xml"])
+
+	_, err = ParseDigest("abc")
+	assertT.EqualError(err, `digest "abc" has no "xd1:" prefix`)
+	_, err = ParseDigest("xd1:abc")
+	assertT.EqualError(err, `invalid digest "xd1:abc"`)
+}
@@ -0,0 +1,218 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// Type of a tree edit operation.
+type EditOpType int
+
+const (
+	EditInsert EditOpType = iota + 1
+	EditDelete
+	EditUpdate
+	EditMove
+)
+
+var editOpTypeNames = map[EditOpType]string{
+	EditInsert: "insert",
+	EditDelete: "delete",
+	EditUpdate: "update",
+	EditMove:   "move",
+}
+
+func (opType EditOpType) String() string {
+	if name, ok := editOpTypeNames[opType]; ok {
+		return name
+	}
+	return fmt.Sprintf("EditOpType(%d)", int(opType))
+}
+
+// Tree edit operation that transforms the first sample towards the second one.
+type EditOp struct {
+	Type EditOpType
+	// Paths of the element in the first and second samples; empty for the missing element
+	Path1 string
+	Path2 string
+	// Affected property - `name()`, `text()` or `@name` for attributes; empty for operations on elements
+	Target string
+	// Old and new values of the property
+	Expected string
+	Actual   string
+	// Position among siblings in the second sample for inserted and moved elements
+	Position int
+	// Elements in the first and second samples
+	Node1 *Node
+	Node2 *Node
+}
+
+// Describes the operation in a single line.
+func (op EditOp) String() string {
+	switch {
+	case op.Type == EditInsert && op.Target == "":
+		return fmt.Sprintf("insert %s at %d", op.Path2, op.Position)
+	case op.Type == EditDelete && op.Target == "":
+		return fmt.Sprintf("delete %s", op.Path1)
+	case op.Type == EditMove:
+		return fmt.Sprintf("move %s to %d", op.Path1, op.Position)
+	case op.Type == EditInsert:
+		return fmt.Sprintf("insert %s/%s='%s'", op.Path1, op.Target, op.Actual)
+	case op.Type == EditDelete:
+		return fmt.Sprintf("delete %s/%s", op.Path1, op.Target)
+	default:
+		return fmt.Sprintf("update %s/%s: '%s' -> '%s'", op.Path1, op.Target, op.Expected, op.Actual)
+	}
+}
+
+// Computes an edit script of tree operations that transforms the first sample into the second one.
+// Elements are matched top-down - identical subtrees first, then the most similar elements with the same key;
+// matched elements out of the longest common order are moved.
+//   - sample1 - source XML string
+//   - sample2 - target XML string
+//   - opts - comparison options; ignored elements and attributes are not edited, keys and the strategy match children
+//
+// Returns: operations grouped by elements - updates of the name and the text, deleted, inserted and updated attributes,
+// deleted, inserted and moved children, then operations of matched children in the order of the second sample;
+// error if samples can't be parsed
+func EditScript(sample1 string, sample2 string, opts ...Option) ([]EditOp, error) {
+	options := newCompareOptions(opts)
+	limits := options.documentLimits()
+	root1, err := parseLimitedXML(sample1, limits)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse the first sample: %w", err)
+	}
+	root2, err := parseLimitedXML(sample2, limits)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse the second sample: %w", err)
+	}
+
+	script := editScript{ops: make([]EditOp, 0), recorder: createDiffRecorderEx(options)}
+	script.recorder.selectIgnoredNodes(root1)
+	script.recorder.selectIgnoredNodes(root2)
+	if !script.recorder.isIgnoredNode(root1) && !script.recorder.isIgnoredNode(root2) {
+		script.editElement(root1, root2)
+	}
+	return script.ops, nil
+}
+
+type editScript struct {
+	ops []EditOp
+	// Provides ignored elements and settings of matching
+	recorder *diffRecorder
+}
+
+func (script *editScript) update(node1 *Node, node2 *Node, target string, expected string, actual string) {
+	script.ops = append(script.ops, EditOp{Type: EditUpdate, Path1: node1.path(), Path2: node2.path(), Target: target,
+		Expected: expected, Actual: actual, Node1: node1, Node2: node2})
+}
+
+func (script *editScript) editElement(node1 *Node, node2 *Node) {
+	if identicalSubtrees(node1, node2) {
+		return
+	}
+
+	if node1.XMLName != node2.XMLName {
+		script.update(node1, node2, "name()", nodeName(node1), nodeName(node2))
+	}
+	if text1, text2 := trimmedText(node1), trimmedText(node2); text1 != text2 {
+		script.update(node1, node2, "text()", text1, text2)
+	}
+	script.editAttributes(node1, node2)
+	script.editChildren(node1, node2)
+}
+
+func (script *editScript) editAttributes(node1 *Node, node2 *Node) {
+	attrs1, attrs2 := script.attributes(node1), script.attributes(node2)
+	for i := range attrs1 {
+		target := "@" + attrQName(&attrs1[i])
+		idx := indexOfAttr(attrs2, attrs1[i].Name)
+		switch {
+		case idx < 0:
+			script.ops = append(script.ops, EditOp{Type: EditDelete, Path1: node1.path(), Path2: node2.path(), Target: target,
+				Expected: attrs1[i].Value, Node1: node1, Node2: node2})
+		case attrs2[idx].Value != attrs1[i].Value:
+			script.update(node1, node2, target, attrs1[i].Value, attrs2[idx].Value)
+		}
+	}
+	for i := range attrs2 {
+		if indexOfAttr(attrs1, attrs2[i].Name) < 0 {
+			script.ops = append(script.ops, EditOp{Type: EditInsert, Path1: node1.path(), Path2: node2.path(),
+				Target: "@" + attrQName(&attrs2[i]), Actual: attrs2[i].Value, Node1: node1, Node2: node2})
+		}
+	}
+}
+
+// Attributes of the element except ignored ones.
+func (script *editScript) attributes(node *Node) []xml.Attr {
+	attrs := node.extractAttributes()
+	ret := make([]xml.Attr, 0, len(attrs))
+	for i := range attrs {
+		if !script.recorder.opts.isIgnoredAttribute(node, &attrs[i]) {
+			ret = append(ret, attrs[i])
+		}
+	}
+	return ret
+}
+
+func (script *editScript) editChildren(node1 *Node, node2 *Node) {
+	_, indices1 := script.recorder.selectChildren(node1)
+	_, indices2 := script.recorder.selectChildren(node2)
+	opts := script.recorder.opts
+	pairs, unmatched1, unmatched2 := matchUnordered(node1, node2, indices1, indices2, opts.elementKey, opts.strategy)
+
+	for _, i := range unmatched1 {
+		child := &node1.Children[i]
+		script.ops = append(script.ops, EditOp{Type: EditDelete, Path1: child.path(), Node1: child})
+	}
+	for _, j := range unmatched2 {
+		child := &node2.Children[j]
+		script.ops = append(script.ops, EditOp{Type: EditInsert, Path2: child.path(), Position: j, Node2: child})
+	}
+
+	// Pairs in the order of the second sample; those out of the longest common order are moved
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].idx2 < pairs[j].idx2 })
+	kept := increasingSubsequence(pairs)
+	for k, pair := range pairs {
+		if !kept[k] {
+			child1, child2 := &node1.Children[pair.idx1], &node2.Children[pair.idx2]
+			script.ops = append(script.ops, EditOp{Type: EditMove, Path1: child1.path(), Path2: child2.path(), Position: pair.idx2,
+				Node1: child1, Node2: child2})
+		}
+	}
+
+	// Recursion!
+	for _, pair := range pairs {
+		script.editElement(&node1.Children[pair.idx1], &node2.Children[pair.idx2])
+	}
+}
+
+// Finds the longest subsequence of pairs with increasing indices in the first sample.
+//
+// Returns: flags of pairs belonging to the subsequence
+func increasingSubsequence(pairs []matchedPair) []bool {
+	// Indices of pairs ending subsequences of each length and their predecessors
+	tails := make([]int, 0, len(pairs))
+	prev := make([]int, len(pairs))
+	for k := range pairs {
+		pos := sort.Search(len(tails), func(i int) bool { return pairs[tails[i]].idx1 >= pairs[k].idx1 })
+		prev[k] = -1
+		if pos > 0 {
+			prev[k] = tails[pos-1]
+		}
+		if pos == len(tails) {
+			tails = append(tails, k)
+		} else {
+			tails[pos] = k
+		}
+	}
+
+	ret := make([]bool, len(pairs))
+	if len(tails) != 0 {
+		for k := tails[len(tails)-1]; k >= 0; k = prev[k] {
+			ret[k] = true
+		}
+	}
+	return ret
+}
@@ -0,0 +1,82 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func describeOps(ops []EditOp) []string {
+	ret := make([]string, len(ops))
+	for i := range ops {
+		ret[i] = ops[i].String()
+	}
+	return ret
+}
+
+func TestEditScript(t *testing.T) {
+	assertT := assert.New(t)
+
+	ops, err := EditScript(`<a x="1" y="2"><b>1</b><c/><d/></a>`, `<r x="3" z="4"><d/><b>2</b><e/></r>`)
+	assertT.Nil(err)
+	assertT.Equal([]string{
+		"update /a/name(): 'a' -> 'r'",
+		"update /a/@x: '1' -> '3'",
+		"delete /a/@y",
+		"insert /a/@z='4'",
+		"delete /a/c[1]",
+		"insert /r/e[2] at 2",
+		"move /a/d[2] to 0",
+		"update /a/b[0]/text(): '1' -> '2'",
+	}, describeOps(ops))
+
+	assertT.Equal(EditMove, ops[6].Type)
+	assertT.Equal("/r/d[0]", ops[6].Path2)
+	assertT.Equal("d", nodeName(ops[6].Node2))
+	assertT.Equal("4", ops[3].Actual)
+	assertT.Equal("2", ops[2].Expected)
+
+	ops, _ = EditScript(xmlString1, xmlString1)
+	assertT.Equal(0, len(ops))
+
+	_, err = EditScript(`<a>`, `<a/>`)
+	assertT.ErrorContains(err, "can't parse the first sample")
+	_, err = EditScript(`<a/>`, `<a>`)
+	assertT.ErrorContains(err, "can't parse the second sample")
+
+	assertT.Equal("move", EditMove.String())
+	assertT.Equal("EditOpType(0)", EditOpType(0).String())
+}
+
+func TestEditScriptOptions(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a t="1"><meta>x</meta><item id="1">a</item><item id="2">b</item></a>`
+	xmlSample2 := `<a t="2"><meta>y</meta><item id="2">b</item><item id="1">c</item></a>`
+
+	ops, err := EditScript(xmlSample1, xmlSample2)
+	assertT.Equal(4, len(ops))
+	ops, err = EditScript(xmlSample1, xmlSample2, WithIgnoredPaths("/a/meta"), WithIgnoredAttributes("t"))
+	assertT.Nil(err)
+	assertT.Equal([]string{"move /a/item[2] to 1", "update /a/item[1]/text(): 'a' -> 'c'"}, describeOps(ops))
+
+	// Elements with different keys are not matched
+	ops, _ = EditScript(`<a><item id="1">a</item></a>`, `<a><item id="3">a</item></a>`)
+	assertT.Equal([]string{"update /a/item/@id: '1' -> '3'"}, describeOps(ops))
+	ops, _ = EditScript(`<a><item id="1">a</item></a>`, `<a><item id="3">a</item></a>`, WithKeyAttributes("id"))
+	assertT.Equal([]string{"delete /a/item", "insert /a/item at 0"}, describeOps(ops))
+
+	ops, _ = EditScript(xmlSample1, xmlSample2, WithIgnoredPaths("/a"))
+	assertT.Equal(0, len(ops))
+
+	_, err = EditScript(xmlSample1, xmlSample2, WithMaxDocumentSize(10))
+	assertT.ErrorContains(err, "can't parse the first sample")
+}
+
+func TestIncreasingSubsequence(t *testing.T) {
+	assertT := assert.New(t)
+
+	pairs := []matchedPair{{3, 0}, {0, 1}, {1, 2}, {2, 3}, {4, 4}}
+	assertT.Equal([]bool{false, true, true, true, true}, increasingSubsequence(pairs))
+	assertT.Equal([]bool{}, increasingSubsequence([]matchedPair{}))
+}
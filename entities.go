@@ -0,0 +1,289 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Declaration of an internal general entity in the document type declaration
+var entityDeclPattern = regexp.MustCompile(`<!ENTITY\s+([^\s%"']+)\s+(?:"([^"]*)"|'([^']*)')\s*>`)
+
+// Reference to an entity or a character in an entity value
+var entityRefPattern = regexp.MustCompile(`&(#x[0-9a-fA-F]+|#[0-9]+|[^\s&;#]+);`)
+
+var predefinedEntities = map[string]string{"amp": "&", "lt": "<", "gt": ">", "apos": "'", "quot": `"`}
+
+// Longest entity name considered in references
+const maxEntityNameLen = 256
+
+// Error of an entity declared in the document that can't be expanded.
+type EntityError struct {
+	// Name of the entity
+	Name string
+	// Reason like "recursive entity"
+	Reason string
+}
+
+func (err *EntityError) Error() string {
+	return err.Reason + " &" + err.Name + ";"
+}
+
+// Budgets of expanded bytes of decoders parsing documents with internal entities, see `WithInternalEntities`
+var entityBudgets sync.Map
+
+// Reader charging entity references in the input against the budget of expanded bytes of the document,
+// so that many references to a large entity ("quadratic blowup") are rejected before the decoder expands them.
+// References read before entities are declared are charged at declaration - references in the document type
+// declaration are charged as well.
+type entityBudget struct {
+	r     io.Reader
+	limit int64
+	spent int64
+	// Lengths of expanded entities by names; `nil` until entities are declared
+	sizes map[string]int64
+	// Counts of references read before entities are declared
+	pending map[string]int64
+	// The prolog was read without declarations, so references can't be expanded
+	closed bool
+	// Name of the reference being read
+	name  []byte
+	inRef bool
+}
+
+func (budget *entityBudget) Read(p []byte) (int, error) {
+	n, err := budget.r.Read(p)
+	if budget.closed {
+		return n, err
+	}
+	for _, b := range p[:n] {
+		if !budget.scan(b) {
+			// The read part is not passed to the decoder to avoid its expansion
+			return 0, &LimitError{Unit: "bytes of entity expansion", Limit: budget.limit}
+		}
+	}
+	return n, err
+}
+
+// Tracks references in the input byte by byte.
+//
+// Returns: whether the budget is not exceeded
+func (budget *entityBudget) scan(b byte) bool {
+	switch {
+	case b == '&':
+		budget.inRef, budget.name = true, budget.name[:0]
+	case !budget.inRef:
+	case b == ';':
+		budget.inRef = false
+		return budget.charge(string(budget.name), 1)
+	case b <= ' ' || b == '<' || len(budget.name) >= maxEntityNameLen:
+		budget.inRef = false
+	default:
+		budget.name = append(budget.name, b)
+	}
+	return true
+}
+
+// Charges references to the entity.
+//
+// Returns: whether the budget is not exceeded
+func (budget *entityBudget) charge(name string, count int64) bool {
+	if _, ok := predefinedEntities[name]; ok || strings.HasPrefix(name, "#") {
+		return true
+	}
+	if budget.sizes == nil {
+		if budget.pending == nil {
+			budget.pending = make(map[string]int64)
+		}
+		budget.pending[name] += count
+		return true
+	}
+	budget.spent += count * budget.sizes[name]
+	return budget.spent <= budget.limit
+}
+
+// Sets lengths of expanded entities and charges references read so far.
+//
+// Returns: `*LimitError` if the budget is exceeded
+func (budget *entityBudget) declare(expanded map[string]string) error {
+	if budget.sizes == nil {
+		budget.sizes = make(map[string]int64, len(expanded))
+	}
+	for name, value := range expanded {
+		budget.sizes[name] = int64(len(value))
+	}
+	pending := budget.pending
+	budget.pending = nil
+	for name, count := range pending {
+		if !budget.charge(name, count) {
+			return &LimitError{Unit: "bytes of entity expansion", Limit: budget.limit}
+		}
+	}
+	return nil
+}
+
+// Stops tracking references if no entities were declared in the prolog.
+func (budget *entityBudget) endProlog() {
+	if budget.sizes == nil {
+		budget.closed = true
+		budget.pending = nil
+	}
+}
+
+// Creates the decoder of the document enforcing limits of elements and entity expansion.
+//
+// Returns: the decoder and function that releases its budgets
+func (limits documentLimits) decoder(r io.Reader) (*xml.Decoder, func()) {
+	if limits.maxEntityExpansion <= 0 {
+		dec := xml.NewDecoder(r)
+		return dec, limits.watch(dec)
+	}
+
+	budget := &entityBudget{r: r, limit: limits.maxEntityExpansion}
+	dec := xml.NewDecoder(budget)
+	entityBudgets.Store(dec, budget)
+	unwatch := limits.watch(dec)
+	return dec, func() {
+		unwatch()
+		entityBudgets.Delete(dec)
+	}
+}
+
+// Reads the prolog of the document up to the root element.
+// Internal entities declared in the document type declaration are made known to the decoder if enabled by limits.
+//
+// Returns: start of the root element, `io.EOF` if there is none
+func readProlog(dec *xml.Decoder, limits documentLimits) (*xml.StartElement, error) {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if budget, ok := entityBudgets.Load(dec); ok {
+				budget.(*entityBudget).endProlog()
+			}
+			return &t, nil
+		case xml.Directive:
+			if err = limits.declareEntities(dec, string(t)); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Expands internal general entities declared in the document type declaration for the decoder.
+// External entities are never resolved - references to them fail parsing as references to undeclared entities.
+//   - directive - the `<!DOCTYPE ...>` directive without angle brackets
+//
+// Returns: `*LimitError` if expansion of a declared entity or references read so far exceed
+// `documentLimits.maxEntityExpansion`, `*EntityError` if an entity is recursive
+func (limits documentLimits) declareEntities(dec *xml.Decoder, directive string) error {
+	if limits.maxEntityExpansion <= 0 || !strings.HasPrefix(directive, "DOCTYPE") {
+		return nil
+	}
+
+	names := make([]string, 0)
+	values := make(map[string]string)
+	for _, decl := range entityDeclPattern.FindAllStringSubmatch(directive, -1) {
+		// The first declaration is binding
+		if _, ok := values[decl[1]]; !ok {
+			names = append(names, decl[1])
+			values[decl[1]] = decl[2] + decl[3]
+		}
+	}
+
+	expanded := make(map[string]string, len(values))
+	for _, name := range names {
+		if _, err := expandEntity(name, values, expanded, make(map[string]bool), limits.maxEntityExpansion); err != nil {
+			return err
+		}
+	}
+	if dec.Entity == nil {
+		dec.Entity = make(map[string]string, len(expanded))
+	}
+	for name, value := range expanded {
+		dec.Entity[name] = value
+	}
+	if budget, ok := entityBudgets.Load(dec); ok {
+		return budget.(*entityBudget).declare(expanded)
+	}
+	return nil
+}
+
+// Expands references in the value of the entity.
+//   - values - declared values of entities
+//   - expanded - already expanded values, updated
+//   - expanding - entities being expanded, to detect recursion
+//   - limit - maximal length of an expanded value
+//
+// Returns: the expanded value and error if the entity is recursive or its expansion exceeds the limit
+func expandEntity(name string, values map[string]string, expanded map[string]string, expanding map[string]bool,
+	limit int64) (string, error) {
+	if value, ok := expanded[name]; ok {
+		return value, nil
+	}
+	if expanding[name] {
+		return "", &EntityError{Name: name, Reason: "recursive entity"}
+	}
+	expanding[name] = true
+
+	var buf strings.Builder
+	value := values[name]
+	last := 0
+	for _, ref := range entityRefPattern.FindAllStringSubmatchIndex(value, -1) {
+		buf.WriteString(value[last:ref[0]])
+		last = ref[1]
+
+		refName := value[ref[2]:ref[3]]
+		if replacement, ok := characterReference(refName); ok {
+			buf.WriteString(replacement)
+		} else if replacement, ok = predefinedEntities[refName]; ok {
+			buf.WriteString(replacement)
+		} else if _, ok = values[refName]; ok {
+			replacement, err := expandEntity(refName, values, expanded, expanding, limit)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(replacement)
+		} else {
+			// Undeclared entities are left to the decoder
+			buf.WriteString(value[ref[0]:ref[1]])
+		}
+		if int64(buf.Len()) > limit {
+			return "", &LimitError{Unit: "bytes of entity expansion", Limit: limit}
+		}
+	}
+	buf.WriteString(value[last:])
+	if int64(buf.Len()) > limit {
+		return "", &LimitError{Unit: "bytes of entity expansion", Limit: limit}
+	}
+
+	delete(expanding, name)
+	expanded[name] = buf.String()
+	return expanded[name], nil
+}
+
+// Character referenced like `#65` or `#x41`.
+//
+// Returns: the character and whether the name is a character reference
+func characterReference(name string) (string, bool) {
+	var code uint64
+	var err error
+	switch {
+	case strings.HasPrefix(name, "#x"):
+		code, err = strconv.ParseUint(name[2:], 16, 32)
+	case strings.HasPrefix(name, "#"):
+		code, err = strconv.ParseUint(name[1:], 10, 32)
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	return string(rune(code)), true
+}
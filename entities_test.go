@@ -0,0 +1,86 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const entityDoctype = `<!DOCTYPE a [
+  <!ENTITY company "ACME &amp; Co&#x2E;">
+  <!ENTITY signature '&company; team'>
+  <!ENTITY xxe SYSTEM "file:///etc/passwd">
+]>`
+
+func TestInternalEntities(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample := entityDoctype + `<a><b>&signature;</b></a>`
+	assertT.Equal([]string{"Can't parse the first sample: XML syntax error on line 5: invalid character entity &signature;"},
+		Compare(xmlSample, `<a/>`).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample, `<a><b>ACME &amp; Co. team</b></a>`, WithInternalEntities(100)).GetMessages())
+	assertT.Equal(emptyList, CompareXmlStreams(strings.NewReader(xmlSample), strings.NewReader(`<a><b>ACME &amp; Co. team</b></a>`),
+		WithInternalEntities(100)).GetMessages())
+
+	// External entities are not resolved
+	assertT.Equal([]string{"Can't parse the first sample: XML syntax error on line 5: invalid character entity &xxe;"},
+		Compare(entityDoctype+`<a>&xxe;</a>`, `<a/>`, WithInternalEntities(100)).GetMessages())
+
+	assertT.Equal([]string{"Can't parse the first sample: recursive entity &r1;"},
+		Compare(`<!DOCTYPE a [<!ENTITY r1 "&r2;"><!ENTITY r2 "&r1;">]><a/>`, `<a/>`, WithInternalEntities(100)).GetMessages())
+	_, err := parseLimitedXML(`<!DOCTYPE a [<!ENTITY r1 "&r1;">]><a/>`, documentLimits{maxEntityExpansion: 100})
+	var entityErr *EntityError
+	assertT.ErrorAs(err, &entityErr)
+	assertT.Equal(&EntityError{Name: "r1", Reason: "recursive entity"}, entityErr)
+}
+
+// Document type declaration of the "billion laughs" attack.
+//   - levels - count of nested entities, each one expands 10 times
+func laughsDoctype(levels int) string {
+	var doctype strings.Builder
+	doctype.WriteString(`<!DOCTYPE lolz [<!ENTITY lol0 "lol">`)
+	for i := 1; i < levels; i++ {
+		doctype.WriteString(fmt.Sprintf(`<!ENTITY lol%d "%s">`, i, strings.Repeat(fmt.Sprintf("&lol%d;", i-1), 10)))
+	}
+	doctype.WriteString(`]>`)
+	return doctype.String()
+}
+
+func TestEntityExpansionLimit(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(laughsDoctype(10)+`<lolz>&lol9;</lolz>`, `<lolz/>`, WithInternalEntities(1<<20))
+	assertT.Equal(&LimitError{Unit: "bytes of entity expansion", Limit: 1 << 20}, diffs.Err())
+	assertT.Equal([]string{"Can't parse the first sample: document exceeds the limit of 1048576 bytes of entity expansion"},
+		diffs.GetMessages())
+
+	// Entities are expanded when declared
+	assertT.NotNil(Compare(laughsDoctype(10)+`<lolz>&lol1;</lolz>`, `<lolz/>`, WithInternalEntities(1<<20)).Err())
+	assertT.Equal(emptyList, Compare(laughsDoctype(4)+`<lolz>&lol3;</lolz>`, `<lolz>`+strings.Repeat("lol", 1000)+`</lolz>`,
+		WithInternalEntities(1<<20)).GetMessages())
+}
+
+func TestEntityReferencesLimit(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Quadratic blowup - many references to a large entity
+	doctype := `<!DOCTYPE a [<!ENTITY big "` + strings.Repeat("x", 90000) + `">]>`
+	blowup := doctype + `<a>` + strings.Repeat("&big;", 3000) + `</a>`
+	diffs := Compare(blowup, `<a/>`, WithInternalEntities(100000), WithMaxDocumentSize(1<<20))
+	assertT.Equal(&LimitError{Unit: "bytes of entity expansion", Limit: 100000}, diffs.Err())
+	diffs = CompareXmlStreams(strings.NewReader(blowup), strings.NewReader(`<a/>`), WithInternalEntities(100000))
+	assertT.Equal(&LimitError{Unit: "bytes of entity expansion", Limit: 100000}, diffs.Err())
+
+	// References in attributes are charged too
+	diffs = Compare(doctype+`<a x="&big;"><b y="&big;"/></a>`, `<a/>`, WithInternalEntities(100000))
+	assertT.Equal(&LimitError{Unit: "bytes of entity expansion", Limit: 100000}, diffs.Err())
+
+	single := doctype + `<a>&big;&amp;&#x41;</a>`
+	assertT.Equal(emptyList, Compare(single, `<a>`+strings.Repeat("x", 90000)+`&amp;A</a>`, WithInternalEntities(100000)).GetMessages())
+
+	// Documents without declarations are not affected
+	assertT.Equal(emptyList, Compare(`<a>`+strings.Repeat("&amp;", 100)+`</a>`, `<a>`+strings.Repeat("&amp;", 100)+`</a>`,
+		WithInternalEntities(10)).GetMessages())
+}
@@ -0,0 +1,82 @@
+package xmlcomparator
+
+import "slices"
+
+// Predicate selecting structured differences.
+type DiffFilter func(diff *Diff) bool
+
+func (recorder diffRecorder) Filter(filters ...DiffFilter) DiffList {
+	return recorder.GetStructuredDiffs().Filter(filters...)
+}
+
+// Selects differences satisfying all filters, e.g. `diffs.Filter(ByPathPrefix("/doc/header"), ByKind(TextChanged))`.
+func (diffs DiffList) Filter(filters ...DiffFilter) DiffList {
+	ret := make(DiffList, 0, len(diffs))
+	for i := range diffs {
+		if AllOf(filters...)(&diffs[i]) {
+			ret = append(ret, diffs[i])
+		}
+	}
+	return ret
+}
+
+// Selects differences in the subtree of elements in either sample.
+//   - prefix - path of subtree roots in the same format as for `WithIgnoredPaths`
+func ByPathPrefix(prefix string) DiffFilter {
+	pattern := compilePathPattern(prefix)
+	return func(diff *Diff) bool {
+		return (diff.Path1 != "" && pattern.matchesPrefix(diff.Path1)) || (diff.Path2 != "" && pattern.matchesPrefix(diff.Path2))
+	}
+}
+
+// Selects differences of the specified kinds.
+func ByKind(kinds ...DiffKind) DiffFilter {
+	return func(diff *Diff) bool {
+		return slices.Contains(kinds, diff.Kind)
+	}
+}
+
+// Selects differences which kinds have the specified codes.
+func ByCode(codes ...string) DiffFilter {
+	return func(diff *Diff) bool {
+		return slices.Contains(codes, diff.Kind.Code())
+	}
+}
+
+// Selects differences with the specified severities.
+func BySeverity(severities ...Severity) DiffFilter {
+	return func(diff *Diff) bool {
+		return slices.Contains(severities, diff.Severity)
+	}
+}
+
+// Selects differences not selected by the filter.
+func Not(filter DiffFilter) DiffFilter {
+	return func(diff *Diff) bool {
+		return !filter(diff)
+	}
+}
+
+// Selects differences satisfying all filters; all differences if there are no filters.
+func AllOf(filters ...DiffFilter) DiffFilter {
+	return func(diff *Diff) bool {
+		for _, filter := range filters {
+			if !filter(diff) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Selects differences satisfying any of filters.
+func AnyOf(filters ...DiffFilter) DiffFilter {
+	return func(diff *Diff) bool {
+		for _, filter := range filters {
+			if filter(diff) {
+				return true
+			}
+		}
+		return false
+	}
+}
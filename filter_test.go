@@ -0,0 +1,40 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const filterSample1 = `<doc><header id="1"><ts>1</ts></header><body><item>a</item><item>b</item></body></doc>`
+const filterSample2 = `<doc><header id="2"><ts>2</ts></header><body><item>a</item><item>c</item><extra/></body></doc>`
+
+func TestFilter(t *testing.T) {
+	assertT := assert.New(t)
+
+	result := Compare(filterSample1, filterSample2, WithSeverity(SeverityInfo, "/doc/header"))
+	assertT.Equal(4, len(result.GetStructuredDiffs()))
+
+	diffs := result.Filter(ByPathPrefix("/doc/header"))
+	assertT.Equal(2, len(diffs))
+
+	diffs = result.Filter(ByPathPrefix("/doc/header"), ByKind(TextChanged))
+	assertT.Equal(1, len(diffs))
+	assertT.Equal("/doc/header[0]/ts", diffs[0].Path1)
+
+	diffs = result.Filter(ByPathPrefix("/doc/body/item[1]"))
+	assertT.Equal(1, len(diffs))
+	assertT.Equal("c", diffs[0].Actual)
+
+	diffs = result.Filter(ByPathPrefix("/*/body"), Not(ByCode("XC002")))
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ElementAdded, diffs[0].Kind)
+
+	diffs = result.Filter(AnyOf(BySeverity(SeverityInfo), ByKind(ElementAdded)))
+	assertT.Equal(3, len(diffs))
+
+	assertT.Equal(4, len(result.Filter()))
+	assertT.Equal(0, len(result.Filter(ByPathPrefix("/doc/footer"))))
+	assertT.Equal(0, len(result.Filter(AnyOf())))
+	assertT.Equal(2, len(result.GetStructuredDiffs().Filter(ByKind(TextChanged, AttrChanged), BySeverity(SeverityInfo))))
+}
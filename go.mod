@@ -1,6 +1,6 @@
 module github.com/aknopov/xmlcomparator
 
-go 1.21
+go 1.23
 
 require github.com/stretchr/testify v1.10.0
 
@@ -8,5 +8,5 @@ require (
 	github.com/aknopov/handymaps v0.0.2
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,71 @@
+package xmlcomparator
+
+import "fmt"
+
+// Differences under a common ancestor element.
+type DiffGroup struct {
+	// Path of the common ancestor; empty for differences without nodes, e.g. parsing errors
+	Path  string
+	Diffs DiffList
+}
+
+// Groups differences by their ancestors at the specified depth.
+//   - depth - depth of common ancestors, the root element has depth 1; differences of shallower nodes form own groups
+//
+// Returns: groups in the order of first differences
+func (diffs DiffList) GroupByPath(depth int) []DiffGroup {
+	ret := make([]DiffGroup, 0)
+	indices := make(map[string]int)
+	for i := range diffs {
+		path := ancestorPath(&diffs[i], depth)
+		idx, ok := indices[path]
+		if !ok {
+			idx = len(ret)
+			indices[path] = idx
+			ret = append(ret, DiffGroup{Path: path, Diffs: make(DiffList, 0, 1)})
+		}
+		ret[idx].Diffs = append(ret[idx].Diffs, diffs[i])
+	}
+	return ret
+}
+
+// Describes groups one per line; groups with more than `threshold` differences are collapsed into a summary line.
+//   - depth - depth of common ancestors - see `GroupByPath`
+//   - threshold - max count of differences listed individually
+func (diffs DiffList) Collapse(depth int, threshold int) []string {
+	ret := make([]string, 0)
+	for _, group := range diffs.GroupByPath(depth) {
+		if len(group.Diffs) > threshold && group.Path != "" {
+			ret = append(ret, group.String())
+			continue
+		}
+		for i := range group.Diffs {
+			ret = append(ret, group.Diffs[i].String())
+		}
+	}
+	return ret
+}
+
+// Summarizes the group, e.g. "17 differences under /orders/order[3]".
+func (group DiffGroup) String() string {
+	if len(group.Diffs) == 1 {
+		return fmt.Sprintf("1 difference under %s", group.Path)
+	}
+	return fmt.Sprintf("%d differences under %s", len(group.Diffs), group.Path)
+}
+
+// Path of the ancestor of the difference node at the depth.
+func ancestorPath(diff *Diff, depth int) string {
+	node := diff.Node1
+	if node == nil {
+		node = diff.Node2
+	}
+	if node == nil {
+		return ""
+	}
+
+	for node.Parent != nil && node.depth() > max(depth, 1) {
+		node = node.Parent
+	}
+	return node.path()
+}
@@ -0,0 +1,51 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ordersSample1 = `<orders><order><id>1</id><qty>1</qty></order><order><id>2</id><qty>2</qty><price>5</price></order></orders>`
+const ordersSample2 = `<orders><order><id>1</id><qty>3</qty></order><order><id>2</id><qty>4</qty><price>6</price></order></orders>`
+
+func TestGroupByPath(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(ordersSample1, ordersSample2).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+
+	groups := diffs.GroupByPath(2)
+	assertT.Equal(2, len(groups))
+	assertT.Equal("/orders/order[0]", groups[0].Path)
+	assertT.Equal(1, len(groups[0].Diffs))
+	assertT.Equal("/orders/order[1]", groups[1].Path)
+	assertT.Equal(2, len(groups[1].Diffs))
+	assertT.Equal("2 differences under /orders/order[1]", groups[1].String())
+	assertT.Equal("1 difference under /orders/order[0]", groups[0].String())
+
+	groups = diffs.GroupByPath(1)
+	assertT.Equal(1, len(groups))
+	assertT.Equal("/orders", groups[0].Path)
+
+	groups = diffs.GroupByPath(10)
+	assertT.Equal(3, len(groups))
+	assertT.Equal("/orders/order[1]/qty[1]", groups[1].Path)
+
+	groups = Compare(`<a>`, `<a/>`).GetStructuredDiffs().GroupByPath(2)
+	assertT.Equal(1, len(groups))
+	assertT.Equal("", groups[0].Path)
+}
+
+func TestCollapse(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(ordersSample1, ordersSample2).GetStructuredDiffs()
+
+	assertT.Equal([]string{
+		"TextChanged: '1' vs '3', path='/orders/order[0]/qty[1]'",
+		"2 differences under /orders/order[1]",
+	}, diffs.Collapse(2, 1))
+	assertT.Equal(3, len(diffs.Collapse(2, 2)))
+	assertT.Equal([]string{"3 differences under /orders"}, diffs.Collapse(1, 0))
+}
@@ -0,0 +1,107 @@
+package xmlcomparator
+
+import "sync"
+
+// Session of repeated comparisons of documents which change a little at a time, e.g. in an editor or a file watcher.
+// Pairs of subtrees found equal are remembered by their paths and hashes, so later comparisons of the session skip them
+// without walking - only regions changed since the previous comparison and regions with differences are compared again.
+// Trees may be modified in place with `Node` methods or parsed anew - hashes of unchanged subtrees stay the same.
+// Hashes don't reflect comments, processing instructions, CDATA sections, exact whitespace, self-closing tags and
+// namespaces, so with options comparing them, e.g. `WithComments()`, every comparison walks the trees in full.
+//
+// Comparisons of a session must not run concurrently.
+type IncrementalComparison struct {
+	cmp *Comparator
+	mu  sync.Mutex
+	// Equal pairs found or used by the previous and the current comparisons
+	previous map[subtreePair]void
+	current  map[subtreePair]void
+}
+
+// Key of a pair of compared subtrees.
+type subtreePair struct {
+	path1 string
+	path2 string
+	hash1 uint64
+	hash2 uint64
+}
+
+// Creates a session of repeated comparisons with the settings of the comparator - see `IncrementalComparison`.
+func (cmp *Comparator) Incremental() *IncrementalComparison {
+	return &IncrementalComparison{cmp: cmp, previous: make(map[subtreePair]void), current: make(map[subtreePair]void)}
+}
+
+// Compares parsed trees skipping pairs of subtrees found equal by previous comparisons of the session.
+//   - node1, node2 - roots of compared trees
+//
+// Returns: A list of detected discrepancies, the same as of `Comparator.CompareNodes`
+func (inc *IncrementalComparison) CompareNodes(node1 *Node, node2 *Node) DiffRecorder {
+	diffRecorder := inc.cmp.newRecorder()
+	if !inc.cmp.opts.deepComparison() {
+		diffRecorder.session = inc
+	}
+	limits := inc.cmp.opts.documentLimits()
+	compareInputs(func() (*Node, error) { return existingNode(node1, limits) },
+		func() (*Node, error) { return existingNode(node2, limits) }, diffRecorder)
+
+	// Pairs not met by this comparison are forgotten
+	inc.previous, inc.current = inc.current, make(map[subtreePair]void)
+	return diffRecorder
+}
+
+// Checks whether the pair of subtrees was found equal by this or the previous comparison.
+func (inc *IncrementalComparison) knownEqual(key subtreePair) bool {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	if _, ok := inc.current[key]; ok {
+		return true
+	}
+	if _, ok := inc.previous[key]; ok {
+		inc.current[key] = empty
+		return true
+	}
+	return false
+}
+
+func (inc *IncrementalComparison) rememberEqual(key subtreePair) {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	inc.current[key] = empty
+}
+
+func pairKey(node1 *Node, node2 *Node) subtreePair {
+	return subtreePair{path1: node1.path(), path2: node2.path(), hash1: node1.hashCode(), hash2: node2.hashCode()}
+}
+
+// Checks whether subtrees are identical; verified pairs are remembered by the incremental comparison.
+func (recorder *diffRecorder) identical(node1 *Node, node2 *Node) bool {
+	// Verification of leaves is cheaper than the lookup
+	if recorder.session == nil || len(node1.Children) == 0 || node1.hashCode() != node2.hashCode() {
+		return identicalSubtrees(node1, node2)
+	}
+
+	key := pairKey(node1, node2)
+	if recorder.session.knownEqual(key) {
+		return true
+	}
+	if !identicalSubtrees(node1, node2) {
+		return false
+	}
+	recorder.session.rememberEqual(key)
+	return true
+}
+
+// Compares the pair of subtrees unless it is known to be equal; remembers the pair if no differences are found.
+func (inc *IncrementalComparison) compareUnlessEqual(node1 *Node, node2 *Node, recorder *diffRecorder) {
+	key := pairKey(node1, node2)
+	if inc.knownEqual(key) {
+		return
+	}
+
+	// Namespace differences are reported once, so suppressed ones count too
+	recorded, suppressed := recorder.recorded, recorder.suppressed
+	pairDifferent(node1, node2, recorder)
+	if recorder.recorded == recorded && recorder.suppressed == suppressed && !recorder.isComplete() {
+		inc.rememberEqual(key)
+	}
+}
@@ -0,0 +1,62 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementalComparison(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample1 := catalogSample(100, func(int) bool { return false })
+	// Items differ by ignored attributes, so hashes don't help
+	sample2 := strings.ReplaceAll(catalogSample(100, func(i int) bool { return i == 10 }), `id="`, `id="x`)
+	root1, _ := parseXML(sample1)
+	root2, _ := parseXML(sample2)
+
+	session := NewComparator(WithIgnoredAttributes("id")).Incremental()
+	full := CompareNodes(root1, root2, WithIgnoredAttributes("id"))
+	diffs := session.CompareNodes(root1, root2)
+	assertT.Equal(full.GetMessages(), diffs.GetMessages())
+	assertT.Len(diffs.GetMessages(), 2)
+
+	// Unchanged items are skipped
+	diffs = session.CompareNodes(root1, root2)
+	assertT.Equal(full.GetMessages(), diffs.GetMessages())
+	assertT.Less(diffs.GetSummary().NodesCompared, full.GetSummary().NodesCompared/10)
+
+	// Modified in place
+	root2.Children[50].Children[0].SetText("Renamed")
+	full = CompareNodes(root1, root2, WithIgnoredAttributes("id"))
+	diffs = session.CompareNodes(root1, root2)
+	assertT.Equal(full.GetMessages(), diffs.GetMessages())
+	assertT.Equal("Node texts differ: 'Item 50' vs 'Renamed', path='/catalog/item[50]/name[0]'", diffs.GetMessages()[2])
+	assertT.Less(diffs.GetSummary().NodesCompared, full.GetSummary().NodesCompared/10)
+
+	// Parsed anew
+	root2, _ = parseXML(sample2)
+	full = CompareNodes(root1, root2, WithIgnoredAttributes("id"))
+	diffs = session.CompareNodes(root1, root2)
+	assertT.Equal(full.GetMessages(), diffs.GetMessages())
+	assertT.Less(diffs.GetSummary().NodesCompared, full.GetSummary().NodesCompared/10)
+}
+
+func TestIncrementalComparisonComments(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample := `<a><b><!-- %s --><c>1</c></b><d/></a>`
+	root1, _ := parseXML(fmt.Sprintf(sample, "one"))
+	root2, _ := parseXML(fmt.Sprintf(sample, "one"))
+
+	session := NewComparator(WithComments()).Incremental()
+	assertT.Empty(session.CompareNodes(root1, root2).GetMessages())
+
+	// Hashes of the re-parsed document are the same
+	root2, _ = parseXML(fmt.Sprintf(sample, "two"))
+	full := CompareNodes(root1, root2, WithComments())
+	assertT.Len(full.GetMessages(), 1)
+	assertT.Equal(full.GetMessages(), session.CompareNodes(root1, root2).GetMessages())
+}
@@ -0,0 +1,90 @@
+package xmlcomparator
+
+import (
+	"iter"
+	"strconv"
+)
+
+// Iterates over the node and its descendants in document order (depth-first, pre-order).
+func (node *Node) All() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		node.yieldSubtree(yield)
+	}
+}
+
+// Iterates over descendants of the node in document order, excluding the node itself.
+func (node *Node) Descendants() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for i := range node.Children {
+			if !node.Children[i].yieldSubtree(yield) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over ancestors of the node from its parent up to the root.
+func (node *Node) Ancestors() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for ancestor := node.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if !yield(ancestor) {
+				return
+			}
+		}
+	}
+}
+
+// Returns: `false` if iteration was stopped
+func (node *Node) yieldSubtree(yield func(*Node) bool) bool {
+	if !yield(node) {
+		return false
+	}
+	for i := range node.Children {
+		if !node.Children[i].yieldSubtree(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Walks depth-first through the subtree calling the function for the node before its children (pre-order).
+//   - f - function to call for each node; returns `false` to skip children of the node
+func (node *Node) WalkDepthFirst(f func(*Node) bool) {
+	node.walk(f)
+}
+
+// Walks breadth-first through the subtree calling the function level by level.
+//   - f - function to call for each node; returns `false` to skip children of the node
+func (node *Node) WalkBreadthFirst(f func(*Node) bool) {
+	queue := []*Node{node}
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if !f(current) {
+			continue
+		}
+		for i := range current.Children {
+			queue = append(queue, &current.Children[i])
+		}
+	}
+}
+
+// Walks depth-first through the subtree passing the depth (the root element has depth 1) and the path
+// in the format reported in diffs.
+//   - f - function to call for each node; returns `false` to skip children of the node
+func (node *Node) WalkWithPath(f func(node *Node, depth int, path string) bool) {
+	node.walkWithPath(f, node.depth(), node.path())
+}
+
+func (node *Node) walkWithPath(f func(*Node, int, string) bool, depth int, path string) {
+	if !f(node, depth, path) {
+		return
+	}
+	for i := range node.Children {
+		childPath := path + "/" + nodeName(&node.Children[i])
+		if len(node.Children) > 1 {
+			childPath += "[" + strconv.Itoa(i) + "]"
+		}
+		node.Children[i].walkWithPath(f, depth+1, childPath)
+	}
+}
@@ -0,0 +1,91 @@
+package xmlcomparator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectNames(nodes func(func(*Node) bool)) []string {
+	names := make([]string, 0)
+	for node := range nodes {
+		names = append(names, nodeName(node))
+	}
+	return names
+}
+
+func TestNodeIterators(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b><c/><d/></b><e><f/></e></a>`)
+
+	assertT.Equal([]string{"a", "b", "c", "d", "e", "f"}, collectNames(root.All()))
+	assertT.Equal([]string{"b", "c", "d", "e", "f"}, collectNames(root.Descendants()))
+	assertT.Equal([]string{"c", "d"}, collectNames(root.Children[0].Descendants()))
+	assertT.Equal([]string{"e", "a"}, collectNames(root.Children[1].Children[0].Ancestors()))
+	assertT.Equal([]string{}, collectNames(root.Ancestors()))
+
+	// Early exit
+	names := make([]string, 0)
+	for node := range root.All() {
+		if nodeName(node) == "d" {
+			break
+		}
+		names = append(names, nodeName(node))
+	}
+	assertT.Equal([]string{"a", "b", "c"}, names)
+
+	for node := range root.Descendants() {
+		names = append(names, nodeName(node))
+		break
+	}
+	for node := range root.Children[0].Children[0].Ancestors() {
+		names = append(names, nodeName(node))
+		break
+	}
+	assertT.Equal([]string{"a", "b", "c", "b", "b"}, names)
+}
+
+func TestWalks(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b><c/><d/></b><e><f/></e></a>`)
+
+	names := make([]string, 0)
+	root.WalkDepthFirst(func(node *Node) bool {
+		names = append(names, nodeName(node))
+		return nodeName(node) != "b"
+	})
+	assertT.Equal([]string{"a", "b", "e", "f"}, names)
+
+	names = names[:0]
+	root.WalkBreadthFirst(func(node *Node) bool {
+		names = append(names, nodeName(node))
+		return true
+	})
+	assertT.Equal([]string{"a", "b", "e", "c", "d", "f"}, names)
+
+	names = names[:0]
+	root.WalkBreadthFirst(func(node *Node) bool {
+		names = append(names, nodeName(node))
+		return nodeName(node) != "e"
+	})
+	assertT.Equal([]string{"a", "b", "e", "c", "d"}, names)
+
+	paths := make([]string, 0)
+	root.WalkWithPath(func(node *Node, depth int, path string) bool {
+		assertT.Equal(node.depth(), depth)
+		assertT.Equal(node.path(), path)
+		paths = append(paths, path)
+		return depth < 2
+	})
+	assertT.Equal([]string{"/a", "/a/b[0]", "/a/e[1]"}, paths)
+
+	paths = paths[:0]
+	root.Children[1].WalkWithPath(func(node *Node, depth int, path string) bool {
+		paths = append(paths, strconv.Itoa(depth)+path)
+		return true
+	})
+	assertT.Equal([]string{"2/a/e[1]", "3/a/e[1]/f"}, paths)
+}
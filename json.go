@@ -0,0 +1,218 @@
+package xmlcomparator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Conventions of converting JSON documents to XML trees; empty fields take default values.
+type JSONMapping struct {
+	// Name of the root element wrapping the document, "root" by default. If the name is empty and the document is
+	// an object with a single member, the member becomes the root element. Items of a top level array are named
+	// after `ArrayItemName` or "item".
+	RootName string
+	// Prefix of object members converted to attributes, "@" by default
+	AttributePrefix string
+	// Name of the object member with own text of the element, "#text" by default
+	TextKey string
+	// Name of elements wrapped by the element of an array member. By default arrays become repeated elements
+	// named after the member - `{"b": [1, 2]}` is `<b>1</b><b>2</b>`.
+	ArrayItemName string
+}
+
+// Parses JSON string into a tree of nodes. Object members become child elements in the order of the document,
+// scalar values become texts and `null` becomes an empty element. Names are element local names without namespaces.
+//   - jsonString - JSON string to convert
+//   - mapping - conversion conventions
+//
+// Returns: root node of the converted tree and error if any
+func UnmarshalJSONString(jsonString string, mapping JSONMapping) (*Node, error) {
+	conv := jsonConverter{dec: json.NewDecoder(strings.NewReader(jsonString)), mapping: mapping.withDefaults()}
+	conv.dec.UseNumber()
+
+	token, err := conv.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	rootName := conv.mapping.RootName
+	if mapping.RootName == "" {
+		rootName = "root"
+	}
+	var nodes []Node
+	if token == json.Delim('[') {
+		// Top level array is always wrapped
+		itemName := conv.mapping.ArrayItemName
+		if itemName == "" {
+			itemName = "item"
+		}
+		items, err := conv.arrayItems(itemName)
+		nodes = []Node{{XMLName: xml.Name{Local: rootName}, Children: items}}
+		if err != nil {
+			return nil, err
+		}
+	} else if nodes, err = conv.elements(token, rootName); err != nil {
+		return nil, err
+	}
+	if _, err = conv.dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	root := &nodes[0]
+	if mapping.RootName == "" && token == json.Delim('{') && len(root.Children) == 1 && len(root.Attrs) == 0 &&
+		root.CharData == "" {
+		root = &root.Children[0]
+	}
+	root.Parent = nil
+	root.linkChildren()
+	root.hashCode()
+	return root, nil
+}
+
+// Compares XML document with JSON document converted to XML tree - see `UnmarshalJSONString`.
+//   - xmlString - XML string, the first sample
+//   - jsonString - JSON string, the second sample
+//   - mapping - conventions of converting JSON
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareXMLWithJSON(xmlString string, jsonString string, mapping JSONMapping, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareXMLWithJSON(xmlString, jsonString, mapping)
+}
+
+// Compares XML document with JSON document - see `CompareXMLWithJSON`.
+func (cmp *Comparator) CompareXMLWithJSON(xmlString string, jsonString string, mapping JSONMapping) DiffRecorder {
+	limits := cmp.opts.documentLimits()
+	return compareInputs(func() (*Node, error) { return parseLimitedXML(xmlString, limits) },
+		func() (*Node, error) {
+			if limits.maxSize > 0 && int64(len(jsonString)) > limits.maxSize {
+				return nil, &LimitError{Unit: "bytes", Limit: limits.maxSize}
+			}
+			root, err := UnmarshalJSONString(jsonString, mapping)
+			if err != nil {
+				return nil, err
+			}
+			return existingNode(root, limits)
+		}, cmp.newRecorder())
+}
+
+func (mapping JSONMapping) withDefaults() JSONMapping {
+	if mapping.AttributePrefix == "" {
+		mapping.AttributePrefix = "@"
+	}
+	if mapping.TextKey == "" {
+		mapping.TextKey = "#text"
+	}
+	return mapping
+}
+
+// State of JSON conversion.
+type jsonConverter struct {
+	dec     *json.Decoder
+	mapping JSONMapping
+}
+
+// Converts the JSON value which first token was just read.
+//   - name - name of the member holding the value
+//
+// Returns: elements representing the value - several for arrays of repeated elements
+func (conv *jsonConverter) elements(token json.Token, name string) ([]Node, error) {
+	switch token {
+	case json.Delim('{'):
+		node, err := conv.object(name)
+		return []Node{node}, err
+	case json.Delim('['):
+		return conv.array(name)
+	}
+
+	text, err := scalarText(token)
+	if err != nil {
+		return nil, err
+	}
+	return []Node{{XMLName: xml.Name{Local: name}, CharData: text}}, nil
+}
+
+func (conv *jsonConverter) object(name string) (Node, error) {
+	node := Node{XMLName: xml.Name{Local: name}}
+	for conv.dec.More() {
+		token, err := conv.dec.Token()
+		if err != nil {
+			return node, err
+		}
+		key := token.(string)
+		if token, err = conv.dec.Token(); err != nil {
+			return node, err
+		}
+
+		switch {
+		case strings.HasPrefix(key, conv.mapping.AttributePrefix):
+			value, err := scalarText(token)
+			if err != nil {
+				return node, fmt.Errorf("attribute '%s': %w", key, err)
+			}
+			node.Attrs = append(node.Attrs, xml.Attr{Name: xml.Name{Local: key[len(conv.mapping.AttributePrefix):]}, Value: value})
+		case key == conv.mapping.TextKey:
+			text, err := scalarText(token)
+			if err != nil {
+				return node, fmt.Errorf("text of '%s': %w", name, err)
+			}
+			node.CharData += text
+		default:
+			children, err := conv.elements(token, key)
+			if err != nil {
+				return node, err
+			}
+			node.Children = append(node.Children, children...)
+		}
+	}
+	_, err := conv.dec.Token()
+	return node, err
+}
+
+func (conv *jsonConverter) array(name string) ([]Node, error) {
+	if conv.mapping.ArrayItemName == "" {
+		return conv.arrayItems(name)
+	}
+	items, err := conv.arrayItems(conv.mapping.ArrayItemName)
+	return []Node{{XMLName: xml.Name{Local: name}, Children: items}}, err
+}
+
+// Converts items of the array which start was just read.
+func (conv *jsonConverter) arrayItems(itemName string) ([]Node, error) {
+	items := make([]Node, 0)
+	for conv.dec.More() {
+		token, err := conv.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := conv.elements(token, itemName)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nodes...)
+	}
+	_, err := conv.dec.Token()
+	return items, err
+}
+
+// Text of a scalar JSON value; `null` is an empty text.
+func scalarText(token json.Token) (string, error) {
+	switch v := token.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", errors.New("scalar value expected")
+}
@@ -0,0 +1,80 @@
+package xmlcomparator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+)
+
+// JSON representation of the element.
+type jsonNode struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Attrs     []jsonAttr  `json:"attrs,omitempty"`
+	Text      string      `json:"text,omitempty"`
+	CDATA     bool        `json:"cdata,omitempty"`
+	Children  []*jsonNode `json:"children,omitempty"`
+}
+
+type jsonAttr struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Value     string `json:"value"`
+}
+
+// Serializes the node with its subtree as a JSON object with fields `name`, `namespace`, `attrs`, `text`, `cdata` and
+// `children`; empty fields are omitted. Namespace declarations are kept as attributes with namespace "xmlns"
+// (or name "xmlns" for the default one), own text is written as is.
+func (node *Node) ToJSON() ([]byte, error) {
+	return json.Marshal(node.toJSONNode())
+}
+
+func (node *Node) toJSONNode() *jsonNode {
+	jNode := &jsonNode{Name: nodeName(node), Namespace: nodeSpace(node), Text: node.CharData, CDATA: node.CDATA}
+	for i := range node.Attrs {
+		attr := &node.Attrs[i]
+		jNode.Attrs = append(jNode.Attrs, jsonAttr{Name: attrName(attr), Namespace: attrSpace(attr), Value: attr.Value})
+	}
+	for i := range node.Children {
+		jNode.Children = append(jNode.Children, node.Children[i].toJSONNode())
+	}
+	return jNode
+}
+
+// Restores the tree of nodes from JSON created with `ToJSON`.
+//   - data - JSON object
+//
+// Returns: root node of the tree and error if any
+func NodeFromJSON(data []byte) (*Node, error) {
+	var jNode jsonNode
+	if err := json.Unmarshal(data, &jNode); err != nil {
+		return nil, err
+	}
+
+	root, err := jNode.toNode()
+	if err != nil {
+		return nil, err
+	}
+	root.linkChildren()
+	root.hashCode()
+	return root, nil
+}
+
+func (jNode *jsonNode) toNode() (*Node, error) {
+	if jNode == nil || jNode.Name == "" {
+		return nil, errors.New("element without name")
+	}
+
+	node := &Node{XMLName: xml.Name{Space: jNode.Namespace, Local: jNode.Name}, CharData: jNode.Text, CDATA: jNode.CDATA}
+	for _, attr := range jNode.Attrs {
+		node.Attrs = append(node.Attrs, xml.Attr{Name: xml.Name{Space: attr.Namespace, Local: attr.Name}, Value: attr.Value})
+	}
+	for _, jChild := range jNode.Children {
+		child, err := jChild.toNode()
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, *child)
+	}
+	return node, nil
+}
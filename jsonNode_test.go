@@ -0,0 +1,36 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeToJSON(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<p:a xmlns:p="urn:p" k="1"><b><![CDATA[x<y]]></b><c/></p:a>`)
+	data, err := root.ToJSON()
+	assertT.Nil(err)
+	assertT.Equal(`{"name":"a","namespace":"urn:p","attrs":[{"name":"p","namespace":"xmlns","value":"urn:p"},{"name":"k","value":"1"}],`+
+		`"children":[{"name":"b","text":"x\u003cy","cdata":true},{"name":"c"}]}`, string(data))
+
+	restored, err := NodeFromJSON(data)
+	assertT.Nil(err)
+	assertT.True(restored.Equal(root))
+	assertT.Equal(root.Hash, restored.Hash)
+	assertT.Same(restored, restored.Children[1].Parent)
+	text, _ := restored.XML()
+	assertT.Equal(`<p:a xmlns:p="urn:p" k="1"><b>x&lt;y</b><c></c></p:a>`, text)
+}
+
+func TestNodeFromJSONErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	_, err := NodeFromJSON([]byte(`{"name":`))
+	assertT.NotNil(err)
+	_, err = NodeFromJSON([]byte(`{"name":"a","children":[{"text":"x"}]}`))
+	assertT.EqualError(err, "element without name")
+	_, err = NodeFromJSON([]byte(`{"name":"a","children":[null]}`))
+	assertT.EqualError(err, "element without name")
+}
@@ -0,0 +1,63 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalJSONString(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, err := UnmarshalJSONString(`{"order": {"@id": 7, "item": [{"#text": "pen", "@qty": 2}, "book"], "paid": true, "note": null}}`,
+		JSONMapping{})
+	assertT.NoError(err)
+	assertT.Nil(root.Parent)
+	assertT.Equal(`<order id="7"><item qty="2">pen</item><item>book</item><paid>true</paid><note></note></order>`,
+		root.Canonical())
+	assertT.Equal("/order/item[1]", root.Children[1].path())
+
+	root, err = UnmarshalJSONString(`{"a": 1, "b": [1.5, "x"]}`, JSONMapping{ArrayItemName: "li"})
+	assertT.NoError(err)
+	assertT.Equal(`<root><a>1</a><b><li>1.5</li><li>x</li></b></root>`, root.Canonical())
+
+	root, err = UnmarshalJSONString(`[{"_x": "1", "$": "t"}, 2]`, JSONMapping{RootName: "list", AttributePrefix: "_", TextKey: "$"})
+	assertT.NoError(err)
+	assertT.Equal(`<list><item x="1">t</item><item>2</item></list>`, root.Canonical())
+
+	root, err = UnmarshalJSONString(`{"a": {}}`, JSONMapping{RootName: "doc"})
+	assertT.NoError(err)
+	assertT.Equal(`<doc><a></a></doc>`, root.Canonical())
+}
+
+func TestUnmarshalJSONStringErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	_, err := UnmarshalJSONString(`{"a": 1`, JSONMapping{})
+	assertT.Error(err)
+	_, err = UnmarshalJSONString(`{"a": 1} 2`, JSONMapping{})
+	assertT.EqualError(err, "unexpected data after JSON value")
+	_, err = UnmarshalJSONString(`{"a": {"@x": [1]}}`, JSONMapping{})
+	assertT.EqualError(err, "attribute '@x': scalar value expected")
+	_, err = UnmarshalJSONString(`{"a": {"#text": {}}}`, JSONMapping{})
+	assertT.EqualError(err, "text of 'a': scalar value expected")
+}
+
+func TestCompareXMLWithJSON(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlString := `<order id="7"><item qty="2">pen</item><item>book</item><total>10.0</total></order>`
+	diffs := CompareXMLWithJSON(xmlString, `{"order": {"@id": "7", "item": [{"@qty": 2, "#text": "pen"}, "book"], "total": 10}}`,
+		JSONMapping{}, WithNumericTolerance(0.001)).GetStructuredDiffs()
+	assertT.Empty(diffs)
+
+	assertT.Equal([]string{"Attributes differ: 'id=7' vs 'id=8', path='/order'",
+		"Attributes differ: counts 1 vs 0: qty[0]:+1, path='/order/item[0]'"},
+		CompareXMLWithJSON(xmlString, `{"order": {"@id": "8", "item": ["pen", "book"], "total": 10.0}}`,
+			JSONMapping{}).GetMessages())
+
+	diffs = CompareXMLWithJSON(xmlString, `{"order"`, JSONMapping{}).GetStructuredDiffs()
+	assertT.Len(diffs, 1)
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+	assertT.Contains(diffs[0].Message, "Can't parse the second sample")
+}
@@ -0,0 +1,113 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Error of a document exceeding a limit set with `WithMaxNodes(...)`, `WithMaxDocumentSize(...)` or
+// `WithInternalEntities(...)`.
+type LimitError struct {
+	// "elements" or "bytes"
+	Unit string
+	// The exceeded limit
+	Limit int64
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("document exceeds the limit of %d %s", err.Limit, err.Unit)
+}
+
+// Limits of a compared document; zero values mean no limit.
+type documentLimits struct {
+	maxNodes int64
+	maxSize  int64
+	// Internal entities are expanded only when the limit is set
+	maxEntityExpansion int64
+}
+
+// Count of elements that a decoder can still produce.
+type nodeBudget struct {
+	left  int64
+	limit int64
+}
+
+// Budgets of decoders parsing documents with limited count of elements, see `Node.UnmarshalXML`
+var nodeBudgets sync.Map
+
+// Registers the budget of elements decoded by the decoder.
+//
+// Returns: function that unregisters the budget
+func (limits documentLimits) watch(dec *xml.Decoder) func() {
+	if limits.maxNodes <= 0 {
+		return func() {}
+	}
+	nodeBudgets.Store(dec, &nodeBudget{left: limits.maxNodes, limit: limits.maxNodes})
+	return func() { nodeBudgets.Delete(dec) }
+}
+
+// Accounts an element decoded by the decoder.
+//
+// Returns: `*LimitError` if the decoder has exceeded its budget
+func spendNode(dec *xml.Decoder) error {
+	value, ok := nodeBudgets.Load(dec)
+	if !ok {
+		return nil
+	}
+	budget := value.(*nodeBudget)
+	if budget.left--; budget.left < 0 {
+		return &LimitError{Unit: "elements", Limit: budget.limit}
+	}
+	return nil
+}
+
+// Reader of a document failing with `*LimitError` when the document is larger than the limit.
+func (limits documentLimits) reader(r io.Reader) io.Reader {
+	if limits.maxSize <= 0 {
+		return r
+	}
+	return &sizeLimitedReader{r: r, left: limits.maxSize, limit: limits.maxSize}
+}
+
+type sizeLimitedReader struct {
+	r     io.Reader
+	left  int64
+	limit int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		// Only the end of input is allowed after the limit
+		var probe [1]byte
+		n, err := r.r.Read(probe[:])
+		if n > 0 {
+			return 0, &LimitError{Unit: "bytes", Limit: r.limit}
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.r.Read(p)
+	r.left -= int64(n)
+	return n, err
+}
+
+// Checks the count of elements of a tree that wasn't parsed with limits.
+func (limits documentLimits) checkNodes(node *Node) error {
+	if limits.maxNodes <= 0 {
+		return nil
+	}
+	count := int64(0)
+	node.walk(func(*Node) bool {
+		count++
+		return count <= limits.maxNodes
+	})
+	if count > limits.maxNodes {
+		return &LimitError{Unit: "elements", Limit: limits.maxNodes}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package xmlcomparator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxNodes(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample := `<a><b/><c><d/></c></a>`
+	diffs := Compare(xmlSample, xmlSample, WithMaxNodes(4))
+	assertT.Equal(emptyList, diffs.GetMessages())
+	assertT.Nil(diffs.Err())
+
+	diffs = Compare(xmlSample, xmlSample, WithMaxNodes(3))
+	assertT.Equal([]string{"Can't parse the first sample: document exceeds the limit of 3 elements"}, diffs.GetMessages())
+	var limitErr *LimitError
+	assertT.True(errors.As(diffs.Err(), &limitErr))
+	assertT.Equal(&LimitError{Unit: "elements", Limit: 3}, limitErr)
+
+	_, err := CompareContext(context.Background(), `<a/>`, xmlSample, WithMaxNodes(3))
+	assertT.Equal(&LimitError{Unit: "elements", Limit: 3}, err)
+
+	root, _ := parseXML(xmlSample)
+	assertT.NotNil(CompareNodes(root, root, WithMaxNodes(3)).Err())
+	assertT.Nil(CompareNodes(root, root, WithMaxNodes(4)).Err())
+	assertT.NotNil(CompareXmlStreams(strings.NewReader(xmlSample), strings.NewReader(xmlSample), WithMaxNodes(3)).Err())
+	assertT.Nil(CompareXmlStreams(strings.NewReader(xmlSample), strings.NewReader(xmlSample), WithMaxNodes(4)).Err())
+}
+
+func TestMaxDocumentSize(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample := `<a><b>text</b></a>`
+	assertT.Nil(Compare(xmlSample, xmlSample, WithMaxDocumentSize(int64(len(xmlSample)))).Err())
+	diffs := Compare(xmlSample, xmlSample, WithMaxDocumentSize(10))
+	assertT.Equal([]string{"Can't parse the first sample: document exceeds the limit of 10 bytes"}, diffs.GetMessages())
+	assertT.Equal(&LimitError{Unit: "bytes", Limit: 10}, diffs.Err())
+
+	diffs = CompareXmlReaders(strings.NewReader(`<a/>`), strings.NewReader(xmlSample), WithMaxDocumentSize(10))
+	assertT.Equal(&LimitError{Unit: "bytes", Limit: 10}, diffs.Err())
+	assertT.Equal([]string{"Can't parse the second sample: document exceeds the limit of 10 bytes"}, diffs.GetMessages())
+	assertT.Nil(CompareXmlReaders(strings.NewReader(xmlSample), strings.NewReader(xmlSample),
+		WithMaxDocumentSize(int64(len(xmlSample)))).Err())
+
+	diffs = CompareXmlStreams(strings.NewReader(xmlSample), strings.NewReader(xmlSample), WithMaxDocumentSize(10))
+	assertT.Equal(&LimitError{Unit: "bytes", Limit: 10}, diffs.Err())
+}
@@ -0,0 +1,202 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// Namespace declarations in scope of an element.
+type namespaceScope struct {
+	// Prefixes by namespace URI
+	prefixes map[string]string
+	// Namespace of unprefixed elements
+	defaultSpace string
+	// Count of generated prefixes
+	generated *int
+}
+
+// Serializes the node with its subtree as XML - `Encoder` requirement.
+// Namespaces are declared where they are used first, with prefixes of the parsed document where possible.
+// Own text keeps its position among children; comments and processing instructions are not preserved.
+func (node *Node) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	scope := namespaceScope{prefixes: make(map[string]string), generated: new(int)}
+	return node.encode(e, scope, inheritedPrefixes(node))
+}
+
+// Serializes the node with its subtree as XML - see `MarshalXML`.
+//
+// Returns: XML string and encoding error if any
+func (node *Node) XML() (string, error) {
+	var buf strings.Builder
+	if err := xml.NewEncoder(&buf).Encode(node); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Prefixes declared by ancestors - used for namespaces of the serialized subtree.
+func inheritedPrefixes(node *Node) map[string]string {
+	hints := make(map[string]string)
+	for ancestor := node.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		for i := range ancestor.Attrs {
+			attr := &ancestor.Attrs[i]
+			if _, ok := hints[attr.Value]; !ok && attrSpace(attr) == "xmlns" {
+				hints[attr.Value] = attrName(attr)
+			}
+		}
+	}
+	return hints
+}
+
+func (node *Node) encode(e *xml.Encoder, parentScope namespaceScope, hints map[string]string) error {
+	start, scope := node.startElement(parentScope, hints)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	// Whitespace between children is formatting unless the element has mixed content
+	segments := node.ownTextSegments()
+	mixed := len(node.Children) == 0 || strings.TrimSpace(node.CharData) != ""
+	for i := range node.Children {
+		if err := encodeText(e, segments[i], mixed); err != nil {
+			return err
+		}
+		if err := node.Children[i].encode(e, scope, hints); err != nil {
+			return err
+		}
+	}
+	if err := encodeText(e, segments[len(node.Children)], mixed); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeText(e *xml.Encoder, text string, mixed bool) error {
+	if !mixed || text == "" {
+		return nil
+	}
+	return e.EncodeToken(xml.CharData(text))
+}
+
+// Start tag of the element with qualified names and necessary namespace declarations.
+//
+// Returns: the tag and namespace declarations in scope of the element
+func (node *Node) startElement(parentScope namespaceScope, hints map[string]string) (xml.StartElement, namespaceScope) {
+	scope := namespaceScope{prefixes: make(map[string]string, len(parentScope.prefixes)), defaultSpace: parentScope.defaultSpace,
+		generated: parentScope.generated}
+	for uri, prefix := range parentScope.prefixes {
+		scope.prefixes[uri] = prefix
+	}
+
+	// Own declarations go first
+	attrs := make([]xml.Attr, 0, len(node.Attrs)+1)
+	for i := range node.Attrs {
+		attr := &node.Attrs[i]
+		switch {
+		case attrSpace(attr) == "xmlns":
+			scope.prefixes[attr.Value] = attrName(attr)
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + attrName(attr)}, Value: attr.Value})
+		case attrSpace(attr) == "" && attrName(attr) == "xmlns":
+			scope.defaultSpace = attr.Value
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: attr.Value})
+		}
+	}
+
+	name := nodeName(node)
+	space := nodeSpace(node)
+	prefix, declared := scope.prefixes[space]
+	switch {
+	case space == scope.defaultSpace:
+	case space == "":
+		scope.defaultSpace = ""
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: ""})
+	case declared:
+		name = prefix + ":" + name
+	case isUndeclaredPrefix(space):
+		name = space + ":" + name
+	case hints[space] != "":
+		prefix = hints[space]
+		scope.prefixes[space] = prefix
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: space})
+		name = prefix + ":" + name
+	default:
+		scope.defaultSpace = space
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: space})
+	}
+
+	for i := range node.Attrs {
+		attr := &node.Attrs[i]
+		if !isNameSpaceAttr(attr) {
+			qName := scope.attrName(attr, hints, &attrs)
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: qName}, Value: attr.Value})
+		}
+	}
+
+	return xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}, scope
+}
+
+// Qualified name of the attribute; declares the namespace if necessary.
+func (scope *namespaceScope) attrName(attr *xml.Attr, hints map[string]string, attrs *[]xml.Attr) string {
+	space := attrSpace(attr)
+	switch {
+	case space == "":
+		return attrName(attr)
+	case space == xmlNamespace:
+		return "xml:" + attrName(attr)
+	case isUndeclaredPrefix(space):
+		return space + ":" + attrName(attr)
+	}
+	if prefix, ok := scope.prefixes[space]; ok {
+		return prefix + ":" + attrName(attr)
+	}
+
+	prefix := hints[space]
+	if prefix == "" {
+		*scope.generated++
+		prefix = "ns" + strconv.Itoa(*scope.generated)
+	}
+	scope.prefixes[space] = prefix
+	*attrs = append(*attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: space})
+	return prefix + ":" + attrName(attr)
+}
+
+// Checks whether the namespace is a prefix left unresolved by the decoder rather than a URI.
+func isUndeclaredPrefix(space string) bool {
+	return !strings.ContainsAny(space, ":/")
+}
+
+// Serializes the node with its subtree as consistently indented XML - one element per line, own text of elements
+// with children on a separate line before them, empty elements as `<x/>`. Texts are trimmed.
+//   - indent - indentation unit, e.g. two spaces
+func (node *Node) Pretty(indent string) string {
+	scope := namespaceScope{prefixes: make(map[string]string), generated: new(int)}
+	return strings.Join(node.prettyLines(scope, inheritedPrefixes(node), "", indent), "\n")
+}
+
+func (node *Node) prettyLines(parentScope namespaceScope, hints map[string]string, prefix string, indent string) []string {
+	start, scope := node.startElement(parentScope, hints)
+	startTag := "<" + start.Name.Local
+	for _, attr := range start.Attr {
+		startTag += " " + attr.Name.Local + `="` + escapeAttr(attr.Value) + `"`
+	}
+
+	text := escapeText(trimmedText(node))
+	endTag := "</" + start.Name.Local + ">"
+	switch {
+	case len(node.Children) == 0 && text == "":
+		return []string{prefix + startTag + "/>"}
+	case len(node.Children) == 0:
+		return []string{prefix + startTag + ">" + text + endTag}
+	}
+
+	lines := []string{prefix + startTag + ">"}
+	if text != "" {
+		lines = append(lines, prefix+indent+text)
+	}
+	for i := range node.Children {
+		lines = append(lines, node.Children[i].prettyLines(scope, hints, prefix+indent, indent)...)
+	}
+	return append(lines, prefix+endTag)
+}
@@ -0,0 +1,88 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeXML(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample := `<a xmlns="urn:a" xmlns:p="urn:p" x="1&amp;"><p:b p:y="2">t&lt;</p:b><c/><d xmlns="urn:d"><e/></d></a>`
+	root, err := parseXML(sample)
+	assertT.Nil(err)
+
+	text, err := root.XML()
+	assertT.Nil(err)
+	assertT.Equal(`<a xmlns="urn:a" xmlns:p="urn:p" x="1&amp;"><p:b p:y="2">t&lt;</p:b><c></c><d xmlns="urn:d"><e></e></d></a>`, text)
+	assertT.Equal(0, len(Compare(sample, text, WithStrictNamespaces()).GetDiffs()))
+
+	// Ancestors declarations are used for subtrees
+	text, _ = root.Children[0].XML()
+	assertT.Equal(`<p:b xmlns:p="urn:p" p:y="2">t&lt;</p:b>`, text)
+	text, _ = root.Children[1].XML()
+	assertT.Equal(`<c xmlns="urn:a"></c>`, text)
+
+	data, err := xml.Marshal(&root.Children[2])
+	assertT.Nil(err)
+	assertT.Equal(`<d xmlns="urn:d"><e></e></d>`, string(data))
+}
+
+func TestNodeXMLMixedContent(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample := `<p>Hello <b>w</b> there, <i>x &amp; y</i>!</p>`
+	root, _ := parseXML(sample)
+	text, err := root.XML()
+	assertT.Nil(err)
+	assertT.Equal(sample, text)
+	roundTrip, _ := parseXML(text)
+	assertT.Equal(root.Canonical(), roundTrip.Canonical())
+
+	// Formatting whitespace of element-only content is dropped
+	root, _ = parseXML("<a>\n  <b>1</b>\n  <c/>\n</a>")
+	text, _ = root.XML()
+	assertT.Equal(`<a><b>1</b><c></c></a>`, text)
+
+	// Positions of texts in modified elements are unknown - the text is written before children
+	root, _ = parseXML(`<p>Hello <b>w</b> there</p>`)
+	root.AppendChild(E("i"))
+	text, _ = root.XML()
+	assertT.Equal(`<p>Hello  there<b>w</b><i></i></p>`, text)
+}
+
+func TestNodeXMLNamespaces(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Undeclared default namespace, generated prefix, xml namespace and an undeclared prefix
+	node := &Node{XMLName: xml.Name{Space: "urn:x", Local: "a"}, Attrs: []xml.Attr{
+		{Name: xml.Name{Space: "urn:y", Local: "k"}, Value: "1"},
+		{Name: xml.Name{Space: xmlNamespace, Local: "lang"}, Value: "en"},
+		{Name: xml.Name{Space: "xsi", Local: "nil"}, Value: "true"}},
+		Children: []Node{{XMLName: xml.Name{Local: "b"}, CharData: "  "}}}
+	text, err := node.XML()
+	assertT.Nil(err)
+	assertT.Equal(`<a xmlns="urn:x" xmlns:ns1="urn:y" ns1:k="1" xml:lang="en" xsi:nil="true"><b xmlns="">  </b></a>`, text)
+
+	// Whitespace around children is dropped
+	root, _ := parseXML("<a>\n  <b>1</b>\n</a>")
+	text, _ = root.XML()
+	assertT.Equal(`<a><b>1</b></a>`, text)
+}
+
+func TestNodePretty(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a x="1"><b> t&lt; </b>text<c/><p:d xmlns:p="urn:p"><p:e/></p:d></a>`)
+	assertT.Equal(`<a x="1">
+	text
+	<b>t&lt;</b>
+	<c/>
+	<p:d xmlns:p="urn:p">
+		<p:e/>
+	</p:d>
+</a>`, root.Pretty("\t"))
+	assertT.Equal(`<c/>`, root.Children[1].Pretty("  "))
+}
@@ -0,0 +1,281 @@
+package xmlcomparator
+
+import (
+	"math"
+	"slices"
+)
+
+// Pair of matched indices in the siblings lists of both samples.
+type matchedPair struct {
+	idx1 int
+	idx2 int
+}
+
+// Matches children of two nodes regardless of their order.
+// Identical subtrees are paired first, then remaining elements with the same name are paired according to the strategy.
+//   - indices1, indices2 - indices of children participating in comparison
+//   - namer - key of elements with the same name
+//
+// Returns: matched pairs and indices of unmatched children from both samples
+func matchUnordered(node1 *Node, node2 *Node, indices1 []int, indices2 []int, namer func(*Node) string,
+	strategy Strategy) ([]matchedPair, []int, []int) {
+	pairs, rest1, rest2 := matchIdentical(node1, node2, indices1, indices2)
+
+	keys1 := make([]string, len(rest1))
+	for k, i := range rest1 {
+		keys1[k] = namer(&node1.Children[i])
+	}
+	keys2 := make([]string, len(rest2))
+	for k, j := range rest2 {
+		keys2[k] = namer(&node2.Children[j])
+	}
+	modified := matchSameNamed(keys1, keys2, func(k1, k2 int) float64 {
+		return nodeSimilarity(&node1.Children[rest1[k1]], &node2.Children[rest2[k2]])
+	}, strategy)
+
+	matched1 := make(map[int]bool)
+	matched2 := make(map[int]bool)
+	for _, pair := range modified {
+		pairs = append(pairs, matchedPair{rest1[pair.idx1], rest2[pair.idx2]})
+		matched1[rest1[pair.idx1]] = true
+		matched2[rest2[pair.idx2]] = true
+	}
+
+	unmatched1 := slices.DeleteFunc(rest1, func(i int) bool { return matched1[i] })
+	unmatched2 := slices.DeleteFunc(rest2, func(j int) bool { return matched2[j] })
+	return pairs, unmatched1, unmatched2
+}
+
+// Pairs elements with the same name from two lists.
+// `StrategyFast` pairs them in order, other strategies maximize the total similarity of pairs.
+//   - keys1, keys2 - names of elements
+//   - similarity - similarity of elements by their positions in the lists
+//
+// Returns: pairs of positions in the lists ordered by the first one
+func matchSameNamed(keys1 []string, keys2 []string, similarity func(k1, k2 int) float64, strategy Strategy) []matchedPair {
+	names := make([]string, 0)
+	groups1 := make(map[string][]int)
+	groups2 := make(map[string][]int)
+	for k, key := range keys1 {
+		if _, ok := groups1[key]; !ok {
+			names = append(names, key)
+		}
+		groups1[key] = append(groups1[key], k)
+	}
+	for k, key := range keys2 {
+		groups2[key] = append(groups2[key], k)
+	}
+
+	pairs := make([]matchedPair, 0)
+	for _, name := range names {
+		group1, group2 := groups1[name], groups2[name]
+		if len(group2) == 0 {
+			continue
+		}
+		if strategy == StrategyFast {
+			for r := range min(len(group1), len(group2)) {
+				pairs = append(pairs, matchedPair{group1[r], group2[r]})
+			}
+			continue
+		}
+
+		scores := make([][]float64, len(group1))
+		for r, k1 := range group1 {
+			scores[r] = make([]float64, len(group2))
+			for c, k2 := range group2 {
+				scores[r][c] = similarity(k1, k2)
+			}
+		}
+		for r, c := range assignSimilar(scores, strategy) {
+			if c >= 0 {
+				pairs = append(pairs, matchedPair{group1[r], group2[c]})
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(p1, p2 matchedPair) int { return p1.idx1 - p2.idx1 })
+	return pairs
+}
+
+// Largest group of same-named siblings matched optimally with `StrategyBalanced`; larger groups are matched greedily.
+const maxOptimalMatching = 256
+
+// Assigns columns to rows of the similarity matrix maximizing the total similarity with as many pairs as possible.
+// Uses the Hungarian algorithm, except groups over `maxOptimalMatching` rows or columns with `StrategyBalanced`
+// which get the most similar column for each row in order.
+//
+// Returns: assigned column for each row or -1
+func assignSimilar(scores [][]float64, strategy Strategy) []int {
+	rows, cols := len(scores), len(scores[0])
+	if strategy != StrategyPrecise && max(rows, cols) > maxOptimalMatching {
+		return assignGreedy(scores)
+	}
+	if rows <= cols {
+		return hungarian(rows, cols, func(r, c int) float64 { return 1 - scores[r][c] })
+	}
+
+	// The algorithm needs no more rows than columns
+	byCol := hungarian(cols, rows, func(r, c int) float64 { return 1 - scores[c][r] })
+	assignment := make([]int, rows)
+	for r := range assignment {
+		assignment[r] = -1
+	}
+	for c, r := range byCol {
+		assignment[r] = c
+	}
+	return assignment
+}
+
+func assignGreedy(scores [][]float64) []int {
+	assignment := make([]int, len(scores))
+	taken := make([]bool, len(scores[0]))
+	for r := range scores {
+		assignment[r] = -1
+		bestScore := -1.0
+		for c, score := range scores[r] {
+			if !taken[c] && score > bestScore {
+				assignment[r], bestScore = c, score
+			}
+		}
+		if assignment[r] >= 0 {
+			taken[assignment[r]] = true
+		}
+	}
+	return assignment
+}
+
+// Minimal cost assignment with potentials in O(rows^2*cols) (https://en.wikipedia.org/wiki/Hungarian_algorithm).
+//   - rows, cols - dimensions of the cost matrix, `rows <= cols`
+//   - cost - cost of assigning the column to the row
+//
+// Returns: assigned column for each row
+func hungarian(rows int, cols int, cost func(r, c int) float64) []int {
+	// 1-based arrays, column 0 is a fictitious one
+	u := make([]float64, rows+1)
+	v := make([]float64, cols+1)
+	rowOf := make([]int, cols+1)
+	way := make([]int, cols+1)
+	for r := 1; r <= rows; r++ {
+		rowOf[0] = r
+		c0 := 0
+		minv := make([]float64, cols+1)
+		used := make([]bool, cols+1)
+		for c := range minv {
+			minv[c] = math.Inf(1)
+		}
+		for rowOf[c0] != 0 {
+			used[c0] = true
+			r0, delta, c1 := rowOf[c0], math.Inf(1), 0
+			for c := 1; c <= cols; c++ {
+				if used[c] {
+					continue
+				}
+				if cur := cost(r0-1, c-1) - u[r0] - v[c]; cur < minv[c] {
+					minv[c], way[c] = cur, c0
+				}
+				if minv[c] < delta {
+					delta, c1 = minv[c], c
+				}
+			}
+			for c := 0; c <= cols; c++ {
+				if used[c] {
+					u[rowOf[c]] += delta
+					v[c] -= delta
+				} else {
+					minv[c] -= delta
+				}
+			}
+			c0 = c1
+		}
+		for c0 != 0 {
+			c1 := way[c0]
+			rowOf[c0] = rowOf[c1]
+			c0 = c1
+		}
+	}
+
+	assignment := make([]int, rows)
+	for c := 1; c <= cols; c++ {
+		if rowOf[c] != 0 {
+			assignment[rowOf[c]-1] = c - 1
+		}
+	}
+	return assignment
+}
+
+// Pairs identical children of two nodes regardless of their order.
+//
+// Returns: matched pairs and indices of remaining children from both samples
+func matchIdentical(node1 *Node, node2 *Node, indices1 []int, indices2 []int) ([]matchedPair, []int, []int) {
+	pairs := make([]matchedPair, 0, len(indices1))
+
+	byHash := make(map[uint64][]int)
+	for _, j := range indices2 {
+		hash := node2.Children[j].Hash
+		byHash[hash] = append(byHash[hash], j)
+	}
+	matched2 := make(map[int]bool)
+	rest1 := make([]int, 0)
+	for _, i := range indices1 {
+		hash := node1.Children[i].Hash
+		candidates := byHash[hash]
+		k := slices.IndexFunc(candidates, func(j int) bool { return identicalSubtrees(&node1.Children[i], &node2.Children[j]) })
+		if k < 0 {
+			rest1 = append(rest1, i)
+			continue
+		}
+		pairs = append(pairs, matchedPair{i, candidates[k]})
+		matched2[candidates[k]] = true
+		byHash[hash] = slices.Delete(candidates, k, k+1)
+	}
+	rest2 := make([]int, 0)
+	for _, j := range indices2 {
+		if !matched2[j] {
+			rest2 = append(rest2, j)
+		}
+	}
+	return pairs, rest1, rest2
+}
+
+// Estimates similarity of two nodes as a number in the range [0, 1].
+// Takes into account the name, own text, attributes and hashes of children.
+func nodeSimilarity(node1 *Node, node2 *Node) float64 {
+	if identicalSubtrees(node1, node2) {
+		return 1.0
+	}
+
+	matched, total := 0, 1
+	if nodeName(node1) == nodeName(node2) {
+		matched++
+	}
+
+	total++
+	if trimmedText(node1) == trimmedText(node2) {
+		matched++
+	}
+
+	attrs2 := make(map[string]string)
+	for _, attr := range node2.extractAttributes() {
+		attrs2[attrName(&attr)] = attr.Value
+	}
+	attrs1 := node1.extractAttributes()
+	total += max(len(attrs1), len(attrs2))
+	for _, attr := range attrs1 {
+		if value, ok := attrs2[attrName(&attr)]; ok && value == attr.Value {
+			matched++
+		}
+	}
+
+	hashes2 := make(map[uint64]int)
+	for i := range node2.Children {
+		hashes2[node2.Children[i].Hash]++
+	}
+	total += max(len(node1.Children), len(node2.Children))
+	for i := range node1.Children {
+		if hashes2[node1.Children[i].Hash] > 0 {
+			hashes2[node1.Children[i].Hash]--
+			matched++
+		}
+	}
+
+	return float64(matched) / float64(total)
+}
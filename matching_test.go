@@ -0,0 +1,114 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreOrder(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal(emptyList, Compare(`<a><x/><y/></a>`, `<a><y/><x/></a>`, WithIgnoreOrder()).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlString1, `<note color="red"><body>Don't forget me this weekend!</body><heading>Reminder</heading>
+		<date>2023-08-27T16:27:55+00:00</date><from>Jani</from><to>Tove</to></note>`, WithIgnoreOrder()).GetMessages())
+}
+
+func TestIgnoreOrderPartialMatches(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><item id="1"><v>1</v><w/></item><item id="2"><v>2</v><w/></item><b/></a>`
+	xmlSample2 := `<a><c/><item id="2"><v>2</v><w/></item><item id="1"><v>3</v><w/></item></a>`
+	assertT.Equal([]string{"Children differ: counts 3 vs 3: b[2]:+1, c[0]:-1, path='/a'",
+		"Node texts differ: '1' vs '3', path='/a/item[0]/v[0]'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoreOrder()).GetMessages())
+}
+
+func TestIgnoreOrderPicksSimilar(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><p x="1" y="1">A</p><p x="2" y="2">B</p></a>`
+	xmlSample2 := `<a><p x="2" y="2">C</p><p x="1" y="1">D</p></a>`
+	assertT.Equal([]string{"Node texts differ: 'A' vs 'D', path='/a/p[0]'", "Node texts differ: 'B' vs 'C', path='/a/p[1]'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoreOrder()).GetMessages())
+}
+
+func TestIgnoreOrderOptimalMatching(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Greedy matching pairs the first element with the first of equally similar candidates
+	xmlSample1 := `<a><b x="1"/><b x="1" y="1" w="1"/></a>`
+	xmlSample2 := `<a><b x="1" y="1"/><b x="1" z="1"/></a>`
+	assertT.Equal([]string{"Attributes differ: counts 1 vs 2: z[1]:-1, path='/a/b[0]'",
+		"Attributes differ: counts 3 vs 2: w[2]:+1, path='/a/b[1]'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoreOrder()).GetMessages())
+}
+
+func TestAssignSimilar(t *testing.T) {
+	assertT := assert.New(t)
+
+	scores := [][]float64{{0.75, 0.75}, {0.8, 0.6}}
+	assertT.Equal([]int{1, 0}, assignSimilar(scores, StrategyBalanced))
+	assertT.Equal([]int{0, 1}, assignGreedy(scores))
+
+	assertT.Equal([]int{-1, 0, 1}, assignSimilar([][]float64{{0.1, 0.1}, {0.9, 0.2}, {0.5, 0.8}}, StrategyBalanced))
+	assertT.Equal([]int{2, 0}, assignSimilar([][]float64{{0.1, 0.2, 0.9}, {0.9, 0.3, 0.95}}, StrategyBalanced))
+}
+
+func TestNodeSimilarity(t *testing.T) {
+	assertT := assert.New(t)
+
+	root1, _ := parseXML(`<a x="1"><b/><c/></a>`)
+	root2, _ := parseXML(`<a x="1"><b/><d/></a>`)
+	root3, _ := parseXML(`<e>text</e>`)
+
+	assertT.Equal(1.0, nodeSimilarity(root1, root1))
+	assertT.InDelta(0.8, nodeSimilarity(root1, root2), 1e-6)
+	assertT.InDelta(0.0, nodeSimilarity(root1, root3), 1e-6)
+}
+
+func TestUnorderedPaths(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<catalog><items><x/><y/></items><seq><x/><y/></seq></catalog>`
+	xmlSample2 := `<catalog><items><y/><x/></items><seq><y/><x/></seq></catalog>`
+	assertT.Equal([]string{"Children order differ for 2 nodes, path='/catalog/seq[1]'"},
+		Compare(xmlSample1, xmlSample2, WithUnorderedPaths("/catalog/items")).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithUnorderedPaths("/catalog/*")).GetMessages())
+	assertT.Equal(2, len(Compare(xmlSample1, xmlSample2, WithUnorderedPaths("/catalog")).GetMessages()))
+}
+
+func TestStrategies(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b x="1"/><b x="1" y="1" w="1"/></a>`
+	xmlSample2 := `<a><b x="1" y="1"/><b x="1" z="1"/></a>`
+	assertT.Equal([]string{"Attributes differ: counts 1 vs 2: y[1]:-1, path='/a/b[0]'",
+		"Attributes differ: counts 3 vs 2: y[1]:+2, z[1]:-1, path='/a/b[1]'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoreOrder(), WithStrategy(StrategyFast)).GetMessages())
+	assertT.Equal(Compare(xmlSample1, xmlSample2, WithIgnoreOrder()).GetMessages(),
+		Compare(xmlSample1, xmlSample2, WithIgnoreOrder(), WithStrategy(StrategyPrecise)).GetMessages())
+
+	// Removed and added elements of ordered children
+	xmlSample1 = `<a><b x="1"/><c/><b x="1" y="1" w="1"/></a>`
+	xmlSample2 = `<a><b x="1" y="1"/><d/><b x="1" z="1"/></a>`
+	assertT.Equal([]string{"Children differ: counts 3 vs 3: c[1]:+1, d[1]:-1, path='/a'",
+		"Attributes differ: counts 1 vs 2: y[1]:-1, path='/a/b[0]'",
+		"Attributes differ: counts 3 vs 2: y[1]:+2, z[1]:-1, path='/a/b[2]'"}, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 3 vs 3: c[1]:+1, d[1]:-1, path='/a'",
+		"Attributes differ: counts 1 vs 2: z[1]:-1, path='/a/b[0]'",
+		"Attributes differ: counts 3 vs 2: w[2]:+1, path='/a/b[2]'"},
+		Compare(xmlSample1, xmlSample2, WithStrategy(StrategyPrecise)).GetMessages())
+}
+
+func TestGreedyAlignment(t *testing.T) {
+	assertT := assert.New(t)
+
+	root1, _ := parseXML(`<a><x/><b/><c/><d/><e/></a>`)
+	root2, _ := parseXML(`<a><b/><c/><y/><e/><d/></a>`)
+	edits := make([]string, 0)
+	for _, diff := range greedyAlignment(root1.Children, root2.Children, true) {
+		edits = append(edits, map[editType]string{diffDelete: "-", diffSame: "=", diffAdd: "+"}[diff.t]+nodeName(&diff.e))
+	}
+	assertT.Equal([]string{"-x", "=b", "=c", "+y", "-d", "=e", "+d"}, edits)
+}
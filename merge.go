@@ -0,0 +1,464 @@
+package xmlcomparator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Part of the document changed differently in both merged versions.
+type MergeConflict struct {
+	// Path of the element in the merged document
+	Path string
+	// Name of the conflicting attribute; empty for conflicts of elements and texts
+	Attr string
+	// What conflicts - "element", "name", "text" or "attribute"
+	Subject string
+	// Values in the base, mine and theirs versions - texts, attribute values, names or serialized elements;
+	// empty for missing ones
+	Base   string
+	Mine   string
+	Theirs string
+}
+
+// Describes the conflict in a single line.
+func (conflict MergeConflict) String() string {
+	subject := conflict.Subject
+	if conflict.Attr != "" {
+		subject += " '" + conflict.Attr + "'"
+	}
+	return fmt.Sprintf("Conflicting %s: base '%s', mine '%s', theirs '%s', path='%s'", subject, conflict.Base, conflict.Mine,
+		conflict.Theirs, conflict.Path)
+}
+
+// Result of a three-way merge.
+type MergeResult struct {
+	// Merged document; conflicting parts are taken from mine version
+	Merged *Node
+	// Merged document as text - changes are spliced into the parsed text of mine version, so its prolog,
+	// comments, formatting and self-closing tags are kept; elements taken from theirs version keep their markup
+	Text string
+	// Conflicts in document order
+	Conflicts []MergeConflict
+}
+
+// State of a three-way merge.
+type merger struct {
+	opts      []Option
+	options   *compareOptions
+	conflicts []MergeConflict
+	// Merged text; `nil` while merged elements are not spliced into mine text
+	out *bytes.Buffer
+}
+
+// Merges changes made to two versions of a document since their common base, e.g. as a git merge driver.
+// Elements are paired by names and positions among siblings with the same name or by `WithKeyAttributes` keys.
+// A part changed in only one version takes that change; parts changed differently in both versions are conflicts
+// that keep mine version. Children keep the order of mine version with children added by theirs version inserted
+// after their preceding siblings. Comparison options decide which changes are significant, e.g. `WithIgnoredAttributes`.
+//   - base - common ancestor version
+//   - mine, theirs - versions with changes to merge
+//   - opts - comparison options
+//
+// Returns: merged document with conflicts, or error if a version can't be parsed
+func Merge(base string, mine string, theirs string, opts ...Option) (*MergeResult, error) {
+	roots := make([]*Node, 3)
+	for i, sample := range []string{base, mine, theirs} {
+		root, err := parseXML(sample)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse the %s version: %w", []string{"base", "mine", "theirs"}[i], err)
+		}
+		roots[i] = root
+	}
+	return MergeNodes(roots[0], roots[1], roots[2], opts...), nil
+}
+
+// Merges changes of parsed trees - see `Merge`; trees are not modified.
+func MergeNodes(base *Node, mine *Node, theirs *Node, opts ...Option) *MergeResult {
+	merger := &merger{opts: opts, options: newCompareOptions(opts), conflicts: make([]MergeConflict, 0)}
+	if mine.source != "" && mine.Parent == nil {
+		merger.out = &bytes.Buffer{}
+		merger.out.WriteString(mine.source[:mine.startOffset])
+	}
+	merged := merger.mergeNodes(base, mine, theirs, "/"+nodeName(mine))
+	merged.Parent = nil
+	merged.linkChildren()
+	merged.hashCode()
+
+	text := ""
+	if merger.out != nil {
+		merger.out.WriteString(mine.source[mine.endOffset:])
+		text = merger.out.String()
+	} else {
+		text = serialized(merged)
+	}
+	return &MergeResult{Merged: merged, Text: text, Conflicts: merger.conflicts}
+}
+
+func (merger *merger) equal(node1 *Node, node2 *Node) bool {
+	return node1.Equal(node2, merger.opts...)
+}
+
+func (merger *merger) conflict(path string, attr string, subject string, base string, mine string, theirs string) {
+	merger.conflicts = append(merger.conflicts, MergeConflict{Path: path, Attr: attr, Subject: subject, Base: base, Mine: mine,
+		Theirs: theirs})
+}
+
+// Merges versions of an element present in all of them.
+//   - path - path of the element in the merged document
+func (merger *merger) mergeNodes(base *Node, mine *Node, theirs *Node, path string) *Node {
+	switch {
+	case merger.equal(base, mine):
+		if merger.out != nil && merger.equal(mine, theirs) {
+			merger.write(mine)
+		} else {
+			merger.write(theirs)
+		}
+		return theirs.Clone()
+	case merger.equal(base, theirs) || merger.equal(mine, theirs):
+		merger.write(mine)
+		return mine.Clone()
+	}
+
+	merged := &Node{XMLName: mine.XMLName, SelfClosing: mine.SelfClosing, CDATA: mine.CDATA}
+	switch {
+	case mine.XMLName == base.XMLName:
+		merged.XMLName = theirs.XMLName
+	case theirs.XMLName != base.XMLName && theirs.XMLName != mine.XMLName:
+		merger.conflict(path, "", "name", nodeName(base), nodeName(mine), nodeName(theirs))
+	}
+
+	merged.Attrs = merger.mergeAttributes(base, mine, theirs, path)
+
+	baseText, mineText, theirsText := trimmedText(base), trimmedText(mine), trimmedText(theirs)
+	merged.CharData = mine.CharData
+	switch {
+	case mineText == baseText:
+		merged.CharData, merged.CDATA = theirs.CharData, theirs.CDATA
+	case theirsText != baseText && theirsText != mineText:
+		merger.conflict(path, "", "text", baseText, mineText, theirsText)
+	}
+
+	// Text is replaced in elements without children only, otherwise the element is serialized as a whole
+	textFromTheirs := mineText == baseText && theirsText != mineText
+	out := merger.out
+	mark, spliced := 0, false
+	if out != nil {
+		mark = out.Len()
+		spliced = (!textFromTheirs || len(mine.Children) == 0 && len(theirs.Children) == 0 && len(theirs.Content) != 0) &&
+			merger.writeStartTag(merged, mine, theirs)
+	}
+	if !spliced {
+		merger.out = nil
+	}
+
+	var segments []string
+	if spliced {
+		segments = mine.rawSegments()
+		if textFromTheirs {
+			segments = []string{string(theirs.Content)}
+		}
+	}
+	merged.Children = merger.mergeChildren(base, mine, theirs, path, segments)
+
+	merger.out = out
+	if out != nil {
+		merger.writeEndTag(mark, spliced, merged, mine)
+	}
+	return merged
+}
+
+// Writes raw markup of the element or its serialization if the element was not parsed.
+func (merger *merger) write(node *Node) {
+	if merger.out == nil {
+		return
+	}
+	if markup := node.rawXML(); markup != "" {
+		merger.out.WriteString(markup)
+	} else {
+		merger.out.WriteString(serialized(node))
+	}
+}
+
+// Writes the start tag of the element merged from mine and theirs versions - raw tag of mine version if the name
+// and attributes are the same, otherwise a tag with prefixes of the versions.
+//
+// Returns: whether the tag was written; the element is serialized as a whole otherwise
+func (merger *merger) writeStartTag(merged *Node, mine *Node, theirs *Node) bool {
+	if mine.source == "" {
+		return false
+	}
+	if merged.XMLName == mine.XMLName && slices.Equal(merged.Attrs, mine.Attrs) {
+		merger.out.WriteString(mine.source[mine.startOffset:mine.startEnd])
+		return true
+	}
+
+	name, ok := prefixedName(merged.XMLName, mine, theirs)
+	if !ok {
+		return false
+	}
+	var tag strings.Builder
+	tag.WriteString("<" + name)
+	for i := range merged.Attrs {
+		attr := &merged.Attrs[i]
+		attrName, ok := prefixedAttrName(attr, mine, theirs)
+		if !ok {
+			return false
+		}
+		tag.WriteString(" " + attrName + `="` + escapeAttr(attr.Value) + `"`)
+	}
+	if mine.SelfClosing {
+		tag.WriteString("/")
+	}
+	merger.out.WriteString(tag.String() + ">")
+	return true
+}
+
+// Writes the end tag of the merged element or, if it can't be spliced, replaces the element with its serialization.
+//   - mark - length of the output before the element
+func (merger *merger) writeEndTag(mark int, spliced bool, merged *Node, mine *Node) {
+	if !spliced || mine.SelfClosing && (len(merged.Children) != 0 || trimmedText(merged) != "") {
+		merger.out.Truncate(mark)
+		merger.out.WriteString(serialized(merged))
+		return
+	}
+
+	if merged.XMLName == mine.XMLName {
+		merger.out.WriteString(mine.source[mine.startEnd+int64(len(mine.Content)) : mine.endOffset])
+	} else if !mine.SelfClosing {
+		name, _ := prefixedName(merged.XMLName, mine)
+		merger.out.WriteString("</" + name + ">")
+	}
+}
+
+// Name of the element with the prefix of the version it is taken from.
+//
+// Returns: qualified name and whether the prefix is known
+func prefixedName(name xml.Name, versions ...*Node) (string, bool) {
+	if name.Space == "" {
+		return name.Local, true
+	}
+	for _, version := range versions {
+		if version.XMLName == name && version.source != "" {
+			return qualifiedName(version.prefix, name.Local), true
+		}
+	}
+	return "", false
+}
+
+// Name of the attribute with the prefix of the version it is taken from.
+//
+// Returns: qualified name and whether the prefix is known
+func prefixedAttrName(attr *xml.Attr, versions ...*Node) (string, bool) {
+	switch {
+	case attrSpace(attr) == "":
+		return attrName(attr), true
+	case attrSpace(attr) == "xmlns":
+		return "xmlns:" + attrName(attr), true
+	case attrSpace(attr) == xmlNamespace:
+		return "xml:" + attrName(attr), true
+	}
+	for _, version := range versions {
+		if prefix, ok := version.attrPrefixes[attr.Name]; ok {
+			return qualifiedName(prefix, attrName(attr)), true
+		}
+	}
+	return "", false
+}
+
+func qualifiedName(prefix string, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+// Merges attributes by names; namespace declarations of mine version are kept.
+func (merger *merger) mergeAttributes(base *Node, mine *Node, theirs *Node, path string) []xml.Attr {
+	baseAttrs, mineAttrs, theirsAttrs := attrsByKey(base, merger.options), attrsByKey(mine, merger.options),
+		attrsByKey(theirs, merger.options)
+
+	merged := make([]xml.Attr, 0, len(mine.Attrs))
+	for i := range mine.Attrs {
+		attr := &mine.Attrs[i]
+		if isNameSpaceAttr(attr) {
+			merged = append(merged, *attr)
+			continue
+		}
+		key := merger.options.attrKey(attr)
+		if value, ok := merger.mergeAttribute(baseAttrs[key], attr, theirsAttrs[key], path); ok {
+			merged = append(merged, xml.Attr{Name: attr.Name, Value: value})
+		}
+	}
+	for i := range theirs.Attrs {
+		attr := &theirs.Attrs[i]
+		key := merger.options.attrKey(attr)
+		if _, ok := mineAttrs[key]; ok || isNameSpaceAttr(attr) {
+			continue
+		}
+		if value, ok := merger.mergeAttribute(baseAttrs[key], nil, attr, path); ok {
+			merged = append(merged, xml.Attr{Name: attr.Name, Value: value})
+		}
+	}
+	return merged
+}
+
+// Merges values of an attribute; `nil` stands for a missing attribute.
+//
+// Returns: merged value and whether the attribute is present in the merged element
+func (merger *merger) mergeAttribute(base *xml.Attr, mine *xml.Attr, theirs *xml.Attr, path string) (string, bool) {
+	baseValue, hasBase := attrValueOf(base)
+	mineValue, hasMine := attrValueOf(mine)
+	theirsValue, hasTheirs := attrValueOf(theirs)
+	switch {
+	case hasMine == hasBase && mineValue == baseValue:
+		return theirsValue, hasTheirs
+	case hasTheirs == hasBase && theirsValue == baseValue || hasTheirs == hasMine && theirsValue == mineValue:
+		return mineValue, hasMine
+	}
+
+	name := ""
+	for _, attr := range []*xml.Attr{mine, theirs, base} {
+		if attr != nil && name == "" {
+			name = attrQName(attr)
+		}
+	}
+	merger.conflict(path, name, "attribute", baseValue, mineValue, theirsValue)
+	return mineValue, hasMine
+}
+
+func attrValueOf(attr *xml.Attr) (string, bool) {
+	if attr == nil {
+		return "", false
+	}
+	return attr.Value, true
+}
+
+func attrsByKey(node *Node, options *compareOptions) map[string]*xml.Attr {
+	attrs := make(map[string]*xml.Attr, len(node.Attrs))
+	for i := range node.Attrs {
+		if !isNameSpaceAttr(&node.Attrs[i]) {
+			attrs[options.attrKey(&node.Attrs[i])] = &node.Attrs[i]
+		}
+	}
+	return attrs
+}
+
+// Keys of children - element keys numbered among siblings with the same key.
+func (merger *merger) childKeys(node *Node) []string {
+	counts := make(map[string]int)
+	keys := make([]string, len(node.Children))
+	for i := range node.Children {
+		key := merger.options.elementKey(&node.Children[i])
+		keys[i] = key + "#" + strconv.Itoa(counts[key])
+		counts[key]++
+	}
+	return keys
+}
+
+// Merges children paired by keys in the order of mine version.
+//   - segments - raw texts around children of mine version written between merged children; `nil` if not spliced
+func (merger *merger) mergeChildren(base *Node, mine *Node, theirs *Node, path string, segments []string) []Node {
+	baseIdx, theirsIdx := indexByKey(merger.childKeys(base)), indexByKey(merger.childKeys(theirs))
+	mineKeys, theirsKeys := merger.childKeys(mine), merger.childKeys(theirs)
+	mineIdx := indexByKey(mineKeys)
+
+	// Children of theirs version missing in mine version are inserted after the preceding sibling
+	insertedAfter := make(map[string][]int)
+	previous := ""
+	for j, key := range theirsKeys {
+		if _, ok := mineIdx[key]; ok {
+			previous = key
+		} else {
+			insertedAfter[previous] = append(insertedAfter[previous], j)
+		}
+	}
+
+	merged := make([]Node, 0, len(mine.Children)+len(insertedAfter))
+	childPath := func(node *Node) string {
+		return path + "/" + nodeName(node) + "[" + strconv.Itoa(len(merged)) + "]"
+	}
+	// Inserted children are indented as children of mine version
+	indent := ""
+	for _, segment := range segments[:max(len(segments)-1, 0)] {
+		if strings.TrimSpace(segment) == "" {
+			indent = segment
+			break
+		}
+	}
+	addTheirs := func(after string) {
+		for _, j := range insertedAfter[after] {
+			child := &theirs.Children[j]
+			if k, ok := baseIdx[theirsKeys[j]]; ok && merger.equal(&base.Children[k], child) {
+				continue
+			} else if ok {
+				// Deleted in mine version and modified in theirs one - the modified element is kept
+				merger.deletionConflict(childPath(child), &base.Children[k], nil, child)
+			}
+			merged = append(merged, *child.Clone())
+			if segments != nil {
+				merger.out.WriteString(indent)
+				merger.write(child)
+			}
+		}
+	}
+
+	addTheirs("")
+	for i, key := range mineKeys {
+		child := &mine.Children[i]
+		k, inBase := baseIdx[key]
+		j, inTheirs := theirsIdx[key]
+		// Unchanged in mine version and deleted in theirs one
+		deleted := inBase && !inTheirs && merger.equal(&base.Children[k], child)
+		if segments != nil && (!deleted || strings.TrimSpace(segments[i]) != "") {
+			merger.out.WriteString(segments[i])
+		}
+		switch {
+		case inBase && inTheirs:
+			merged = append(merged, *merger.mergeNodes(&base.Children[k], child, &theirs.Children[j], childPath(child)))
+		case inTheirs:
+			// Added in both versions
+			if !merger.equal(child, &theirs.Children[j]) {
+				merger.conflict(childPath(child), "", "element", "", serialized(child), serialized(&theirs.Children[j]))
+			}
+			merged = append(merged, *child.Clone())
+			merger.write(child)
+		case !deleted:
+			if inBase {
+				// Deleted in theirs version and modified in mine one
+				merger.deletionConflict(childPath(child), &base.Children[k], child, nil)
+			}
+			merged = append(merged, *child.Clone())
+			merger.write(child)
+		}
+		addTheirs(key)
+	}
+	if segments != nil {
+		merger.out.WriteString(segments[len(segments)-1])
+	}
+	return merged
+}
+
+func (merger *merger) deletionConflict(path string, base *Node, mine *Node, theirs *Node) {
+	merger.conflict(path, "", "element", serialized(base), serialized(mine), serialized(theirs))
+}
+
+func indexByKey(keys []string) map[string]int {
+	index := make(map[string]int, len(keys))
+	for i, key := range keys {
+		index[key] = i
+	}
+	return index
+}
+
+// Serialized element for conflict descriptions; empty for `nil`.
+func serialized(node *Node) string {
+	if node == nil {
+		return ""
+	}
+	text, _ := node.XML()
+	return text
+}
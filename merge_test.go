@@ -0,0 +1,151 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Merges versions and serializes the result.
+//
+// Returns: merged XML and descriptions of conflicts
+func mergeToXML(t *testing.T, base string, mine string, theirs string, opts ...Option) (string, []string) {
+	result, err := Merge(base, mine, theirs, opts...)
+	assert.NoError(t, err)
+	merged, err := result.Merged.XML()
+	assert.NoError(t, err)
+	conflicts := make([]string, 0)
+	for _, conflict := range result.Conflicts {
+		conflicts = append(conflicts, conflict.String())
+	}
+	return merged, conflicts
+}
+
+func TestMergeWithoutConflicts(t *testing.T) {
+	assertT := assert.New(t)
+
+	base := `<a x="1" y="1"><b>1</b><c>1</c><d/></a>`
+	mine := `<a x="2" y="1"><b>2</b><c>1</c><d/><e/></a>`
+	theirs := `<a x="1" z="3"><b>1</b><f/><c>3</c></a>`
+
+	merged, conflicts := mergeToXML(t, base, mine, theirs)
+	assertT.Equal(`<a x="2" z="3"><b>2</b><f></f><c>3</c><e></e></a>`, merged)
+	assertT.Empty(conflicts)
+
+	merged, conflicts = mergeToXML(t, base, base, theirs)
+	assertT.Equal(`<a x="1" z="3"><b>1</b><f></f><c>3</c></a>`, merged)
+	assertT.Empty(conflicts)
+	merged, _ = mergeToXML(t, base, mine, mine)
+	assertT.Equal(`<a x="2" y="1"><b>2</b><c>1</c><d></d><e></e></a>`, merged)
+}
+
+func TestMergeConflicts(t *testing.T) {
+	assertT := assert.New(t)
+
+	base := `<a x="1"><b>1</b><c><v>1</v></c><d>1</d></a>`
+	mine := `<a x="2"><b>2</b><d>2</d><e>1</e></a>`
+	theirs := `<a x="3"><b>3</b><c><v>2</v></c><e>2</e></a>`
+
+	merged, conflicts := mergeToXML(t, base, mine, theirs)
+	assertT.Equal(`<a x="2"><b>2</b><c><v>2</v></c><d>2</d><e>1</e></a>`, merged)
+	assertT.Equal([]string{
+		"Conflicting attribute 'x': base '1', mine '2', theirs '3', path='/a'",
+		"Conflicting text: base '1', mine '2', theirs '3', path='/a/b[0]'",
+		"Conflicting element: base '<c><v>1</v></c>', mine '', theirs '<c><v>2</v></c>', path='/a/c[1]'",
+		"Conflicting element: base '<d>1</d>', mine '<d>2</d>', theirs '', path='/a/d[2]'",
+		"Conflicting element: base '', mine '<e>1</e>', theirs '<e>2</e>', path='/a/e[3]'",
+	}, conflicts)
+
+	merged, conflicts = mergeToXML(t, `<a>1</a>`, `<b>1</b>`, `<c>2</c>`)
+	assertT.Equal(`<b>2</b>`, merged)
+	assertT.Equal([]string{"Conflicting name: base 'a', mine 'b', theirs 'c', path='/b'"}, conflicts)
+
+	// Different elements added at the same position aren't paired
+	merged, conflicts = mergeToXML(t, `<a><b/></a>`, `<a><c/></a>`, `<a><d/></a>`)
+	assertT.Equal(`<a><d></d><c></c></a>`, merged)
+	assertT.Empty(conflicts)
+}
+
+func TestMergeOptions(t *testing.T) {
+	assertT := assert.New(t)
+
+	base := `<a><item id="1">1</item><item id="2">2</item></a>`
+	mine := `<a><item id="2">2</item><item id="1">3</item></a>`
+	theirs := `<a><item id="1">1</item><item id="2">4</item></a>`
+
+	merged, conflicts := mergeToXML(t, base, mine, theirs, WithKeyAttributes("id"))
+	assertT.Equal(`<a><item id="2">4</item><item id="1">3</item></a>`, merged)
+	assertT.Empty(conflicts)
+
+	_, conflicts = mergeToXML(t, `<a ts="1">1</a>`, `<a ts="2">1</a>`, `<a ts="3">2</a>`, WithIgnoredAttributes("ts"))
+	assertT.Empty(conflicts)
+
+	_, err := Merge(`<a/>`, `<a>`, `<a/>`)
+	assertT.EqualError(err, "can't parse the mine version: XML syntax error on line 1: unexpected EOF")
+}
+
+func TestMergeText(t *testing.T) {
+	assertT := assert.New(t)
+
+	base := `<?xml version="1.0"?>
+<!-- settings -->
+<db>
+  <host>localhost</host>
+  <port>5432</port>
+  <pool/>
+  <opts a="1"/>
+</db>
+`
+	mine := `<?xml version="1.0"?>
+<!-- settings -->
+<db>
+  <!-- primary -->
+  <host>localhost</host>
+  <port>5433</port>
+  <pool/>
+  <opts a="1"/>
+</db>
+`
+	theirs := `<db><host>db.local</host><port>5432</port><user>app</user><opts a="2"/></db>`
+
+	result, err := Merge(base, mine, theirs)
+	assertT.NoError(err)
+	assertT.Empty(result.Conflicts)
+	assertT.Equal(`<?xml version="1.0"?>
+<!-- settings -->
+<db>
+  <!-- primary -->
+  <host>db.local</host>
+  <port>5433</port>
+  <user>app</user>
+  <opts a="2"/>
+</db>
+`, result.Text)
+	reparsed, err := parseXML(result.Text)
+	assertT.NoError(err)
+	assertT.True(result.Merged.Equal(reparsed))
+
+	// Start tags with changed attributes are rewritten with prefixes of the versions
+	result, _ = Merge(`<p:a xmlns:p="urn:p" x="1"><b/></p:a>`, `<p:a xmlns:p="urn:p" x="1">
+  <b/>
+  <c/>
+</p:a>`, `<p:a x="2" xmlns:p="urn:p" p:y="3"><b/></p:a>`)
+	assertT.Equal(`<p:a xmlns:p="urn:p" x="2" p:y="3">
+  <b/>
+  <c/>
+</p:a>`, result.Text)
+
+	// Texts of elements without children are taken as they are written
+	result, _ = Merge(`<a><b k="1">1</b><c/></a>`, `<a><b k="2">1</b><c/></a>`, `<a><b k="1">3 &amp; 4</b><c/></a>`)
+	assertT.Equal(`<a><b k="2">3 &amp; 4</b><c/></a>`, result.Text)
+
+	// Elements with changed mixed content or self-closing elements getting content are serialized
+	result, _ = Merge(`<a>x<b/></a>`, `<a>x<b/><c/></a>`, `<a>y<b/></a>`)
+	assertT.Equal(`<a>y<b></b><c></c></a>`, result.Text)
+	result, _ = Merge(`<r><a k="1"/></r>`, `<r><a k="2"/></r>`, `<r><a k="1"><b/></a></r>`)
+	assertT.Equal(`<r><a k="2"><b></b></a></r>`, result.Text)
+
+	// Trees that were not parsed are serialized
+	mergedNodes := MergeNodes(E("a", E("b")), E("a", E("b"), E("c")), E("a"))
+	assertT.Equal(`<a><c></c></a>`, mergedNodes.Text)
+}
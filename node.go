@@ -0,0 +1,280 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Creates a deep copy of the node with its subtree; the copy has no parent.
+// Hashes are copied as they are - hashes of modified nodes are recomputed on demand.
+func (node *Node) Clone() *Node {
+	clone := node.cloneTree()
+	clone.Parent = nil
+	clone.linkChildren()
+	return clone
+}
+
+func (node *Node) cloneTree() *Node {
+	clone := *node
+	clone.segment = ""
+	clone.Attrs = slices.Clone(node.Attrs)
+	clone.Content = slices.Clone(node.Content)
+	if node.Children != nil {
+		clone.Children = make([]Node, len(node.Children))
+		for i := range node.Children {
+			clone.Children[i] = *node.Children[i].cloneTree()
+		}
+	}
+	return &clone
+}
+
+// Sets parent pointers and path segments of the subtree.
+func (node *Node) linkChildren() {
+	for i := range node.Children {
+		node.linkChild(i)
+		node.Children[i].linkChildren()
+	}
+}
+
+// Sets the parent pointer of the child and precomputes the last segment of its path.
+func (node *Node) linkChild(i int) {
+	child := &node.Children[i]
+	child.Parent = node
+	if len(node.Children) == 1 {
+		child.segment = "/" + nodeName(child)
+	} else {
+		child.segment = "/" + nodeName(child) + "[" + strconv.Itoa(i) + "]"
+	}
+}
+
+// Appends a copy of the child to children of the node.
+//
+// Returns: the added child; pointers to children are invalidated by subsequent modifications of the node
+func (node *Node) AppendChild(child *Node) *Node {
+	return node.InsertChild(len(node.Children), child)
+}
+
+// Inserts a copy of the child into children of the node.
+//   - index - position of the child in the children list
+//
+// Returns: the added child; pointers to children are invalidated by subsequent modifications of the node
+func (node *Node) InsertChild(index int, child *Node) *Node {
+	node.Children = slices.Insert(node.Children, index, *child.cloneTree())
+	node.modified()
+	return &node.Children[index]
+}
+
+// Removes the child at the index from children of the node.
+func (node *Node) RemoveChild(index int) {
+	node.Children = slices.Delete(node.Children, index, index+1)
+	node.modified()
+}
+
+// Value of the attribute without namespace.
+//
+// Returns: the value and whether the attribute was found
+func (node *Node) GetAttr(name string) (string, bool) {
+	return node.GetAttrNS("", name)
+}
+
+// Value of the attribute with the namespace URI; namespace declarations are not attributes.
+//   - space - namespace URI, empty for attributes without namespace
+//   - name - local name of the attribute
+//
+// Returns: the value and whether the attribute was found
+func (node *Node) GetAttrNS(space string, name string) (string, bool) {
+	idx := indexOfAttr(node.Attrs, xml.Name{Space: space, Local: name})
+	if idx < 0 || isNameSpaceAttr(&node.Attrs[idx]) {
+		return "", false
+	}
+	return node.Attrs[idx].Value, true
+}
+
+// Attributes of the node without namespace declarations.
+//
+// Returns: values by names; names of attributes with namespace are in Clark notation `{uri}name`
+func (node *Node) AttrMap() map[string]string {
+	attrs := make(map[string]string, len(node.Attrs))
+	for i := range node.Attrs {
+		if attr := &node.Attrs[i]; !isNameSpaceAttr(attr) {
+			attrs[attrQName(attr)] = attr.Value
+		}
+	}
+	return attrs
+}
+
+// Sets the value of the attribute without namespace, adding the attribute if necessary.
+func (node *Node) SetAttr(name string, value string) {
+	idx := indexOfAttr(node.Attrs, xml.Name{Local: name})
+	if idx < 0 {
+		node.Attrs = append(node.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	} else {
+		node.Attrs[idx].Value = value
+	}
+	node.modified()
+}
+
+// Removes the attribute without namespace.
+//
+// Returns: whether the attribute was found
+func (node *Node) RemoveAttr(name string) bool {
+	idx := indexOfAttr(node.Attrs, xml.Name{Local: name})
+	if idx < 0 {
+		return false
+	}
+	node.Attrs = slices.Delete(node.Attrs, idx, idx+1)
+	node.modified()
+	return true
+}
+
+// Replaces own text of the node.
+func (node *Node) SetText(text string) {
+	node.CharData = text
+	node.CDATA = false
+	node.modified()
+}
+
+// Restores parent pointers and recomputes hashes after modification of the node.
+// Raw content of the node and its ancestors no longer reflects the tree and is dropped.
+func (node *Node) modified() {
+	node.linkChildren()
+
+	root := node
+	for currNode := node; currNode != nil; currNode = currNode.Parent {
+		currNode.Hash = 0
+		currNode.digest = nil
+		currNode.Content = nil
+		currNode.source = ""
+		root = currNode
+	}
+	root.hashCode()
+}
+
+// Finds the element by path in the format reported in diffs, e.g. `/catalog/item[2]/price`.
+// Indices are positions among all siblings; a segment without index selects the first child with the name.
+// The path is resolved from the document root regardless of the node it is called on.
+//
+// Returns: the element or `nil` if the path doesn't resolve
+func (node *Node) FindByPath(path string) *Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+
+	pattern := compilePathPattern(path)
+	if len(pattern) == 0 || !pattern[0].matchesNode(node, -1) {
+		return nil
+	}
+
+	for _, step := range pattern[1:] {
+		var next *Node
+		for i := range node.Children {
+			if step.matchesNode(&node.Children[i], i) {
+				next = &node.Children[i]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// Checks whether the element at the index among siblings matches the step; -1 for the root.
+func (step pathStep) matchesNode(node *Node, index int) bool {
+	if step.name != "*" && step.name != nodeName(node) {
+		return false
+	}
+	return step.index == "" || step.index == strconv.Itoa(max(index, 0))
+}
+
+// Checks whether the subtrees are equal using the same rules as `Compare`; stops on the first difference
+// and doesn't collect differences.
+//   - other - compared subtree
+//   - opts - comparison options
+func (node *Node) Equal(other *Node, opts ...Option) bool {
+	options := newCompareOptions(opts)
+	if node.XMLName == other.XMLName && !options.deepComparison() && identicalSubtrees(node, other) {
+		return true
+	}
+	options.maxDiffs = 1
+
+	recorder := createDiffRecorderEx(options)
+	recorder.equalityOnly = true
+	recorder.selectIgnoredNodes(node)
+	recorder.selectIgnoredNodes(other)
+	compareRoots(node, other, recorder)
+	return recorder.recorded == 0
+}
+
+// Following sibling element or `nil` for the last child and the root.
+func (node *Node) NextSibling() *Node {
+	idx := node.siblingIndex()
+	if idx < 0 || idx+1 >= len(node.Parent.Children) {
+		return nil
+	}
+	return &node.Parent.Children[idx+1]
+}
+
+// Preceding sibling element or `nil` for the first child and the root.
+func (node *Node) PrevSibling() *Node {
+	idx := node.siblingIndex()
+	if idx <= 0 {
+		return nil
+	}
+	return &node.Parent.Children[idx-1]
+}
+
+// The first child element with the local name or `nil` if there is none.
+func (node *Node) FirstChildNamed(name string) *Node {
+	for i := range node.Children {
+		if nodeName(&node.Children[i]) == name {
+			return &node.Children[i]
+		}
+	}
+	return nil
+}
+
+// Child elements with the local name.
+func (node *Node) ChildrenNamed(name string) []*Node {
+	ret := make([]*Node, 0)
+	for i := range node.Children {
+		if nodeName(&node.Children[i]) == name {
+			ret = append(ret, &node.Children[i])
+		}
+	}
+	return ret
+}
+
+// Index of the node among children of its parent; -1 for the root.
+func (node *Node) siblingIndex() int {
+	if node.Parent == nil {
+		return -1
+	}
+	for i := range node.Parent.Children {
+		if &node.Parent.Children[i] == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// Text of the node and its descendants in document order with whitespace normalized -
+// leading and trailing whitespace removed and inner runs of whitespace replaced by single spaces.
+func (node *Node) Text() string {
+	var buf strings.Builder
+	node.appendText(&buf)
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func (node *Node) appendText(buf *strings.Builder) {
+	segments := node.ownTextSegments()
+	for i := range node.Children {
+		buf.WriteString(segments[i])
+		node.Children[i].appendText(buf)
+	}
+	buf.WriteString(segments[len(node.Children)])
+}
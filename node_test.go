@@ -0,0 +1,204 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(xmlString1)
+	clone := root.Children[0].Clone()
+
+	assertT.Nil(clone.Parent)
+	assertT.Equal(root.Children[0].Hash, clone.Hash)
+	assertT.Equal("to", nodeName(clone))
+
+	clone = root.Clone()
+	assertT.Nil(clone.Parent)
+	assertT.Equal(root.Hash, clone.Hash)
+	assertT.Equal(len(root.Children), len(clone.Children))
+	for i := range clone.Children {
+		assertT.Same(clone, clone.Children[i].Parent)
+	}
+	assertT.Equal(0, len(Compare(xmlString1, mustXML(t, clone)).GetDiffs()))
+
+	// Copies are independent
+	clone.Attrs[0].Value = "blue"
+	clone.Children[0].CharData = "Bob"
+	clone.Content[0] = 'X'
+	assertT.Equal("red", root.Attrs[0].Value)
+	assertT.Equal("Tove", root.Children[0].CharData)
+	assertT.NotEqual(byte('X'), root.Content[0])
+	assertT.Nil((&Node{XMLName: root.XMLName}).Clone().Children)
+}
+
+func mustXML(t *testing.T, node *Node) string {
+	text, err := node.XML()
+	assert.Nil(t, err)
+	return text
+}
+
+func TestNodeMutation(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b x="1"><c/></b><d/></a>`)
+	other, _ := parseXML(`<e>5</e>`)
+
+	added := root.AppendChild(other)
+	assertT.Same(root, added.Parent)
+	assertT.Same(&root.Children[2], added)
+	// Grandchildren follow reallocated children
+	assertT.Same(&root.Children[0], root.Children[0].Children[0].Parent)
+
+	root.InsertChild(0, &Node{XMLName: xml.Name{Local: "z"}})
+	root.RemoveChild(2)
+	root.Children[0].SetText("t")
+	root.Children[1].SetAttr("x", "2")
+	root.Children[1].SetAttr("y", "3")
+	assertT.True(root.Children[1].RemoveAttr("x"))
+	assertT.False(root.Children[1].RemoveAttr("x"))
+	root.Children[1].Children[0].SetText("c")
+
+	assertT.Equal(`<a><z>t</z><b y="3"><c>c</c></b><e>5</e></a>`, mustXML(t, root))
+	assertT.Same(&root.Children[1], root.Children[1].Children[0].Parent)
+	assertT.Equal("", root.rawXML())
+
+	expected, _ := parseXML(`<a><z>t</z><b y="3"><c>c</c></b><e>5</e></a>`)
+	assertT.Equal(expected.Hash, root.Hash)
+	assertT.Equal(expected.Children[1].Hash, root.Children[1].Hash)
+
+	// Inserted copies are independent
+	added = root.AppendChild(other)
+	added.SetText("6")
+	assertT.Equal("5", other.CharData)
+	assertT.Panics(func() { root.RemoveChild(10) })
+}
+
+func TestFindByPath(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<catalog><item><price>1</price></item><note/><item><price>2</price><price>3</price></item></catalog>`)
+
+	assertT.Same(root, root.FindByPath("/catalog"))
+	assertT.Same(&root.Children[2].Children[0], root.FindByPath("/catalog/item[2]/price[0]"))
+	assertT.Same(&root.Children[0].Children[0], root.FindByPath("/catalog/item[0]/price"))
+	assertT.Same(&root.Children[2].Children[1], root.Children[0].FindByPath("/catalog/item[2]/price[1]"))
+	assertT.Same(&root.Children[0], root.FindByPath("/catalog/item"))
+	assertT.Same(&root.Children[1], root.FindByPath("/catalog/*[1]"))
+	assertT.Nil(root.FindByPath("/catalog/item[1]"))
+	assertT.Nil(root.FindByPath("/catalog/item[5]"))
+	assertT.Nil(root.FindByPath("/other"))
+	assertT.Nil(root.FindByPath(""))
+
+	// Paths of diffs resolve to their nodes
+	sample := `<a><b><c>1</c></b><b><c>2</c><d/></b></a>`
+	for _, diff := range Compare(sample, `<a><b><c>1</c></b><b><c>3</c></b></a>`).GetStructuredDiffs() {
+		root1, _ := parseXML(sample)
+		assertT.Equal(diff.Node1.path(), root1.FindByPath(diff.Path1).path())
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	assertT := assert.New(t)
+
+	root1, _ := parseXML(`<a><b x="1">1.0</b><c/></a>`)
+	root2, _ := parseXML(`<a><c/><b x="1">1.01</b></a>`)
+
+	assertT.True(root1.Equal(root1.Clone()))
+	assertT.False(root1.Equal(root2))
+	assertT.False(root1.Equal(root2, WithIgnoreOrder()))
+	assertT.True(root1.Equal(root2, WithIgnoreOrder(), WithNumericTolerance(0.1)))
+	assertT.True(root1.Children[1].Equal(&root2.Children[0]))
+	assertT.False(root1.Children[0].Equal(&root2.Children[0]))
+	assertT.True(root1.Equal(root2, WithIgnoredPaths("/a/b", "/a/c")))
+	assertT.True(root1.Equal(root2, WithIgnoreOrder(), WithIgnoredDiscrepancies("^Node texts differ")))
+
+	// Features not reflected by hashes
+	root3, _ := parseXML(`<a><b x="1">1.0</b><c></c></a>`)
+	assertT.True(root1.Equal(root3))
+	assertT.False(root1.Equal(root3, WithStrictSelfClosing()))
+}
+
+func TestNodeEqualAgreesWithCompare(t *testing.T) {
+	assertT := assert.New(t)
+
+	tests := []struct {
+		sample1 string
+		sample2 string
+		opts    []Option
+	}{
+		{`<a><b>1.0</b><c/></a>`, `<a><b>1.00</b><c/></a>`, nil},
+		{`<a><b t="x">1</b></a>`, `<a><b t="y">1</b></a>`, []Option{WithIgnoredAttributes("t")}},
+		{`<a><b t="x">1</b></a>`, `<a><b t="y">1</b></a>`, nil},
+	}
+	for _, test := range tests {
+		root1, _ := parseXML(test.sample1)
+		root2, _ := parseXML(test.sample2)
+		assertT.Equal(len(CompareNodes(root1, root2, test.opts...).GetStructuredDiffs()) == 0, root1.Equal(root2, test.opts...),
+			test.sample1+" vs "+test.sample2)
+	}
+}
+
+func TestSiblingNavigation(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b>1</b><c/><b>2</b></a>`)
+	first, second, third := &root.Children[0], &root.Children[1], &root.Children[2]
+
+	assertT.Same(second, first.NextSibling())
+	assertT.Same(third, second.NextSibling())
+	assertT.Nil(third.NextSibling())
+	assertT.Same(second, third.PrevSibling())
+	assertT.Nil(first.PrevSibling())
+	assertT.Nil(root.NextSibling())
+	assertT.Nil(root.PrevSibling())
+
+	assertT.Same(first, root.FirstChildNamed("b"))
+	assertT.Nil(root.FirstChildNamed("d"))
+	assertT.Equal([]*Node{first, third}, root.ChildrenNamed("b"))
+	assertT.Equal([]*Node{}, root.ChildrenNamed("d"))
+
+	// Detached copy isn't a sibling
+	copied := *second
+	assertT.Nil(copied.NextSibling())
+}
+
+func TestNodeText(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML("<p>Hello, <b>dear\n  <i>old</i></b> world<br/>!<![CDATA[ <ok> ]]></p>")
+	assertT.Equal("Hello, dear old world! <ok>", root.Text())
+	assertT.Equal("dear old", root.Children[0].Text())
+	assertT.Equal("", root.Children[1].Text())
+
+	// Order of own text around children isn't known after modification - it goes first
+	root.Children[1].SetText(" and ")
+	assertT.Equal("Hello, world! <ok> dear old and", root.Text())
+}
+
+func TestAttrLookup(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a xmlns="urn:d" xmlns:p="urn:p" k="1" p:k="2" xsi:nil="false"/>`)
+
+	value, ok := root.GetAttr("k")
+	assertT.True(ok)
+	assertT.Equal("1", value)
+	value, ok = root.GetAttrNS("urn:p", "k")
+	assertT.True(ok)
+	assertT.Equal("2", value)
+	_, ok = root.GetAttr("m")
+	assertT.False(ok)
+	_, ok = root.GetAttrNS("urn:d", "k")
+	assertT.False(ok)
+	_, ok = root.GetAttr("xmlns")
+	assertT.False(ok)
+	_, ok = root.GetAttrNS("xmlns", "p")
+	assertT.False(ok)
+
+	assertT.Equal(map[string]string{"k": "1", "{urn:p}k": "2", "{xsi}nil": "false"}, root.AttrMap())
+}
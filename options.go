@@ -0,0 +1,698 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"math"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Functional option that tunes comparison behavior.
+type Option func(*compareOptions)
+
+// Relative tolerance for numeric values of selected elements.
+type relativeTolerance struct {
+	ratio float64
+	paths []pathPattern
+}
+
+// Attribute name patterns ignored for selected elements.
+type ignoredAttributes struct {
+	names []*regexp.Regexp
+	paths []pathPattern
+}
+
+// Custom comparison of text or attribute values.
+//   - path - path of the node in the first sample; attribute names are appended as `/@name`
+//   - expected, actual - values from the first and second samples
+//
+// Returns: whether values are equal and whether the comparator made a decision;
+// if not handled, values are compared by the library.
+type ValueComparator func(path string, expected string, actual string) (equal bool, handled bool)
+
+// Whitespace normalization applied to texts before comparison.
+type WhitespaceMode int
+
+const (
+	// Leading and trailing whitespace is removed (default)
+	WhitespaceTrim WhitespaceMode = iota
+	// Texts are compared as is
+	WhitespaceExact
+	// Texts are trimmed and internal runs of whitespace are replaced with a single space
+	WhitespaceCollapse
+)
+
+// Trade-off between quality of matching children and speed of comparison.
+type Strategy int
+
+const (
+	// Ordered children are aligned by the longest common subsequence, elements with the same name are paired
+	// by maximal similarity in groups up to 256 elements (default)
+	StrategyBalanced Strategy = iota
+	// Children are aligned greedily by hashes in one pass and elements with the same name are paired in order
+	StrategyFast
+	// As `StrategyBalanced`, but elements with the same name are paired by maximal similarity in groups of any size,
+	// including removed and added elements of ordered children
+	StrategyPrecise
+)
+
+// Severity assigned to differences of selected kinds under selected elements.
+type severityRule struct {
+	severity Severity
+	path     pathPattern
+	kinds    []DiffKind
+}
+
+// Comparison settings accumulated from options.
+type compareOptions struct {
+	stopOnFirst              bool
+	ignoreOrder              bool
+	numericTolerance         float64
+	relativeTolerances       []relativeTolerance
+	ignoredAttributes        []ignoredAttributes
+	ignoredDiscrepancies     []string
+	ignoredPaths             []pathPattern
+	unorderedPaths           []pathPattern
+	ignoredXPaths            []*xpathPath
+	regexPlaceholders        bool
+	placeholders             *sync.Map
+	valueComparators         []ValueComparator
+	compareTimestamps        bool
+	timestampTolerance       time.Duration
+	ignoreTimezone           bool
+	strictNamespaces         bool
+	emptyAttributesAsMissing bool
+	normalizeBooleans        bool
+	yesNoBooleans            bool
+	maxDiffs                 int
+	maxDepth                 int
+	compareComments          bool
+	compareProcInsts         bool
+	strictCDATA              bool
+	strictAttributeOrder     bool
+	whitespace               WhitespaceMode
+	strictSelfClosing        bool
+	caseInsensitiveNames     bool
+	caseInsensitiveAttrs     bool
+	caseInsensitiveValues    bool
+	nilAsAbsent              bool
+	severityRules            []severityRule
+	detectMoves              bool
+	contextLines             int
+	streamingDepth           int
+	parallelism              int
+	strategy                 Strategy
+	maxNodes                 int64
+	maxDocumentSize          int64
+	maxEntityExpansion       int64
+	valueTruncation          int
+	keyAttributes            []string
+	namespaceInsensitive     []pathPattern
+	soap                     bool
+}
+
+// Creates comparison settings with defaults, then applies options in order.
+func newCompareOptions(opts []Option) *compareOptions {
+	options := &compareOptions{
+		ignoredDiscrepancies: make([]string, 0),
+		ignoredPaths:         make([]pathPattern, 0),
+		unorderedPaths:       make([]pathPattern, 0),
+		ignoredXPaths:        make([]*xpathPath, 0),
+		placeholders:         &sync.Map{},
+		streamingDepth:       1,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// Stops comparison on the first detected difference.
+func WithStopOnFirst() Option {
+	return func(options *compareOptions) {
+		options.stopOnFirst = true
+		options.maxDiffs = 1
+	}
+}
+
+// Stops comparison as soon as the specified number of differences is detected.
+//   - count - maximal number of reported differences; unlimited if not positive
+func WithMaxDiffs(count int) Option {
+	return func(options *compareOptions) {
+		options.maxDiffs = max(count, 0)
+	}
+}
+
+// Compares the tree only down to the specified depth, content of deeper elements is compared as opaque text.
+//   - depth - maximal depth of compared elements, the root element has depth 1; unlimited if not positive
+func WithMaxDepth(depth int) Option {
+	return func(options *compareOptions) {
+		options.maxDepth = max(depth, 0)
+	}
+}
+
+// Matches sibling elements regardless of their order in the document.
+func WithIgnoreOrder() Option {
+	return func(options *compareOptions) {
+		options.ignoreOrder = true
+	}
+}
+
+// Excludes elements selected by XPath expressions (with their subtrees) from comparison.
+// Expressions are evaluated against both samples. Panics if an expression is invalid.
+//   - exprs - expressions like `//metadata` or `//*[@transient='true']`
+func WithIgnoredXPath(exprs ...string) Option {
+	return func(options *compareOptions) {
+		for _, expr := range exprs {
+			options.ignoredXPaths = append(options.ignoredXPaths, mustCompileXPath(expr))
+		}
+	}
+}
+
+// Matches children of the specified elements regardless of their order, the rest of the document stays order-sensitive.
+//   - paths - paths of parent elements in the same format as for `WithIgnoredPaths`
+func WithUnorderedPaths(paths ...string) Option {
+	return func(options *compareOptions) {
+		for _, path := range paths {
+			options.unorderedPaths = append(options.unorderedPaths, compilePathPattern(path))
+		}
+	}
+}
+
+// Treats numeric values in texts and attributes as equal when they differ by no more than `eps`.
+func WithNumericTolerance(eps float64) Option {
+	return func(options *compareOptions) {
+		options.numericTolerance = math.Abs(eps)
+	}
+}
+
+// Treats numeric values as equal when they differ by no more than `ratio` of the larger magnitude, e.g. 0.001 for 0.1%.
+//   - ratio - relative tolerance
+//   - paths - optional paths of elements the tolerance applies to (their texts and attributes); all elements if empty
+func WithRelativeTolerance(ratio float64, paths ...string) Option {
+	return func(options *compareOptions) {
+		rule := relativeTolerance{ratio: math.Abs(ratio), paths: make([]pathPattern, 0, len(paths))}
+		for _, path := range paths {
+			rule.paths = append(rule.paths, compilePathPattern(path))
+		}
+		options.relativeTolerances = append(options.relativeTolerances, rule)
+	}
+}
+
+// Identifies sibling elements by values of key attributes - elements with the same name and different keys
+// are reported as removed and added rather than changed. An element is identified by the first of attributes it has.
+//   - names - local names of key attributes, e.g. `id`
+func WithKeyAttributes(names ...string) Option {
+	return func(options *compareOptions) {
+		options.keyAttributes = append(options.keyAttributes, names...)
+	}
+}
+
+// Excludes attributes from comparison.
+//   - names - attribute names; wildcards `*` and `?` are allowed, e.g. `session-*`
+func WithIgnoredAttributes(names ...string) Option {
+	return WithIgnoredAttributesAt("", names...)
+}
+
+// Excludes attributes of the specified elements from comparison.
+//   - path - path of elements in the same format as for `WithIgnoredPaths`; all elements if empty
+//   - names - attribute names; wildcards `*` and `?` are allowed, e.g. `session-*`
+func WithIgnoredAttributesAt(path string, names ...string) Option {
+	return func(options *compareOptions) {
+		rule := ignoredAttributes{names: make([]*regexp.Regexp, 0, len(names)), paths: make([]pathPattern, 0, 1)}
+		for _, name := range names {
+			rule.names = append(rule.names, compileWildcard(name))
+		}
+		if path != "" {
+			rule.paths = append(rule.paths, compilePathPattern(path))
+		}
+		options.ignoredAttributes = append(options.ignoredAttributes, rule)
+	}
+}
+
+// Allows texts and attribute values of the first (expected) sample to contain regex placeholders
+// like `${regex:[0-9a-f-]{36}}` that match any conforming value of the second sample.
+func WithRegexPlaceholders() Option {
+	return func(options *compareOptions) {
+		options.regexPlaceholders = true
+	}
+}
+
+// Adds custom comparison of text and attribute values. Comparators are tried in order of addition
+// before the library's own comparison. Identical values are always equal and are not passed to comparators.
+func WithValueComparator(comparator ValueComparator) Option {
+	return func(options *compareOptions) {
+		options.valueComparators = append(options.valueComparators, comparator)
+	}
+}
+
+// Compares ISO-8601 / RFC 3339 timestamps in texts and attributes as instants, so that
+// `2024-01-01T00:00:00Z` equals `2024-01-01T01:00:00+01:00`.
+//   - tolerance - maximal allowed skew between timestamps
+func WithTimestampTolerance(tolerance time.Duration) Option {
+	return func(options *compareOptions) {
+		options.compareTimestamps = true
+		options.timestampTolerance = tolerance.Abs()
+	}
+}
+
+// Compares timestamps by their wall-clock time ignoring time zones (implies timestamps comparison).
+func WithTimezoneInsensitiveTimestamps() Option {
+	return func(options *compareOptions) {
+		options.compareTimestamps = true
+		options.ignoreTimezone = true
+	}
+}
+
+// Treats boolean values `true`/`1` and `false`/`0` in texts and attributes as equal.
+//   - yesNo - additionally treat `yes` and `no` (case-insensitive) as booleans
+func WithBooleanNormalization(yesNo bool) Option {
+	return func(options *compareOptions) {
+		options.normalizeBooleans = true
+		options.yesNoBooleans = yesNo
+	}
+}
+
+// Compares comments inside elements, they are ignored by default.
+func WithComments() Option {
+	return func(options *compareOptions) {
+		options.compareComments = true
+	}
+}
+
+// Compares processing instructions inside elements, they are ignored by default.
+func WithProcessingInstructions() Option {
+	return func(options *compareOptions) {
+		options.compareProcInsts = true
+	}
+}
+
+// Sets whitespace normalization of texts, e.g. `WhitespaceCollapse` makes pretty-printed and minified texts comparable.
+//   - mode - one of `WhitespaceTrim` (default), `WhitespaceExact` or `WhitespaceCollapse`
+func WithWhitespace(mode WhitespaceMode) Option {
+	return func(options *compareOptions) {
+		options.whitespace = mode
+	}
+}
+
+// Treats empty elements marked with `xsi:nil="true"` as absent optional elements.
+// Such element still differs from an empty element like `<e></e>`.
+func WithNilAsAbsent() Option {
+	return func(options *compareOptions) {
+		options.nilAsAbsent = true
+	}
+}
+
+// Compares element names ignoring case.
+func WithCaseInsensitiveNames() Option {
+	return func(options *compareOptions) {
+		options.caseInsensitiveNames = true
+	}
+}
+
+// Compares attribute names ignoring case.
+func WithCaseInsensitiveAttributeNames() Option {
+	return func(options *compareOptions) {
+		options.caseInsensitiveAttrs = true
+	}
+}
+
+// Compares texts and attribute values ignoring case.
+func WithCaseInsensitiveValues() Option {
+	return func(options *compareOptions) {
+		options.caseInsensitiveValues = true
+	}
+}
+
+// Includes lines of original samples around nodes into structured differences, see `Diff.Context1` and `Diff.Context2`.
+//   - lines - count of lines before and after the line of the node
+func WithContextLines(lines int) Option {
+	return func(options *compareOptions) {
+		options.contextLines = lines
+	}
+}
+
+// Sets how deep `CompareXmlStreams` reads elements token by token; deeper subtrees are buffered one at a time.
+//   - depth - depth of streamed elements, 1 (default) streams only the root element
+func WithStreamingDepth(depth int) Option {
+	return func(options *compareOptions) {
+		options.streamingDepth = max(depth, 1)
+	}
+}
+
+// Compares matched subtrees concurrently - children of the topmost element with several modified children
+// are distributed over a pool of workers. Results are the same as of sequential comparison.
+//   - workers - size of the pool; `runtime.GOMAXPROCS(0)` if not positive
+func WithParallelism(workers int) Option {
+	return func(options *compareOptions) {
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		options.parallelism = workers
+	}
+}
+
+// Selects how children are matched, trading quality of differences for speed on huge documents.
+//   - strategy - one of `StrategyBalanced` (default), `StrategyFast` or `StrategyPrecise`
+func WithStrategy(strategy Strategy) Option {
+	return func(options *compareOptions) {
+		options.strategy = strategy
+	}
+}
+
+// Aborts parsing and comparison of a document with more elements than the limit.
+// The comparison reports a parser error and `DiffRecorder.Err()` returns `*LimitError`.
+//   - count - maximal count of elements in each document; not positive for no limit
+func WithMaxNodes(count int64) Option {
+	return func(options *compareOptions) {
+		options.maxNodes = count
+	}
+}
+
+// Aborts parsing of a document larger than the limit, see `WithMaxNodes(...)`.
+//   - bytes - maximal size of each document; not positive for no limit
+func WithMaxDocumentSize(bytes int64) Option {
+	return func(options *compareOptions) {
+		options.maxDocumentSize = bytes
+	}
+}
+
+// Expands internal entities declared in the document type declaration, e.g. `<!ENTITY company "ACME">`.
+// By default entities are not expanded and references to them fail parsing; external entities are never resolved.
+// Replacement texts are treated as text, not markup.
+//   - bytes - maximal length of an expanded entity and of expansions of all references in a document together;
+//     a longer expansion ("billion laughs" or many references to a large entity) fails with `*LimitError`
+func WithInternalEntities(bytes int64) Option {
+	return func(options *compareOptions) {
+		options.maxEntityExpansion = bytes
+	}
+}
+
+// Shortens long texts and attribute values in messages of differences to a prefix followed by the length and
+// the hash of the whole value, e.g. for base64 blobs. `Diff.Expected` and `Diff.Actual` keep full values.
+//   - maxLen - length of the kept prefix in bytes; values are not shortened if not positive
+func WithValueTruncation(maxLen int) Option {
+	return func(options *compareOptions) {
+		options.valueTruncation = maxLen
+	}
+}
+
+// Limits of each compared document.
+func (options *compareOptions) documentLimits() documentLimits {
+	return documentLimits{maxNodes: options.maxNodes, maxSize: options.maxDocumentSize,
+		maxEntityExpansion: options.maxEntityExpansion}
+}
+
+// Reports an identical subtree removed from one location and added in another as a single `ElementMoved` difference.
+func WithMoveDetection() Option {
+	return func(options *compareOptions) {
+		options.detectMoves = true
+	}
+}
+
+// Reports empty elements serialized differently, i.e. `<x/>` vs `<x></x>`.
+func WithStrictSelfClosing() Option {
+	return func(options *compareOptions) {
+		options.strictSelfClosing = true
+	}
+}
+
+// Reports different order of otherwise equal attributes, e.g. for signed documents.
+func WithStrictAttributeOrder() Option {
+	return func(options *compareOptions) {
+		options.strictAttributeOrder = true
+	}
+}
+
+// Reports texts that are equal but only one of them comes from a CDATA section,
+// e.g. `<x><![CDATA[a<b]]></x>` vs `<x>a&lt;b</x>`.
+func WithStrictCDATA() Option {
+	return func(options *compareOptions) {
+		options.strictCDATA = true
+	}
+}
+
+// Reports different namespace URIs of same-named elements, including the case when only one of them has a namespace.
+func WithStrictNamespaces() Option {
+	return func(options *compareOptions) {
+		options.strictNamespaces = true
+	}
+}
+
+// Ignores namespaces of elements in subtrees of the specified elements, e.g. versions of a payload schema.
+//   - paths - paths of subtree roots in the same format as for `WithIgnoredPaths`
+func WithNamespaceInsensitivePaths(paths ...string) Option {
+	return func(options *compareOptions) {
+		for _, path := range paths {
+			options.namespaceInsensitive = append(options.namespaceInsensitive, compilePathPattern(path))
+		}
+	}
+}
+
+// Treats attributes with empty values as missing, so `<e a=""/>` equals `<e/>`.
+func WithEmptyAttributesAsMissing() Option {
+	return func(options *compareOptions) {
+		options.emptyAttributesAsMissing = true
+	}
+}
+
+// Assigns severity to differences in the subtree of elements; the last matching rule wins.
+// Differences not matching any rule have `SeverityError`.
+//   - severity - assigned severity
+//   - path - path of subtree roots in the same format as for `WithIgnoredPaths`; all elements if empty
+//   - kinds - kinds of differences; all kinds if empty
+func WithSeverity(severity Severity, path string, kinds ...DiffKind) Option {
+	return func(options *compareOptions) {
+		rule := severityRule{severity: severity, kinds: kinds}
+		if path != "" {
+			rule.path = compilePathPattern(path)
+		}
+		options.severityRules = append(options.severityRules, rule)
+	}
+}
+
+// Filters out discrepancies which messages match any of the regular expressions.
+//   - regexes - regular expressions for ignored discrepancies
+func WithIgnoredDiscrepancies(regexes ...string) Option {
+	return func(options *compareOptions) {
+		options.ignoredDiscrepancies = append(options.ignoredDiscrepancies, regexes...)
+	}
+}
+
+// Excludes nodes (with their subtrees) from comparison.
+//   - paths - paths in the format reported in diffs like `/note/to` or `/items/item[2]`;
+//     element without index matches any sibling with this name, `*` matches any element name.
+func WithIgnoredPaths(paths ...string) Option {
+	return func(options *compareOptions) {
+		for _, path := range paths {
+			options.ignoredPaths = append(options.ignoredPaths, compilePathPattern(path))
+		}
+	}
+}
+
+func (options *compareOptions) isIgnoredPath(node *Node) bool {
+	return matchesAnyPath(options.ignoredPaths, node)
+}
+
+func (options *compareOptions) isIgnoredAttribute(node *Node, attr *xml.Attr) bool {
+	for _, rule := range options.ignoredAttributes {
+		for _, name := range rule.names {
+			if name.MatchString(attrName(attr)) && (len(rule.paths) == 0 || matchesAnyPath(rule.paths, node)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks whether the node or any of its ancestors matches `WithNamespaceInsensitivePaths` paths
+// or, unless `WithStrictNamespaces` is set, is the body of a SOAP envelope compared with `WithSOAP`.
+func (options *compareOptions) isNamespaceInsensitive(node *Node) bool {
+	soapBody := options.soap && !options.strictNamespaces
+	for ; node != nil && (soapBody || len(options.namespaceInsensitive) != 0); node = node.Parent {
+		if matchesAnyPath(options.namespaceInsensitive, node) || (soapBody && isSOAPBody(node)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks whether the node content is compared as text rather than element by element.
+func (options *compareOptions) isOpaque(node *Node) bool {
+	return options.maxDepth > 0 && node.depth() >= options.maxDepth
+}
+
+// Checks whether equal subtree hashes are not enough to consider subtrees equal.
+func (options *compareOptions) deepComparison() bool {
+	return options.strictNamespaces || options.compareComments || options.compareProcInsts || options.strictCDATA ||
+		options.whitespace == WhitespaceExact || options.strictSelfClosing
+}
+
+// Finds severity of the difference according to severity rules.
+func (options *compareOptions) severityOf(diff *Diff) Severity {
+	node := diff.Node1
+	if node == nil {
+		node = diff.Node2
+	}
+
+	severity := SeverityError
+	for _, rule := range options.severityRules {
+		if (len(rule.kinds) == 0 || slices.Contains(rule.kinds, diff.Kind)) && rule.matchesSubtree(node) {
+			severity = rule.severity
+		}
+	}
+	return severity
+}
+
+func (rule *severityRule) matchesSubtree(node *Node) bool {
+	if rule.path == nil {
+		return true
+	}
+	for ; node != nil; node = node.Parent {
+		if rule.path.matches(node.path()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key of matching elements with the same name.
+func (options *compareOptions) elementKey(node *Node) string {
+	key := nodeName(node)
+	if options.caseInsensitiveNames {
+		key = strings.ToLower(key)
+	}
+	for _, name := range options.keyAttributes {
+		for i := range node.Attrs {
+			if attrName(&node.Attrs[i]) == name && !isNameSpaceAttr(&node.Attrs[i]) {
+				return key + "[@" + name + "='" + node.Attrs[i].Value + "']"
+			}
+		}
+	}
+	return key
+}
+
+// Key of matching attributes with the same name.
+func (options *compareOptions) attrKey(attr *xml.Attr) string {
+	if options.caseInsensitiveAttrs {
+		return strings.ToLower(attrQName(attr))
+	}
+	return attrQName(attr)
+}
+
+// Source snippet of the node if enabled.
+func (options *compareOptions) sourceContext(node *Node) string {
+	if options.contextLines <= 0 {
+		return ""
+	}
+	return node.sourceSnippet(options.contextLines)
+}
+
+// Normalizes whitespace of the text according to the whitespace mode.
+func (options *compareOptions) normalizeSpace(text string) string {
+	switch options.whitespace {
+	case WhitespaceExact:
+		return text
+	case WhitespaceCollapse:
+		return strings.Join(strings.Fields(text), " ")
+	default:
+		return strings.TrimSpace(text)
+	}
+}
+
+func (options *compareOptions) isUnordered(node *Node) bool {
+	return options.ignoreOrder || matchesAnyPath(options.unorderedPaths, node)
+}
+
+//------- path patterns -------
+
+var pathSegmentPattern = regexp.MustCompile(`^([^\[\]]+)(?:\[(\d+)\])?$`)
+
+// Single step of path pattern - element name with optional sibling index.
+type pathStep struct {
+	name  string
+	index string
+}
+
+// Path in the format reported in diffs.
+type pathPattern []pathStep
+
+func compilePathPattern(path string) pathPattern {
+	pattern := make(pathPattern, 0)
+	for _, segment := range splitPath(path) {
+		matches := pathSegmentPattern.FindStringSubmatch(segment)
+		if matches == nil {
+			pattern = append(pattern, pathStep{name: segment})
+		} else {
+			pattern = append(pattern, pathStep{name: matches[1], index: matches[2]})
+		}
+	}
+	return pattern
+}
+
+func (pattern pathPattern) matches(path string) bool {
+	segments := splitPath(path)
+	if len(segments) != len(pattern) {
+		return false
+	}
+
+	for i, segment := range segments {
+		name, index := segment, ""
+		if matches := pathSegmentPattern.FindStringSubmatch(segment); matches != nil {
+			name, index = matches[1], matches[2]
+		}
+		step := pattern[i]
+		if step.name != "*" && step.name != name {
+			return false
+		}
+		if step.index != "" && step.index != index && !(step.index == "0" && index == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks whether the path starts with the pattern, i.e. belongs to the subtree of elements matching the pattern.
+func (pattern pathPattern) matchesPrefix(path string) bool {
+	segments := splitPath(path)
+	if len(segments) < len(pattern) {
+		return false
+	}
+	return pattern.matches("/" + strings.Join(segments[:len(pattern)], "/"))
+}
+
+func matchesAnyPath(patterns []pathPattern, node *Node) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	path := node.path()
+	for _, pattern := range patterns {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts a name with wildcards `*` and `?` to a regular expression.
+func compileWildcard(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+func splitPath(path string) []string {
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
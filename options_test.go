@@ -0,0 +1,328 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareWithoutOptions(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal(CompareXmlStrings(xmlString1, xmlMixed, false), Compare(xmlString1, xmlMixed).GetMessages())
+}
+
+func TestCompareStopOnFirst(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal([]string{"Node texts differ: '' vs 'Some text ...\n    \n\tmixed with elements', path='/note'"},
+		Compare(xmlString1, xmlMixed, WithStopOnFirst()).GetMessages())
+}
+
+func TestCompareIgnoredDiscrepancies(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := Compare(xmlString1, xmlMixed, WithIgnoredDiscrepancies(`path='/note/to\[0\]'`), WithIgnoredDiscrepancies(`path='/note'`)).GetMessages()
+	assertT.Equal([]string{"Node texts differ: 'Jani' vs 'Tove', path='/note/from[1]'"}, diffs)
+}
+
+func TestCompareIgnoredPaths(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b>1</b><c>2</c><d><e>3</e></d></a>`
+	xmlSample2 := `<a><b>1</b><c>4</c><d><e>5</e></d><f/></a>`
+
+	assertT.Equal([]string{"Children differ: counts 3 vs 4: f[3]:-1, path='/a'",
+		"Node texts differ: '2' vs '4', path='/a/c[1]'", "Node texts differ: '3' vs '5', path='/a/d[2]/e'"},
+		Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithIgnoredPaths("/a/c", "/a/d", "/a/f")).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '3' vs '5', path='/a/d[2]/e'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoredPaths("/a/*[1]", "/a/f")).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithIgnoredPaths("/a")).GetMessages())
+}
+
+func TestPathPatterns(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.True(compilePathPattern("/a/b").matches("/a/b[2]"))
+	assertT.True(compilePathPattern("/a/b[2]").matches("/a/b[2]"))
+	assertT.True(compilePathPattern("/a/b[0]").matches("/a/b"))
+	assertT.True(compilePathPattern("/a/*/c").matches("/a/b[1]/c"))
+	assertT.False(compilePathPattern("/a/b[1]").matches("/a/b[2]"))
+	assertT.False(compilePathPattern("/a/b").matches("/a/b/c"))
+	assertT.False(compilePathPattern("/a/b").matches("/a/c"))
+}
+
+func TestIgnoredAttributes(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a timestamp="1" session-id="x"><b generated-at="10" id="1"/></a>`
+	xmlSample2 := `<a timestamp="2" session-key="y"><b generated-at="20" id="1"/></a>`
+
+	assertT.Equal(emptyList,
+		Compare(xmlSample1, xmlSample2, WithIgnoredAttributes("timestamp", "generated-at", "session-*")).GetMessages())
+	assertT.Equal([]string{"Attributes differ: 'generated-at=10' vs 'generated-at=20', path='/a/b'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoredAttributesAt("/a", "timestamp", "generated-at", "session-??*")).GetMessages())
+	assertT.Equal([]string{"Attributes differ: counts 1 vs 1: session-id[0]:+1, session-key[0]:-1, path='/a'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoredAttributes("time*"), WithIgnoredAttributesAt("/a/b", "generated-at")).GetMessages())
+}
+
+func TestWildcards(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.True(compileWildcard("session-*").MatchString("session-id"))
+	assertT.True(compileWildcard("a?c").MatchString("abc"))
+	assertT.False(compileWildcard("a.c").MatchString("abc"))
+	assertT.False(compileWildcard("session").MatchString("session-id"))
+}
+
+func TestStrictNamespaces(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a xmlns:x="urn:x"><b/><x:c>1</x:c><d><x:e/></d></a>`
+	xmlSample2 := `<a xmlns:y="urn:y"><b/><y:c>2</y:c><d><e/></d></a>`
+
+	assertT.Equal([]string{"Node namespaces differ: 'urn:x' vs 'urn:y', path='/a/c[1]'", "Node texts differ: '1' vs '2', path='/a/c[1]'"},
+		Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Node namespaces differ: 'urn:x' vs 'urn:y', path='/a/c[1]'", "Node texts differ: '1' vs '2', path='/a/c[1]'",
+		"Node namespaces differ: 'urn:x' vs '', path='/a/d[2]/e'"},
+		Compare(xmlSample1, xmlSample2, WithStrictNamespaces()).GetMessages())
+	assertT.Equal([]string{"Children order differ for 2 nodes, path='/a'", "Node namespaces differ: '' vs 'urn:y', path='/a/c[0]'"},
+		Compare(`<a><c/><b/></a>`, `<a xmlns:y="urn:y"><b/><y:c/></a>`, WithStrictNamespaces()).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 1 vs 2: b[1]:-1, path='/a'", "Node namespaces differ: '' vs 'urn:y', path='/a/c'"},
+		Compare(`<a><c/></a>`, `<a xmlns:y="urn:y"><y:c/><b/></a>`, WithStrictNamespaces()).GetMessages())
+	assertT.Equal([]string{"Node namespaces differ: '' vs 'urn:y', path='/a/c'"},
+		Compare(`<a><c/></a>`, `<a xmlns:y="urn:y"><y:c/></a>`, WithStrictNamespaces(), WithIgnoreOrder()).GetMessages())
+}
+
+func TestEmptyAttributesAsMissing(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><e a="" b="1"/></a>`
+	xmlSample2 := `<a><e b="1"/></a>`
+
+	assertT.Equal([]string{"Attributes differ: counts 2 vs 1: a[0]:+1, path='/a/e'"}, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithEmptyAttributesAsMissing()).GetMessages())
+	assertT.Equal([]string{"Attributes differ: counts 1 vs 2: a[0]:-1, path='/a/e'"},
+		Compare(`<a><e b="1"/></a>`, `<a><e a="x" b="1"/></a>`, WithEmptyAttributesAsMissing()).GetMessages())
+}
+
+func TestMaxDiffs(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b>1</b><c>2</c><d x="1"><e>3</e></d></a>`
+	xmlSample2 := `<a><b>4</b><c>5</c><d x="2"><e>6</e></d></a>`
+
+	assertT.Equal(4, len(Compare(xmlSample1, xmlSample2).GetMessages()))
+	assertT.Equal(4, len(Compare(xmlSample1, xmlSample2, WithMaxDiffs(0)).GetMessages()))
+	assertT.Equal([]string{"Node texts differ: '1' vs '4', path='/a/b[0]'", "Node texts differ: '2' vs '5', path='/a/c[1]'"},
+		Compare(xmlSample1, xmlSample2, WithMaxDiffs(2)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '1' vs '4', path='/a/b[0]'"}, Compare(xmlSample1, xmlSample2, WithStopOnFirst()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '2' vs '5', path='/a/c[1]'"},
+		Compare(xmlSample1, xmlSample2, WithMaxDiffs(1), WithIgnoredDiscrepancies(`'1' vs '4'`)).GetMessages())
+}
+
+func TestMaxDepth(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b x="1"><c>1</c></b><d>2</d></a>`
+	xmlSample2 := `<a><b x="2"><c>3</c></b><d>2</d></a>`
+
+	assertT.Equal([]string{"Attributes differ: 'x=1' vs 'x=2', path='/a/b[0]'", "Node texts differ: '1' vs '3', path='/a/b[0]/c'"},
+		Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '<c>1</c>' vs '<c>3</c>', path='/a/b[0]'", "Attributes differ: 'x=1' vs 'x=2', path='/a/b[0]'"},
+		Compare(xmlSample1, xmlSample2, WithMaxDepth(2)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '<b x=\"1\"><c>1</c></b><d>2</d>' vs '<b x=\"2\"><c>3</c></b><d>2</d>', path='/a'"},
+		Compare(xmlSample1, xmlSample2, WithMaxDepth(1)).GetMessages())
+	assertT.Equal(2, len(Compare(xmlSample1, xmlSample2, WithMaxDepth(3)).GetMessages()))
+}
+
+func TestCommentsAndProcessingInstructions(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><!-- first --><b><?render fast?>1</b><c><!-- nested --></c></a>`
+	xmlSample2 := `<a><!-- second --><b><?render slow?>1</b><c><!--nested--></c></a>`
+
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Node comments differ: 'first' vs 'second', path='/a'"}, Compare(xmlSample1, xmlSample2, WithComments()).GetMessages())
+	assertT.Equal([]string{"Node processing instructions differ: '<?render fast?>' vs '<?render slow?>', path='/a/b[0]'"},
+		Compare(xmlSample1, xmlSample2, WithProcessingInstructions()).GetMessages())
+
+	diffs := Compare(`<a><!--x--><!--y--></a>`, `<a><!--x--></a>`, WithComments()).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(CommentChanged, diffs[0].Kind)
+	assertT.Equal("x|y", diffs[0].Expected)
+	assertT.Equal("x", diffs[0].Actual)
+}
+
+func TestStrictCDATA(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><x><![CDATA[a<b]]></x><y><![CDATA[1]]></y></a>`
+	xmlSample2 := `<a><x>a&lt;b</x><y><![CDATA[1]]></y></a>`
+
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Node text representations differ: 'CDATA' vs 'text', path='/a/x[0]'"},
+		Compare(xmlSample1, xmlSample2, WithStrictCDATA()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: 'a<b' vs 'a<c', path='/a/x'"},
+		Compare(`<a><x><![CDATA[a<b]]></x></a>`, `<a><x>a&lt;c</x></a>`, WithStrictCDATA()).GetMessages())
+}
+
+func TestStrictAttributeOrder(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b x="1" y="2" z="3"/></a>`
+	xmlSample2 := `<a><b y="2" x="1" z="3"/></a>`
+
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Attributes order differ: 'x, y, z' vs 'y, x, z', path='/a/b'"},
+		Compare(xmlSample1, xmlSample2, WithStrictAttributeOrder()).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithStrictAttributeOrder(), WithIgnoredAttributes("x")).GetMessages())
+
+	diffs := Compare(xmlSample1, xmlSample2, WithStrictAttributeOrder()).GetStructuredDiffs()
+	assertT.Equal(AttrOrderChanged, diffs[0].Kind)
+	assertT.Equal("b", diffs[0].Name)
+}
+
+func TestWhitespaceModes(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b> some   text
+	</b><c>x</c></a>`
+	xmlSample2 := `<a><b>some text</b><c> x </c></a>`
+
+	assertT.Equal([]string{"Node texts differ: 'some   text' vs 'some text', path='/a/b[0]'"}, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithWhitespace(WhitespaceCollapse)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: ' some   text\n\t' vs 'some text', path='/a/b[0]'", "Node texts differ: 'x' vs ' x ', path='/a/c[1]'"},
+		Compare(xmlSample1, xmlSample2, WithWhitespace(WhitespaceExact)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: 'x' vs ' x ', path='/a/c'"},
+		Compare(`<a><c>x</c></a>`, `<a><c> x </c></a>`, WithWhitespace(WhitespaceExact)).GetMessages())
+}
+
+func TestStrictSelfClosing(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><x/><y></y><z a="1" /></a>`
+	xmlSample2 := `<a><x></x><y></y><z a="1"></z></a>`
+
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal([]string{"Element serializations differ: 'self-closing' vs 'empty', path='/a/x[0]'",
+		"Element serializations differ: 'self-closing' vs 'empty', path='/a/z[2]'"},
+		Compare(xmlSample1, xmlSample2, WithStrictSelfClosing()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '' vs 'text', path='/a/x'"},
+		Compare(`<a><x/></a>`, `<a><x>text</x></a>`, WithStrictSelfClosing()).GetMessages())
+}
+
+func TestCaseInsensitivity(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><item Id="1">Red</item><item Id="2">Green</item></a>`
+	xmlSample2 := `<A><ITEM ID="1">RED</ITEM><ITEM ID="2">GREEN</ITEM></A>`
+
+	assertT.Equal([]string{"Node names differ: 'a' vs 'A', path='/a'"}, Compare(xmlSample1, xmlSample2, WithStopOnFirst()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: 'Red' vs 'RED', path='/a/item[0]'", "Attributes differ: counts 1 vs 1: Id[0]:+1, ID[0]:-1, path='/a/item[0]'",
+		"Node texts differ: 'Green' vs 'GREEN', path='/a/item[1]'", "Attributes differ: counts 1 vs 1: Id[0]:+1, ID[0]:-1, path='/a/item[1]'"},
+		Compare(xmlSample1, xmlSample2, WithCaseInsensitiveNames()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: 'Red' vs 'RED', path='/a/item[0]'", "Node texts differ: 'Green' vs 'GREEN', path='/a/item[1]'"},
+		Compare(xmlSample1, xmlSample2, WithCaseInsensitiveNames(), WithCaseInsensitiveAttributeNames()).GetMessages())
+	assertT.Equal(emptyList,
+		Compare(xmlSample1, xmlSample2, WithCaseInsensitiveNames(), WithCaseInsensitiveAttributeNames(), WithCaseInsensitiveValues()).GetMessages())
+	assertT.Equal(emptyList,
+		Compare(xmlSample1, xmlSample2, WithCaseInsensitiveNames(), WithCaseInsensitiveAttributeNames(), WithCaseInsensitiveValues(), WithIgnoreOrder()).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 1 vs 1: b[0]:+1, B[0]:-1, path='/a'"}, Compare(`<a><b/></a>`, `<a><B/></a>`).GetMessages())
+}
+
+func TestNilAsAbsent(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><b>1</b><e xsi:nil="true"/></a>`
+	xmlSample2 := `<a><b>1</b></a>`
+	xmlSample3 := `<a><b>1</b><e></e></a>`
+
+	assertT.Equal([]string{"Children differ: counts 2 vs 1: e[1]:+1, path='/a'"}, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithNilAsAbsent()).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample2, xmlSample1, WithNilAsAbsent()).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 1 vs 2: e[1]:-1, path='/a'"}, Compare(xmlSample1, xmlSample3, WithNilAsAbsent()).GetMessages())
+	assertT.Equal(emptyList, Compare(`<a><e xsi:nil="1"/></a>`, `<a></a>`, WithNilAsAbsent()).GetMessages())
+}
+
+func TestMoveDetection(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<config><db><pool size="5"><min>1</min></pool><host>h</host></db><cache/></config>`
+	xmlSample2 := `<config><db><host>h</host></db><cache><pool size="5"><min>1</min></pool></cache></config>`
+
+	diffs := Compare(xmlSample1, xmlSample2).GetStructuredDiffs()
+	assertT.Equal(2, len(diffs))
+
+	diffs = Compare(xmlSample1, xmlSample2, WithMoveDetection()).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ElementMoved, diffs[0].Kind)
+	assertT.Equal("pool", diffs[0].Name)
+	assertT.Equal("/config/db[0]/pool[0]", diffs[0].Path1)
+	assertT.Equal("/config/cache[1]/pool", diffs[0].Path2)
+	assertT.Equal("Element moved: 'pool' from '/config/db[0]/pool[0]' to '/config/cache[1]/pool'", diffs[0].Message)
+	assertT.Equal("ElementMoved: 'pool', path='/config/db[0]/pool[0]' to '/config/cache[1]/pool'", diffs[0].String())
+	assertT.Equal("XC015", diffs[0].Kind.Code())
+
+	// Messages and handlers get moves too
+	assertT.Equal([]string{"Element moved: 'pool' from '/config/db[0]/pool[0]' to '/config/cache[1]/pool'"},
+		Compare(xmlSample1, xmlSample2, WithMoveDetection()).GetMessages())
+	handled := make([]DiffKind, 0)
+	CompareWithHandler(xmlSample1, xmlSample2, func(diff Diff) bool {
+		handled = append(handled, diff.Kind)
+		return true
+	}, WithMoveDetection())
+	assertT.Equal([]DiffKind{ElementMoved}, handled)
+
+	// Modified subtrees are not moved
+	diffs = Compare(xmlSample1, strings.Replace(xmlSample2, `size="5"`, `size="6"`, 1), WithMoveDetection()).GetStructuredDiffs()
+	assertT.Equal([]DiffKind{ElementRemoved, ElementAdded}, []DiffKind{diffs[0].Kind, diffs[1].Kind})
+
+	// Other removed children stay in the discrepancy
+	xmlSample3 := `<config><db><pool size="5"><min>1</min></pool><host>h</host><port/></db><cache/></config>`
+	assertT.Equal([]string{"Children differ: counts 3 vs 1: port[2]:+1, path='/config/db[0]'",
+		"Element moved: 'pool' from '/config/db[0]/pool[0]' to '/config/cache[1]/pool'"},
+		Compare(xmlSample3, xmlSample2, WithMoveDetection()).GetMessages())
+
+	// Each removed subtree is moved once
+	diffs = Compare(`<r><a><i/><i/><j/></a><b/></r>`, `<r><a><j/></a><b><i/></b></r>`, WithMoveDetection()).GetStructuredDiffs()
+	assertT.Equal([]DiffKind{ElementRemoved, ElementMoved}, []DiffKind{diffs[0].Kind, diffs[1].Kind})
+}
+
+func TestContextLines(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := "<a>\n  <b>1</b>\n  <c/>\n  <d/>\n</a>"
+	xmlSample2 := "<a>\r\n  <b>2</b>\r\n</a>"
+
+	diffs := Compare(xmlSample1, xmlSample2).GetStructuredDiffs()
+	assertT.Equal("", diffs[0].Context1)
+
+	diffs = Compare(xmlSample1, xmlSample2, WithContextLines(1)).GetStructuredDiffs()
+	assertT.Equal(3, len(diffs))
+	assertT.Equal(ElementRemoved, diffs[0].Kind)
+	assertT.Equal("     2 |   <b>1</b>\n>    3 |   <c/>\n     4 |   <d/>\n", diffs[0].Context1)
+	assertT.Equal("", diffs[0].Context2)
+	assertT.Equal(TextChanged, diffs[2].Kind)
+	assertT.Equal("     1 | <a>\n>    2 |   <b>1</b>\n     3 |   <c/>\n", diffs[2].Context1)
+	assertT.Equal("     1 | <a>\n>    2 |   <b>2</b>\n     3 | </a>\n", diffs[2].Context2)
+
+	assertT.Equal(">    1 | <a>\n", diffs[2].Node1.Parent.sourceSnippet(0))
+	assertT.Equal("", (&Node{}).sourceSnippet(1))
+}
+
+func TestKeyAttributes(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample1 := `<a><item id="1"><v>1</v></item><item id="2"><v>2</v></item></a>`
+	sample2 := `<a><item id="2"><v>3</v></item><item id="3"><v>1</v></item></a>`
+	assertT.Equal([]string{"Attributes differ: 'id=1' vs 'id=2', path='/a/item[0]'", "Node texts differ: '1' vs '3', path='/a/item[0]/v'",
+		"Attributes differ: 'id=2' vs 'id=3', path='/a/item[1]'", "Node texts differ: '2' vs '1', path='/a/item[1]/v'"},
+		Compare(sample1, sample2).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 2 vs 2: item[0]:+1, item[1]:-1, path='/a'", "Node texts differ: '2' vs '3', path='/a/item[1]/v'"},
+		Compare(sample1, sample2, WithKeyAttributes("id")).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 2 vs 2: item[0]:+1, item[1]:-1, path='/a'", "Node texts differ: '2' vs '3', path='/a/item[1]/v'"},
+		Compare(sample1, sample2, WithKeyAttributes("key", "id"), WithIgnoreOrder()).GetMessages())
+}
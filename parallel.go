@@ -0,0 +1,71 @@
+package xmlcomparator
+
+import "sync"
+
+// Compares pairs of nodes one by one or, if parallelism is configured, concurrently in a pool of workers.
+// Differences found by workers are merged in the order of pairs, so results don't depend on scheduling.
+func pairsDifferent(pairs []nodePair, recorder *diffRecorder) {
+	if !recorder.canParallelize(len(pairs)) {
+		for _, pair := range pairs {
+			nodesDifferent(pair.node1, pair.node2, recorder)
+		}
+		return
+	}
+
+	workers := make([]*diffRecorder, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(recorder.opts.parallelism, len(pairs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				workers[i] = recorder.worker()
+				if !workers[i].isCanceled() {
+					nodesDifferent(pairs[i].node1, pairs[i].node2, workers[i])
+				}
+			}
+		}()
+	}
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, worker := range workers {
+		recorder.merge(worker)
+	}
+}
+
+// Checks whether pairs of nodes should be compared concurrently - only at the topmost level with several pairs.
+func (recorder *diffRecorder) canParallelize(pairsCount int) bool {
+	return recorder.opts.parallelism > 1 && pairsCount > 1 && !recorder.isWorker && !recorder.equalityOnly
+}
+
+// Recorder of a subtree comparison in a worker; settings and ignored nodes are shared read-only.
+func (recorder *diffRecorder) worker() *diffRecorder {
+	worker := newDiffRecorder(recorder.opts, recorder.ignoredDiscrepancies)
+	worker.ignoredNodes = recorder.ignoredNodes
+	worker.isWorker = true
+	worker.ctx = recorder.ctx
+	worker.session = recorder.session
+	return worker
+}
+
+// Adds differences found by the worker as if they were found by this recorder.
+func (recorder *diffRecorder) merge(worker *diffRecorder) {
+	recorder.nodesCompared += worker.nodesCompared
+	for _, diff := range worker.diffs {
+		if textDiff, ok := diff.(*textualDiff); ok && textDiff.diffType == DiffSpace &&
+			!recorder.areNamespacesNew(textDiff.text1, textDiff.text2) {
+			recorder.suppressed++
+			continue
+		}
+		recorder.addDiff(diff)
+	}
+	if worker.ctxErr != nil && recorder.ctxErr == nil {
+		recorder.ctxErr = worker.ctxErr
+		recorder.aborted = true
+	}
+}
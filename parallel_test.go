@@ -0,0 +1,52 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func catalogSample(count int, modified func(i int) bool) string {
+	var buf strings.Builder
+	buf.WriteString(`<catalog xmlns:p="urn:p">`)
+	for i := range count {
+		price := fmt.Sprint(i)
+		space := "urn:p"
+		if modified(i) {
+			price += ".5"
+			space = "urn:q"
+		}
+		fmt.Fprintf(&buf, `<item id="%d"><name>Item %d</name><price xmlns="%s">%s</price></item>`, i, i, space, price)
+	}
+	buf.WriteString("</catalog>")
+	return buf.String()
+}
+
+func TestParallelComparison(t *testing.T) {
+	assertT := assert.New(t)
+
+	sample1 := catalogSample(200, func(int) bool { return false })
+	sample2 := catalogSample(200, func(i int) bool { return i%7 == 3 })
+	optionSets := [][]Option{{}, {WithIgnoreOrder()}, {WithStrictCDATA()}, {WithMaxDiffs(5)}, {WithIgnoredXPath("//item[@id='3']")}}
+	for _, opts := range optionSets {
+		expected := Compare(sample1, sample2, opts...).GetStructuredDiffs()
+		assertT.NotEmpty(expected)
+		for _, workers := range []int{0, 2, 8} {
+			actual := Compare(sample1, sample2, append(opts, WithParallelism(workers))...).GetStructuredDiffs()
+			assertT.Equal(expected, actual)
+		}
+	}
+
+	// Differences of namespaces are reported once
+	diffs := Compare(sample1, sample2, WithParallelism(4), WithStrictNamespaces()).Filter(ByKind(NamespaceChanged))
+	assertT.Equal(1, len(diffs))
+
+	found := 0
+	CompareWithHandler(sample1, sample2, func(Diff) bool {
+		found++
+		return found < 3
+	}, WithParallelism(4))
+	assertT.Equal(3, found)
+}
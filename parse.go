@@ -2,59 +2,219 @@ package xmlcomparator
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/xml"
-	"hash/crc32"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"sort"
 	"strings"
 )
 
-var crc32c = crc32.MakeTable(crc32.Castagnoli)
+var cdataStart = []byte("<![CDATA[")
 
-type parseNode struct {
+// Element of the parsed XML tree.
+type Node struct {
 	XMLName  xml.Name
-	Attrs    []xml.Attr  `xml:"-"`
-	Content  []byte      `xml:",innerxml"`
-	CharData string      `xml:",chardata"`
-	Children []parseNode `xml:",any"`
-	Parent   *parseNode  `xml:"-"`
-	Hash     uint32      `xml:"-"`
+	Attrs    []xml.Attr `xml:"-"`
+	Content  []byte     `xml:",innerxml"`
+	CharData string     `xml:",chardata"`
+	Children []Node     `xml:",any"`
+	Parent   *Node      `xml:"-"`
+	Hash     uint64     `xml:"-"`
+	// Own text contains a CDATA section
+	CDATA bool `xml:"-"`
+	// Element is serialized as `<x/>` rather than `<x></x>`
+	SelfClosing bool `xml:"-"`
+	// Position of the start tag in the input; zero for nodes not created by parsing
+	Pos Position `xml:"-"`
+	// Offsets of the element start, start tag end and element end in the input
+	startOffset int64
+	startEnd    int64
+	endOffset   int64
+	// Parsed input
+	source string
+	// Index among siblings plus one for streamed elements, which siblings are not kept
+	streamIndex int
+	// Last segment of the path like `/name[1]`, precomputed when the node is linked to the parent
+	segment string
+	// Cached result of `Digest()`
+	digest *Digest
+	// Prefixes of the element name and of attribute names in the parsed input
+	prefix       string
+	attrPrefixes map[xml.Name]string
+}
+
+// Position in the parsed input.
+type Position struct {
+	// Line number starting from 1
+	Line int
+	// Byte offset in the line starting from 1
+	Column int
 }
 
 // Unmarshals XML data into a Node structure - `Decoder` requirement to parse attributes.
-func (n *parseNode) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+func (n *Node) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if err := spendNode(d); err != nil {
+		return err
+	}
 	n.Attrs = start.Attr
-	type node parseNode
+	n.startEnd = d.InputOffset()
+	type node Node
 
-	return d.DecodeElement((*node)(n), &start)
+	err := d.DecodeElement((*node)(n), &start)
+	n.endOffset = d.InputOffset()
+	return err
+}
+
+// Parses XML string into a tree of nodes.
+//   - xmlString - XML string to unmarshal
+//
+// Returns: root node of the XML tree and error if any
+func UnmarshalXMLString(xmlString string) (*Node, error) {
+	return parseXML(xmlString)
+}
+
+// Parses XML from the reader into a tree of nodes; the input is read once.
+// A copy of the read input is kept with the tree for positions, raw markup and prefixes of nodes, so memory grows
+// by the document size in addition to the tree - `CompareXmlStreams` compares large documents with bounded memory.
+//   - r - reader of XML document
+//
+// Returns: root node of the XML tree and error if any
+func UnmarshalXMLReader(r io.Reader) (*Node, error) {
+	return parseReader(r)
+}
+
+// Parses XML file into a tree of nodes; `.gz`, `.bz2` and `.zip` files are decompressed.
+//   - path - path to the file
+//
+// Returns: root node of the XML tree and error if any
+func UnmarshalXMLFile(path string) (*Node, error) {
+	return parseFile(path, documentLimits{})
+}
+
+func parseFile(path string, limits documentLimits) (*Node, error) {
+	file, err := OpenXmlFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseLimitedReader(file, limits)
 }
 
 // Unmarshals XML string into a Node structure
 //   - xmlString - XML string to unmarshal
 //
 // Returns: root node of the XML tree and error if any
-func parseXML(xmlString string) (*parseNode, error) {
-	buf := bytes.NewBuffer([]byte(xmlString))
-	dec := xml.NewDecoder(buf)
+func parseXML(xmlString string) (*Node, error) {
+	return parseLimitedXML(xmlString, documentLimits{})
+}
+
+// Unmarshals XML string failing with `*LimitError` if the document exceeds limits.
+func parseLimitedXML(xmlString string, limits documentLimits) (*Node, error) {
+	if limits.maxSize > 0 && int64(len(xmlString)) > limits.maxSize {
+		return nil, &LimitError{Unit: "bytes", Limit: limits.maxSize}
+	}
+	return decodeTree(strings.NewReader(xmlString), func() string { return xmlString }, limits)
+}
 
-	var root parseNode
-	if err := dec.Decode(&root); err != nil {
+// Unmarshals XML from the reader into a Node structure; a single copy of the read input is kept as the source of nodes.
+func parseReader(r io.Reader) (*Node, error) {
+	return parseLimitedReader(r, documentLimits{})
+}
+
+// Unmarshals XML from the reader failing with `*LimitError` if the document exceeds limits.
+func parseLimitedReader(r io.Reader, limits documentLimits) (*Node, error) {
+	var source strings.Builder
+	return decodeTree(io.TeeReader(limits.reader(r), &source), source.String, limits)
+}
+
+// Decodes the root element and fills node properties that depend on the input.
+//   - r - reader of XML
+//   - source - provider of the input read by the decoder
+//   - limits - limits of the document; parsing fails with `*LimitError` when they are exceeded
+func decodeTree(r io.Reader, source func() string, limits documentLimits) (*Node, error) {
+	dec, release := limits.decoder(r)
+	defer release()
+
+	start, err := readProlog(dec, limits)
+	if err != nil {
+		return nil, err
+	}
+	var root Node
+	if err = dec.DecodeElement(&root, start); err != nil {
 		return nil, err
 	}
 
-	root.walk(func(n *parseNode) bool {
+	root.completeTree(source())
+	return &root, nil
+}
+
+// Links decoded nodes and fills their properties that depend on the input, then computes hashes.
+func (root *Node) completeTree(xmlString string) {
+	lineStarts := findLineStarts(xmlString)
+	root.walk(func(n *Node) bool {
 		for i := range n.Children {
-			n.Children[i].Parent = n
+			n.linkChild(i)
 		}
+		n.CDATA = n.ownCDATA()
+		n.SelfClosing = n.startEnd >= 2 && xmlString[n.startEnd-2] == '/'
+		n.startOffset = int64(strings.LastIndexByte(xmlString[:n.startEnd], '<'))
+		n.recordPrefixes(xmlString[n.startOffset:n.startEnd])
+		n.source = xmlString
+		n.Pos = positionOf(lineStarts, int(n.startOffset))
 		return true
 	})
 
 	root.hashCode()
+}
 
-	return &root, nil
+// Records prefixes of the element and attribute names as they are written in the start tag -
+// decoded names have namespace URIs instead.
+func (node *Node) recordPrefixes(startTag string) {
+	if !strings.Contains(startTag, ":") {
+		return
+	}
+	dec := xml.NewDecoder(strings.NewReader(startTag))
+	dec.Strict = false
+	token, err := dec.RawToken()
+	start, ok := token.(xml.StartElement)
+	if err != nil || !ok || len(start.Attr) != len(node.Attrs) {
+		return
+	}
+
+	node.prefix = start.Name.Space
+	for i, attr := range start.Attr {
+		if attr.Name.Space != "" && attr.Name.Space != "xmlns" {
+			if node.attrPrefixes == nil {
+				node.attrPrefixes = make(map[xml.Name]string)
+			}
+			node.attrPrefixes[node.Attrs[i].Name] = attr.Name.Space
+		}
+	}
+}
+
+// Offsets of lines beginnings in the text.
+func findLineStarts(text string) []int {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+func positionOf(lineStarts []int, offset int) Position {
+	line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	return Position{Line: line, Column: offset - lineStarts[line-1] + 1}
 }
 
 // Walks depth-first through the XML tree calling the function for iteslef and then for each child node
-//   - f - function to call for each node; should return `false` to stop traversiong
-func (node *parseNode) walk(f func(*parseNode) bool) {
+//   - f - function to call for each node; should return `false` to skip children of the node
+func (node *Node) walk(f func(*Node) bool) {
 	if !f(node) {
 		return
 	}
@@ -64,29 +224,180 @@ func (node *parseNode) walk(f func(*parseNode) bool) {
 	}
 }
 
+// Extracts comments and processing instructions that are immediate children of the node.
+//
+// Returns: comments texts and processing instructions in the form `<?target instruction?>`
+func (node *Node) ownMarkup() ([]string, []string) {
+	comments := make([]string, 0)
+	procInsts := make([]string, 0)
+
+	node.scanOwnTokens(func(token xml.Token, _ []byte) {
+		switch t := token.(type) {
+		case xml.Comment:
+			comments = append(comments, strings.TrimSpace(string(t)))
+		case xml.ProcInst:
+			procInsts = append(procInsts, "<?"+t.Target+" "+strings.TrimSpace(string(t.Inst))+"?>")
+		}
+	})
+
+	return comments, procInsts
+}
+
+// Checks whether own text of the node contains a CDATA section.
+func (node *Node) ownCDATA() bool {
+	if !bytes.Contains(node.Content, cdataStart) {
+		return false
+	}
+
+	found := false
+	node.scanOwnTokens(func(token xml.Token, raw []byte) {
+		if _, ok := token.(xml.CharData); ok && bytes.HasPrefix(raw, cdataStart) {
+			found = true
+		}
+	})
+	return found
+}
+
+// Calls the function for tokens of the node content that are not nested in child elements.
+//   - f - function accepting the token and the raw content starting with the token
+func (node *Node) scanOwnTokens(f func(token xml.Token, raw []byte)) {
+	dec := xml.NewDecoder(bytes.NewReader(node.Content))
+	dec.Strict = false
+	depth := 0
+	for {
+		offset := dec.InputOffset()
+		token, err := dec.RawToken()
+		if err != nil {
+			return
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		default:
+			if depth == 0 {
+				f(token, node.Content[offset:])
+			}
+		}
+	}
+}
+
+// Original markup of the element as it appears in the parsed input.
+// Namespace declarations of ancestors are not included.
+func (node *Node) rawXML() string {
+	if node.source == "" || node.startOffset < 0 {
+		return ""
+	}
+	return node.source[node.startOffset:node.endOffset]
+}
+
+// Raw markup around child elements in the parsed input - before the first child, between children and after
+// the last one; texts keep entity references, comments and processing instructions.
+//
+// Returns: `len(node.Children)+1` segments
+func (node *Node) rawSegments() []string {
+	segments := make([]string, len(node.Children)+1)
+	offset := node.startEnd
+	for i := range node.Children {
+		segments[i] = node.source[offset:node.Children[i].startOffset]
+		offset = node.Children[i].endOffset
+	}
+	segments[len(node.Children)] = node.source[offset : node.startEnd+int64(len(node.Content))]
+	return segments
+}
+
+// Lines of the parsed input around the start tag of the element; the line of the tag is marked with `>`.
+//   - context - count of lines before and after the tag line
+func (node *Node) sourceSnippet(context int) string {
+	if node.source == "" || node.Pos.Line == 0 {
+		return ""
+	}
+
+	lines := strings.Split(node.source, "\n")
+	first, last := max(node.Pos.Line-context, 1), min(node.Pos.Line+context, len(lines))
+	var buf strings.Builder
+	for line := first; line <= last; line++ {
+		marker := " "
+		if line == node.Pos.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(&buf, "%s%5d | %s\n", marker, line, strings.TrimRight(lines[line-1], "\r"))
+	}
+	return buf.String()
+}
+
 //------- hash code generation -------
 
-// Recursive function
-func (node *parseNode) hashCode() uint32 {
+// Recursive function - 64-bit FNV-1a hash of the name, own text, attributes and hashes of children.
+// The hash serves matching of nodes and may change between versions - see `Node.Digest()` for a stable one.
+// Fields are terminated by separators, so that e.g. moving characters from a name to a text changes the hash.
+func (node *Node) hashCode() uint64 {
 	if node.Hash != 0 {
 		return node.Hash
 	}
 
-	node.Hash = crc32.Checksum([]byte(nodeName(node)), crc32c)
-	node.Hash = crc32.Update(node.Hash, crc32c, []byte(strings.TrimSpace(node.CharData)))
+	hash := fnv.New64a()
+	writeHashField(hash, nodeName(node))
+	writeHashField(hash, strings.TrimSpace(node.CharData))
 
 	for i := range node.Attrs {
 		attrPtr := &node.Attrs[i]
 		if !isNameSpaceAttr(attrPtr) {
-			node.Hash = crc32.Update(node.Hash, crc32c, []byte(attrName(attrPtr)))
-			node.Hash = crc32.Update(node.Hash, crc32c, []byte(attrValue(attrPtr)))
+			writeHashField(hash, attrQName(attrPtr))
+			writeHashField(hash, attrValue(attrPtr))
 		}
 	}
 
-	// Cheap and cheerful
+	var childHash [9]byte
+	childHash[0] = hashChildSeparator
 	for i := range node.Children {
-		node.Hash = 31*node.Hash + node.Children[i].hashCode()
+		binary.LittleEndian.PutUint64(childHash[1:], node.Children[i].hashCode())
+		_, _ = hash.Write(childHash[:])
 	}
 
+	node.Hash = hash.Sum64()
+	// Zero means "not computed"
+	if node.Hash == 0 {
+		node.Hash = 1
+	}
 	return node.Hash
 }
+
+// Checks whether subtrees have equal content covered by hashes - verifies equality of hashes against collisions.
+func identicalSubtrees(node1 *Node, node2 *Node) bool {
+	if node1 == node2 {
+		return true
+	}
+	if node1.hashCode() != node2.hashCode() || nodeName(node1) != nodeName(node2) || trimmedText(node1) != trimmedText(node2) ||
+		len(node1.Children) != len(node2.Children) {
+		return false
+	}
+
+	attrs1, attrs2 := node1.extractAttributes(), node2.extractAttributes()
+	if len(attrs1) != len(attrs2) {
+		return false
+	}
+	for i := range attrs1 {
+		if attrQName(&attrs1[i]) != attrQName(&attrs2[i]) || attrs1[i].Value != attrs2[i].Value {
+			return false
+		}
+	}
+
+	for i := range node1.Children {
+		if !identicalSubtrees(&node1.Children[i], &node2.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	hashFieldSeparator = 0
+	hashChildSeparator = 1
+)
+
+func writeHashField(hash hash.Hash64, value string) {
+	_, _ = io.WriteString(hash, value)
+	_, _ = hash.Write([]byte{hashFieldSeparator})
+}
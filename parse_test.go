@@ -2,6 +2,10 @@ package xmlcomparator
 
 import (
 	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,7 +41,7 @@ func TestWalking(t *testing.T) {
 
 	root, _ := parseXML(xmlString2)
 
-	root.walk(func(n *parseNode) bool {
+	root.walk(func(n *Node) bool {
 		assertT.True(nodeName(n) == "root" || n.Parent != nil)
 		assertT.NotZero(n.Hash)
 		return true
@@ -56,15 +60,172 @@ func TestHashCodeGeneration(t *testing.T) {
 
 	root4, _ := parseXML(`<a><b foo="bar"/><c/></a>`)
 	assertT.NotEqual(root1.Hash, root4.Hash)
+
+	// Fields are separated
+	assertT.NotEqual(E("ab", Text("c")).Hash, E("a", Text("bc")).Hash)
+	assertT.NotEqual(E("a", A("x", "1y"), A("z", "2")).Hash, E("a", A("x", "1"), A("yz", "2")).Hash)
+	assertT.NotEqual(E("a", E("b", E("c"))).Hash, E("a", E("b"), E("c")).Hash)
 }
 
 func TestHashCodeCaching(t *testing.T) {
 	assertT := assert.New(t)
 
-	node := parseNode{XMLName: xml.Name{Space: "spc", Local: "name"}}
-	assertT.Equal(uint32(0), node.Hash)
+	node := Node{XMLName: xml.Name{Space: "spc", Local: "name"}}
+	assertT.Equal(uint64(0), node.Hash)
 	hash := node.hashCode()
 	assertT.Equal(hash, node.Hash)
 
 	assertT.Equal(hash, node.hashCode())
 }
+
+func TestOwnMarkup(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><!-- one --><?pi data?><b><!-- inner --></b>text<!--two--></a>`)
+	comments, procInsts := root.ownMarkup()
+	assertT.Equal([]string{"one", "two"}, comments)
+	assertT.Equal([]string{"<?pi data?>"}, procInsts)
+
+	comments, procInsts = root.Children[0].ownMarkup()
+	assertT.Equal([]string{"inner"}, comments)
+	assertT.Equal(0, len(procInsts))
+}
+
+func TestCDATADetection(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a>text<b><![CDATA[x]]></b><c><d><![CDATA[y]]></d></c><e>&lt;![CDATA[</e></a>`)
+	assertT.False(root.CDATA)
+	assertT.True(root.Children[0].CDATA)
+	assertT.False(root.Children[1].CDATA)
+	assertT.True(root.Children[1].Children[0].CDATA)
+	assertT.False(root.Children[2].CDATA)
+}
+
+func TestSelfClosingDetection(t *testing.T) {
+	assertT := assert.New(t)
+
+	root1, _ := parseXML(`<a><b/><c></c><d x="/"  /></a>`)
+	assertT.False(root1.SelfClosing)
+	assertT.True(root1.Children[0].SelfClosing)
+	assertT.False(root1.Children[1].SelfClosing)
+	assertT.True(root1.Children[2].SelfClosing)
+
+	root2, _ := parseXML(`<a><b></b><c/><d x="/"></d></a>`)
+	assertT.Equal(root1.Hash, root2.Hash)
+	assertT.Equal(root1.Children[0].Hash, root2.Children[0].Hash)
+}
+
+func TestRawXML(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<?xml version="1.0"?><a x=">"><b>1</b><c/>  <d><e/></d></a>`)
+	assertT.Equal(`<a x=">"><b>1</b><c/>  <d><e/></d></a>`, root.rawXML())
+	assertT.Equal(`<b>1</b>`, root.Children[0].rawXML())
+	assertT.Equal(`<c/>`, root.Children[1].rawXML())
+	assertT.Equal(`<d><e/></d>`, root.Children[2].rawXML())
+	assertT.Equal("", (&Node{}).rawXML())
+}
+
+func TestUnmarshalXMLString(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, err := UnmarshalXMLString(`<a><b/></a>`)
+	assertT.Nil(err)
+	assertT.Equal("a", nodeName(root))
+	assertT.Equal(root, root.Children[0].Parent)
+
+	_, err = UnmarshalXMLString(`<a>`)
+	assertT.NotNil(err)
+}
+
+func TestUnmarshalXMLReader(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Reader without `io.ByteReader`, so the decoder reads ahead
+	root, err := UnmarshalXMLReader(io.MultiReader(strings.NewReader("<a>\n <b/>"), strings.NewReader("</a>\n<!-- tail -->")))
+	assertT.Nil(err)
+	assertT.Equal(root, root.Children[0].Parent)
+	assertT.True(root.Children[0].SelfClosing)
+	assertT.Equal(Position{2, 2}, root.Children[0].Pos)
+	assertT.Equal("<b/>", root.Children[0].rawXML())
+
+	_, err = UnmarshalXMLReader(strings.NewReader(`<a>`))
+	assertT.NotNil(err)
+
+	// The read input is kept once as the source of all nodes
+	sample := catalogSample(10, func(int) bool { return false })
+	root, _ = UnmarshalXMLReader(strings.NewReader(sample))
+	assertT.Equal(sample, root.source)
+	root.walk(func(node *Node) bool {
+		assertT.Equal(len(sample), len(node.source))
+		return true
+	})
+}
+
+func TestUnmarshalXMLFile(t *testing.T) {
+	assertT := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "sample.xml")
+	assertT.Nil(os.WriteFile(path, []byte(`<a><b>1</b></a>`), 0o600))
+	root, err := UnmarshalXMLFile(path)
+	assertT.Nil(err)
+	assertT.Equal("1", root.Children[0].CharData)
+
+	_, err = UnmarshalXMLFile(filepath.Join(t.TempDir(), "missing.xml"))
+	assertT.True(os.IsNotExist(err))
+}
+
+func TestNodePositions(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, err := parseXML("<?xml version=\"1.0\"?>\n<a>\n  <b x=\"1\">\n    <c/></b>\r\n\t<d/>\n</a>")
+	assertT.Nil(err)
+	assertT.Equal(Position{2, 1}, root.Pos)
+	assertT.Equal(Position{3, 3}, root.Children[0].Pos)
+	assertT.Equal(Position{4, 5}, root.Children[0].Children[0].Pos)
+	assertT.Equal(Position{5, 2}, root.Children[1].Pos)
+	assertT.Equal(Position{}, (&Node{}).Pos)
+
+	diffs := Compare("<a>\n  <b>1</b>\n</a>", "<a>\n\n  <b>2</b>\n</a>").GetStructuredDiffs()
+	assertT.Equal(Position{2, 3}, diffs[0].Pos1)
+	assertT.Equal(Position{3, 3}, diffs[0].Pos2)
+}
+
+func BenchmarkParseSequentially(b *testing.B) {
+	sample1 := catalogSample(2000, func(int) bool { return false })
+	sample2 := catalogSample(2000, func(i int) bool { return i%100 == 0 })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = parseXML(sample1)
+		_, _ = parseXML(sample2)
+	}
+}
+
+func BenchmarkParseInputs(b *testing.B) {
+	sample1 := catalogSample(2000, func(int) bool { return false })
+	sample2 := catalogSample(2000, func(i int) bool { return i%100 == 0 })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parseSamples(sample1, sample2, createDiffRecorder([]string{}))
+	}
+}
+
+// Allocations are about the same as of `BenchmarkUnmarshalXMLString` - the read input replaces the string.
+func BenchmarkUnmarshalXMLReader(b *testing.B) {
+	sample := catalogSample(2000, func(int) bool { return false })
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = UnmarshalXMLReader(strings.NewReader(sample))
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	sample1 := catalogSample(2000, func(int) bool { return false })
+	sample2 := catalogSample(2000, func(i int) bool { return i%100 == 0 })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Compare(sample1, sample2)
+	}
+}
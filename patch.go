@@ -0,0 +1,379 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Generates an XML Patch document (RFC 5261) that turns the first sample into the second one.
+//   - sample1 - source XML string
+//   - sample2 - target XML string
+//
+// Returns: patch document with `<add>`, `<replace>` and `<remove>` operations and error if samples can't be parsed
+func GeneratePatch(sample1 string, sample2 string) (string, error) {
+	root1, err := parseXML(sample1)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the first sample: %w", err)
+	}
+	root2, err := parseXML(sample2)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the second sample: %w", err)
+	}
+
+	gen := patchGenerator{}
+	if !sameElementNames(root1, root2) {
+		gen.addOp("replace", rootSelector(root1), "", root2.rawXML())
+	} else if !identicalSubtrees(root1, root2) {
+		gen.elementPatch(root1, root2, rootSelector(root1))
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<diff" + namespaceDeclarations(root1, root2) + ">\n")
+	for _, op := range gen.ops {
+		buf.WriteString("  " + op + "\n")
+	}
+	buf.WriteString("</diff>")
+	return buf.String(), nil
+}
+
+// Operations of the patch document.
+type patchGenerator struct {
+	ops []string
+}
+
+func (gen *patchGenerator) addOp(op string, sel string, extra string, content string) {
+	if content == "" {
+		gen.ops = append(gen.ops, fmt.Sprintf(`<%s sel="%s"%s/>`, op, escapeAttr(sel), extra))
+	} else {
+		gen.ops = append(gen.ops, fmt.Sprintf(`<%s sel="%s"%s>%s</%s>`, op, escapeAttr(sel), extra, content, op))
+	}
+}
+
+// Patches the element with the same name - attributes, text and then children.
+func (gen *patchGenerator) elementPatch(node1 *Node, node2 *Node, sel string) {
+	text1, text2 := trimmedText(node1), trimmedText(node2)
+	// Text nodes of mixed content are not addressable reliably
+	if text1 != text2 && (len(node1.Children) != 0 || len(node2.Children) != 0) {
+		gen.addOp("replace", sel, "", node2.rawXML())
+		return
+	}
+
+	gen.attributesPatch(node1, node2, sel)
+	if text1 != text2 {
+		switch {
+		case text1 == "":
+			gen.addOp("add", sel, "", escapeText(text2))
+		case text2 == "":
+			gen.addOp("remove", sel+"/text()", "", "")
+		default:
+			gen.addOp("replace", sel+"/text()", "", escapeText(text2))
+		}
+	}
+
+	gen.childrenPatch(node1, node2, sel)
+}
+
+func (gen *patchGenerator) attributesPatch(node1 *Node, node2 *Node, sel string) {
+	attrs2 := node2.extractAttributes()
+	for _, attr1 := range node1.extractAttributes() {
+		idx := indexOfAttr(attrs2, attr1.Name)
+		switch {
+		case idx < 0:
+			gen.addOp("remove", sel+"/"+attrSelector(&attr1), "", "")
+		case attrs2[idx].Value != attr1.Value:
+			gen.addOp("replace", sel+"/"+attrSelector(&attr1), "", escapeText(attrs2[idx].Value))
+		}
+	}
+
+	attrs1 := node1.extractAttributes()
+	for _, attr2 := range attrs2 {
+		if indexOfAttr(attrs1, attr2.Name) < 0 {
+			gen.addOp("add", sel, ` type="`+escapeAttr(attrSelector(&attr2))+`"`, escapeText(attr2.Value))
+		}
+	}
+}
+
+// Aligns children by names and processes them from the end, so selectors of preceding siblings stay valid.
+func (gen *patchGenerator) childrenPatch(node1 *Node, node2 *Node, sel string) {
+	diffs := compareSequencesEx(node1.Children, node2.Children, func(a, b Node) bool { return sameElementNames(&a, &b) },
+		true, defaultMaxDiffs)
+
+	for i := len(diffs) - 1; i >= 0; i-- {
+		switch diffs[i].t {
+		case diffSame:
+			child1, child2 := &node1.Children[diffs[i].aIdx], &node2.Children[diffs[i].bIdx]
+			if !identicalSubtrees(child1, child2) {
+				gen.elementPatch(child1, child2, childSelector(sel, node1, diffs[i].aIdx))
+			}
+		case diffDelete:
+			gen.addOp("remove", childSelector(sel, node1, diffs[i].aIdx), "", "")
+		case diffAdd:
+			// Anchor is the closest preceding element of the first sample
+			anchor := -1
+			for j := i - 1; j >= 0 && anchor < 0; j-- {
+				if diffs[j].t != diffAdd {
+					anchor = diffs[j].aIdx
+				}
+			}
+			added := node2.Children[diffs[i].bIdx].rawXML()
+			if anchor < 0 {
+				gen.addOp("add", sel, ` pos="prepend"`, added)
+			} else {
+				gen.addOp("add", childSelector(sel, node1, anchor), ` pos="after"`, added)
+			}
+		}
+	}
+}
+
+func sameElementNames(node1 *Node, node2 *Node) bool {
+	return node1.XMLName == node2.XMLName
+}
+
+func rootSelector(root *Node) string {
+	if nodeSpace(root) != "" {
+		return "/*"
+	}
+	return "/" + nodeName(root)
+}
+
+// Selector of the child by position among siblings with the same name, or among all elements for namespaced ones.
+func childSelector(parentSel string, parent *Node, idx int) string {
+	child := &parent.Children[idx]
+	if nodeSpace(child) != "" {
+		return parentSel + "/*[" + strconv.Itoa(idx+1) + "]"
+	}
+
+	pos := 1
+	for i := 0; i < idx; i++ {
+		if sameElementNames(&parent.Children[i], child) {
+			pos++
+		}
+	}
+	return parentSel + "/" + nodeName(child) + "[" + strconv.Itoa(pos) + "]"
+}
+
+func attrSelector(attr *xml.Attr) string {
+	if attr.Name.Space != "" {
+		return "@*[local-name()='" + attrName(attr) + "']"
+	}
+	return "@" + attrName(attr)
+}
+
+func indexOfAttr(attrs []xml.Attr, name xml.Name) int {
+	for i := range attrs {
+		if attrs[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Copies namespace declarations of both roots, so that added fragments keep their prefixes.
+func namespaceDeclarations(root1 *Node, root2 *Node) string {
+	declared := make(map[string]bool)
+	decls := ""
+	for _, root := range []*Node{root2, root1} {
+		for i := range root.Attrs {
+			attr := &root.Attrs[i]
+			if isNameSpaceAttr(attr) && !declared[attrQName(attr)] {
+				declared[attrQName(attr)] = true
+				name := "xmlns"
+				if attr.Name.Space == "xmlns" {
+					name += ":" + attrName(attr)
+				}
+				decls += ` ` + name + `="` + escapeAttr(attr.Value) + `"`
+			}
+		}
+	}
+	return decls
+}
+
+func escapeText(text string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;")
+
+func escapeAttr(text string) string {
+	return attrEscaper.Replace(text)
+}
+
+//------- patch application -------
+
+var patchAttrPattern = regexp.MustCompile(`^(.*)/@(?:\*\[local-name\(\)\s*=\s*['"]([\w.-]+)['"]\]|(?:[\w.-]+:)?([\w.-]+))$`)
+
+// Applies an XML Patch document (RFC 5261) to the XML string.
+// Operations are applied in order, each selector must match exactly one element, attribute or text node.
+//   - doc - XML string to patch
+//   - patch - patch document with `<add>`, `<replace>` and `<remove>` operations
+//
+// Returns: patched XML string and error if the patch is invalid or can't be applied
+func ApplyPatch(doc string, patch string) (string, error) {
+	patchRoot, err := parseXML(patch)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the patch: %w", err)
+	}
+	if nodeName(patchRoot) != "diff" {
+		return "", fmt.Errorf("patch root element must be 'diff', got '%s'", nodeName(patchRoot))
+	}
+
+	for i := range patchRoot.Children {
+		if doc, err = applyOperation(doc, &patchRoot.Children[i]); err != nil {
+			return "", err
+		}
+	}
+	return doc, nil
+}
+
+func applyOperation(doc string, op *Node) (string, error) {
+	root, err := parseXML(doc)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the document: %w", err)
+	}
+
+	sel := opAttribute(op, "sel")
+	if sel == "" {
+		return "", fmt.Errorf("operation '%s' has no selector", nodeName(op))
+	}
+
+	elementSel, attr, isText := sel, "", false
+	if matches := patchAttrPattern.FindStringSubmatch(sel); matches != nil {
+		elementSel, attr = matches[1], matches[2]+matches[3]
+	} else if strings.HasSuffix(sel, "/text()") {
+		elementSel, isText = strings.TrimSuffix(sel, "/text()"), true
+	}
+
+	target, err := selectSingle(root, elementSel)
+	if err != nil {
+		return "", err
+	}
+
+	content := string(op.Content)
+	switch {
+	case nodeName(op) == "add" && attr == "" && !isText:
+		return addContent(doc, target, op, content)
+	case nodeName(op) == "replace" && attr != "":
+		return replaceAttribute(doc, target, attr, &op.CharData, sel)
+	case nodeName(op) == "remove" && attr != "":
+		return replaceAttribute(doc, target, attr, nil, sel)
+	case nodeName(op) == "replace" && isText:
+		return replaceText(doc, target, escapeText(op.CharData), sel)
+	case nodeName(op) == "remove" && isText:
+		return replaceText(doc, target, "", sel)
+	case nodeName(op) == "replace":
+		return doc[:target.startOffset] + strings.TrimSpace(content) + doc[target.endOffset:], nil
+	case nodeName(op) == "remove":
+		if target.Parent == nil {
+			return "", fmt.Errorf("can't remove the root element, selector '%s'", sel)
+		}
+		return doc[:target.startOffset] + doc[target.endOffset:], nil
+	default:
+		return "", fmt.Errorf("unsupported operation '%s' with selector '%s'", nodeName(op), sel)
+	}
+}
+
+func opAttribute(op *Node, name string) string {
+	for i := range op.Attrs {
+		if attrSpace(&op.Attrs[i]) == "" && attrName(&op.Attrs[i]) == name {
+			return op.Attrs[i].Value
+		}
+	}
+	return ""
+}
+
+func selectSingle(root *Node, sel string) (*Node, error) {
+	path, err := compileXPath(sel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector '%s': %w", sel, err)
+	}
+	nodes := path.selectNodes(root)
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("selector '%s' matches %d nodes", sel, len(nodes))
+	}
+	return nodes[0], nil
+}
+
+func addContent(doc string, target *Node, op *Node, content string) (string, error) {
+	if typ := opAttribute(op, "type"); typ != "" {
+		name := strings.TrimPrefix(typ, "@")
+		if name == typ {
+			return "", fmt.Errorf("unsupported node type '%s'", typ)
+		}
+		// Attribute is added at the end of the start tag
+		end := target.startEnd - 1
+		if target.SelfClosing {
+			end--
+		}
+		for end > target.startOffset && isXMLSpace(doc[end-1]) {
+			end--
+		}
+		return doc[:end] + ` ` + name + `="` + escapeAttr(op.CharData) + `"` + doc[end:], nil
+	}
+
+	switch pos := opAttribute(op, "pos"); pos {
+	case "before":
+		return doc[:target.startOffset] + content + doc[target.startOffset:], nil
+	case "after":
+		return doc[:target.endOffset] + content + doc[target.endOffset:], nil
+	case "prepend":
+		return insertIntoElement(doc, target, content, true), nil
+	case "":
+		return insertIntoElement(doc, target, content, false), nil
+	default:
+		return "", fmt.Errorf("unsupported position '%s'", pos)
+	}
+}
+
+// Inserts content as the first or the last child of the element.
+func insertIntoElement(doc string, target *Node, content string, prepend bool) string {
+	if target.SelfClosing {
+		startTag := strings.TrimRightFunc(doc[target.startOffset:target.endOffset-2], func(r rune) bool { return r < 128 && isXMLSpace(byte(r)) })
+		qname := startTag[1:]
+		if idx := strings.IndexFunc(qname, func(r rune) bool { return r < 128 && isXMLSpace(byte(r)) }); idx >= 0 {
+			qname = qname[:idx]
+		}
+		return doc[:target.startOffset] + startTag + ">" + content + "</" + qname + ">" + doc[target.endOffset:]
+	}
+
+	if prepend {
+		return doc[:target.startEnd] + content + doc[target.startEnd:]
+	}
+	endTag := strings.LastIndexByte(doc[:target.endOffset], '<')
+	return doc[:endTag] + content + doc[endTag:]
+}
+
+// Replaces the attribute value or removes the attribute if `value` is `nil`.
+func replaceAttribute(doc string, target *Node, name string, value *string, sel string) (string, error) {
+	pattern := regexp.MustCompile(`\s+(?:[\w.-]+:)?` + regexp.QuoteMeta(name) + `\s*=\s*("[^"]*"|'[^']*')`)
+	startTag := doc[target.startOffset:target.startEnd]
+	loc := pattern.FindStringSubmatchIndex(startTag)
+	if loc == nil {
+		return "", fmt.Errorf("selector '%s' matches 0 nodes", sel)
+	}
+
+	start, end := int(target.startOffset), int(target.startOffset)
+	if value == nil {
+		start, end = start+loc[0], end+loc[1]
+		return doc[:start] + doc[end:], nil
+	}
+	start, end = start+loc[2], end+loc[3]
+	return doc[:start] + `"` + escapeAttr(*value) + `"` + doc[end:], nil
+}
+
+// Replaces text of the element without children or removes it if `text` is empty.
+func replaceText(doc string, target *Node, text string, sel string) (string, error) {
+	if len(target.Children) != 0 || trimmedText(target) == "" {
+		return "", fmt.Errorf("selector '%s' doesn't match a single text node", sel)
+	}
+	endTag := strings.LastIndexByte(doc[:target.endOffset], '<')
+	return doc[:target.startEnd] + text + doc[endTag:], nil
+}
+
+func isXMLSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
@@ -0,0 +1,132 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePatch(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a x="1" y="2"><b>1</b><c/><b>2</b><d>text</d></a>`
+	xmlSample2 := `<a x="3" z="4"><b>1</b><b>5</b><e/><d/></a>`
+
+	patch, err := GeneratePatch(xmlSample1, xmlSample2)
+	assertT.Nil(err)
+	assertT.Equal(`<diff>
+  <replace sel="/a/@x">3</replace>
+  <remove sel="/a/@y"/>
+  <add sel="/a" type="@z">4</add>
+  <remove sel="/a/d[1]/text()"/>
+  <add sel="/a/b[2]" pos="after"><e/></add>
+  <replace sel="/a/b[2]/text()">5</replace>
+  <remove sel="/a/c[1]"/>
+</diff>`, patch)
+}
+
+func TestGeneratePatchEdgeCases(t *testing.T) {
+	assertT := assert.New(t)
+
+	patch, _ := GeneratePatch(`<a/>`, `<a/>`)
+	assertT.Equal("<diff>\n</diff>", patch)
+
+	patch, _ = GeneratePatch(`<a><b/></a>`, `<r><b/></r>`)
+	assertT.Equal("<diff>\n  <replace sel=\"/a\"><r><b/></r></replace>\n</diff>", patch)
+
+	patch, _ = GeneratePatch(`<a><b/></a>`, `<a><c>&lt;</c><b/></a>`)
+	assertT.Equal("<diff>\n  <add sel=\"/a\" pos=\"prepend\"><c>&lt;</c></add>\n</diff>", patch)
+
+	patch, _ = GeneratePatch(`<a>x<b/></a>`, `<a>y<b/></a>`)
+	assertT.Equal("<diff>\n  <replace sel=\"/a\"><a>y<b/></a></replace>\n</diff>", patch)
+
+	patch, _ = GeneratePatch(`<a xmlns:n="urn:n"><n:b n:x="1"/></a>`, `<a xmlns:n="urn:n"><n:b n:x="2"/><n:c/></a>`)
+	assertT.Equal("<diff xmlns:n=\"urn:n\">\n  <add sel=\"/a/*[1]\" pos=\"after\"><n:c/></add>\n"+
+		"  <replace sel=\"/a/*[1]/@*[local-name()='x']\">2</replace>\n</diff>", patch)
+
+	_, err := GeneratePatch(`<a>`, `<a/>`)
+	assertT.NotNil(err)
+	_, err = GeneratePatch(`<a/>`, `bogus`)
+	assertT.NotNil(err)
+}
+
+func TestApplyPatch(t *testing.T) {
+	assertT := assert.New(t)
+
+	doc := `<?xml version="1.0"?>
+<a x="1" y='2'>
+  <b>1</b>
+  <c/>
+  <b>2</b>
+  <d>text</d>
+</a>`
+	patch := `<diff>
+  <replace sel="/a/@x">3</replace>
+  <remove sel="/a/@y"/>
+  <add sel="/a" type="@z">4&amp;5</add>
+  <remove sel="/a/d[1]/text()"/>
+  <add sel="/a/b[2]" pos="after"><e/></add>
+  <replace sel="/a/b[2]/text()">5</replace>
+  <remove sel="/a/c[1]"/>
+  <add sel="/a/c"/>
+</diff>`
+
+	_, err := ApplyPatch(doc, patch)
+	assertT.Equal("selector '/a/c' matches 0 nodes", err.Error())
+
+	patch = strings.Replace(patch, `<add sel="/a/c"/>`, `<add sel="/a/e" pos="prepend">e</add><add sel="/a"><f/></add>`, 1)
+	patched, err := ApplyPatch(doc, patch)
+	assertT.Nil(err)
+	assertT.Equal(`<?xml version="1.0"?>
+<a x="3" z="4&amp;5">
+  <b>1</b>
+  
+  <b>5</b><e>e</e>
+  <d></d>
+<f/></a>`, patched)
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	doc := `<a><b/><b/></a>`
+	tests := []struct {
+		patch string
+		err   string
+	}{
+		{`bogus`, "can't parse the patch: EOF"},
+		{`<patch/>`, "patch root element must be 'diff', got 'patch'"},
+		{`<diff><remove/></diff>`, "operation 'remove' has no selector"},
+		{`<diff><remove sel="/a/b"/></diff>`, "selector '/a/b' matches 2 nodes"},
+		{`<diff><remove sel="/a["/></diff>`, "invalid selector '/a["},
+		{`<diff><remove sel="/a"/></diff>`, "can't remove the root element, selector '/a'"},
+		{`<diff><remove sel="/a/b[1]/@x"/></diff>`, "selector '/a/b[1]/@x' matches 0 nodes"},
+		{`<diff><remove sel="/a/b[1]/text()"/></diff>`, "selector '/a/b[1]/text()' doesn't match a single text node"},
+		{`<diff><add sel="/a" pos="under"/></diff>`, "unsupported position 'under'"},
+		{`<diff><add sel="/a" type="text()"/></diff>`, "unsupported node type 'text()'"},
+		{`<diff><move sel="/a"/></diff>`, "unsupported operation 'move' with selector '/a'"},
+	}
+	for _, test := range tests {
+		_, err := ApplyPatch(doc, test.patch)
+		assertT.ErrorContains(err, test.err)
+	}
+}
+
+func TestPatchRoundTrip(t *testing.T) {
+	assertT := assert.New(t)
+
+	samples := [][2]string{
+		{xmlString1, xmlMixed},
+		{xmlString1, xmlString2},
+		{`<a xmlns:n="urn:n"><n:b n:x="1"/><c y="1"/></a>`, `<a xmlns:n="urn:n"><n:b n:x="2"/><n:c/><c/></a>`},
+		{`<a><b><c>1</c><c>2</c></b></a>`, `<a><b><c>2</c><c>1</c><c>3</c></b><b/></a>`},
+	}
+	for _, sample := range samples {
+		patch, err := GeneratePatch(sample[0], sample[1])
+		assertT.Nil(err)
+		patched, err := ApplyPatch(sample[0], patch)
+		assertT.Nil(err)
+		assertT.Equal(emptyList, Compare(patched, sample[1]).GetMessages(), patch)
+	}
+}
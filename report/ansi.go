@@ -0,0 +1,94 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Usage of ANSI colors in terminal output.
+type ColorMode int
+
+const (
+	// Colors are used when writing to a terminal and the `NO_COLOR` environment variable is not set
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+const (
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// Writes differences one per line - removed in red, added in green and changed in yellow.
+//   - w - destination, e.g. `os.Stdout`
+//   - diffs - structured differences
+//   - mode - usage of colors
+//
+// Returns: error of writing to `w`
+func WriteText(w io.Writer, diffs xmlcomparator.DiffList, mode ColorMode) error {
+	colored := useColors(w, mode)
+
+	var buf strings.Builder
+	for i := range diffs {
+		color, line := describeLine(&diffs[i])
+		if colored {
+			line = color + line + ansiReset
+		}
+		buf.WriteString(line + "\n")
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func useColors(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Describes the difference in a single line.
+//
+// Returns: color of the line and its text
+func describeLine(diff *xmlcomparator.Diff) (string, string) {
+	switch diff.Kind {
+	case xmlcomparator.ElementRemoved:
+		return ansiRed, fmt.Sprintf("- %s <%s>", diff.Path1, diff.Name)
+	case xmlcomparator.ElementAdded:
+		return ansiGreen, fmt.Sprintf("+ %s <%s>", diff.Path2, diff.Name)
+	case xmlcomparator.AttrRemoved:
+		return ansiRed, fmt.Sprintf("- %s @%s='%s'", diff.Path1, diff.Name, diff.Expected)
+	case xmlcomparator.AttrAdded:
+		return ansiGreen, fmt.Sprintf("+ %s @%s='%s'", diff.Path1, diff.Name, diff.Actual)
+	case xmlcomparator.AttrChanged:
+		return ansiYellow, fmt.Sprintf("~ %s @%s: '%s' -> '%s'", diff.Path1, diff.Name, diff.Expected, diff.Actual)
+	case xmlcomparator.ElementMoved:
+		return ansiYellow, fmt.Sprintf("> %s <%s> -> %s", diff.Path1, diff.Name, diff.Path2)
+	case xmlcomparator.OrderChanged:
+		return ansiYellow, fmt.Sprintf("~ %s children order", diff.Path1)
+	case xmlcomparator.ParseFailed:
+		return ansiRed, "! " + diff.Message
+	default:
+		return ansiYellow, fmt.Sprintf("~ %s %s: '%s' -> '%s'", diff.Path1, diff.Kind, diff.Expected, diff.Actual)
+	}
+}
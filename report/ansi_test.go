@@ -0,0 +1,60 @@
+package report
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestWriteText(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := xmlcomparator.Compare(`<a x="1" y="2"><b>1</b><c/></a>`, `<a x="3" z="4"><b>2</b><d/></a>`).GetStructuredDiffs()
+
+	var buf strings.Builder
+	assertT.Nil(WriteText(&buf, diffs, ColorNever))
+	assertT.Equal(`~ /a @x: '1' -> '3'
+- /a @y='2'
++ /a @z='4'
+- /a/c[1] <c>
++ /a/d[1] <d>
+~ /a/b[0] TextChanged: '1' -> '2'
+`, buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteText(&buf, diffs[1:4], ColorAlways))
+	assertT.Equal("\x1b[31m- /a @y='2'\x1b[0m\n\x1b[32m+ /a @z='4'\x1b[0m\n\x1b[31m- /a/c[1] <c>\x1b[0m\n", buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteText(&buf, xmlcomparator.Compare(`<a><b/><c/></a>`, `<a><c/><b/></a>`).GetStructuredDiffs(), ColorAuto))
+	assertT.Equal("~ /a children order\n", buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteText(&buf, xmlcomparator.Compare(`<a><b><c/></b><d/></a>`, `<a><b/><d><c/></d></a>`,
+		xmlcomparator.WithMoveDetection()).GetStructuredDiffs(), ColorNever))
+	assertT.Equal("> /a/b[0]/c <c> -> /a/d[1]/c\n", buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteText(&buf, xmlcomparator.Compare(`<a>`, `<a/>`).GetStructuredDiffs(), ColorNever))
+	assertT.Equal("! Can't parse the first sample: XML syntax error on line 1: unexpected EOF\n", buf.String())
+}
+
+func TestUseColors(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.True(useColors(&strings.Builder{}, ColorAlways))
+	assertT.False(useColors(&strings.Builder{}, ColorAuto))
+
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	assertT.Nil(err)
+	defer file.Close()
+	assertT.False(useColors(file, ColorAuto))
+
+	t.Setenv("NO_COLOR", "1")
+	assertT.False(useColors(os.Stdout, ColorAuto))
+	assertT.True(useColors(os.Stdout, ColorAlways))
+}
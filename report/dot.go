@@ -0,0 +1,148 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+const (
+	dotChanged   = "#ffffbb"
+	dotRemoved   = "#ffdddd"
+	dotAdded     = "#ddffdd"
+	dotTextLimit = 20
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// Writes both documents as a Graphviz DOT graph - matched elements are merged into one node,
+// changed elements are yellow, removed - red and added - green.
+//   - w - destination of the graph
+//   - sample1, sample2 - compared (expected and actual) XML strings
+//   - opts - comparison options
+//
+// Returns: error of writing to `w`
+func WriteDOT(w io.Writer, sample1 string, sample2 string, opts ...xmlcomparator.Option) error {
+	_, err := io.WriteString(w, DOT(sample1, sample2, opts...))
+	return err
+}
+
+// Renders comparison of two XML strings as a Graphviz DOT graph - see `WriteDOT`.
+func DOT(sample1 string, sample2 string, opts ...xmlcomparator.Option) string {
+	cmp := compare(sample1, sample2, opts)
+
+	graph := dotGraph{messages: make(map[*xmlcomparator.Node][]string), removed: make(map[*xmlcomparator.Node]bool),
+		added: make(map[*xmlcomparator.Node]bool)}
+	for i := range cmp.diffs {
+		diff := &cmp.diffs[i]
+		switch diff.Kind {
+		case xmlcomparator.ElementRemoved:
+			graph.removed[diff.Node1] = true
+		case xmlcomparator.ElementAdded:
+			graph.added[diff.Node2] = true
+		default:
+			if diff.Node1 != nil {
+				graph.messages[diff.Node1] = append(graph.messages[diff.Node1], diff.Message)
+			}
+		}
+	}
+
+	graph.buf.WriteString("digraph xml {\n")
+	graph.buf.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=white, fontname=\"Helvetica\"];\n")
+	if cmp.root1 != nil && cmp.root2 != nil {
+		graph.writePair(cmp.root1, cmp.root2)
+	}
+	graph.buf.WriteString("}\n")
+	return graph.buf.String()
+}
+
+type dotGraph struct {
+	buf strings.Builder
+	// Messages of differences of matched elements
+	messages map[*xmlcomparator.Node][]string
+	removed  map[*xmlcomparator.Node]bool
+	added    map[*xmlcomparator.Node]bool
+	count    int
+}
+
+// Writes matched elements with their subtrees.
+//
+// Returns: ID of the graph node
+func (graph *dotGraph) writePair(node1 *xmlcomparator.Node, node2 *xmlcomparator.Node) string {
+	id := graph.writeNode(node1, "", graph.messages[node1])
+
+	// Unmatched children of both elements are paired by names in the order of appearance
+	pending := make(map[string][]*xmlcomparator.Node)
+	for i := range node2.Children {
+		child := &node2.Children[i]
+		if !graph.added[child] {
+			pending[child.XMLName.Local] = append(pending[child.XMLName.Local], child)
+		}
+	}
+	for i := range node1.Children {
+		child := &node1.Children[i]
+		candidates := pending[child.XMLName.Local]
+		if graph.removed[child] || len(candidates) == 0 {
+			graph.writeEdge(id, graph.writeSubtree(child, dotRemoved))
+			continue
+		}
+		pending[child.XMLName.Local] = candidates[1:]
+		graph.writeEdge(id, graph.writePair(child, candidates[0]))
+	}
+	for i := range node2.Children {
+		child := &node2.Children[i]
+		if graph.added[child] || containsNode(pending[child.XMLName.Local], child) {
+			graph.writeEdge(id, graph.writeSubtree(child, dotAdded))
+		}
+	}
+
+	return id
+}
+
+// Writes the unmatched subtree in the same color.
+func (graph *dotGraph) writeSubtree(node *xmlcomparator.Node, color string) string {
+	id := graph.writeNode(node, color, nil)
+	for i := range node.Children {
+		graph.writeEdge(id, graph.writeSubtree(&node.Children[i], color))
+	}
+	return id
+}
+
+func (graph *dotGraph) writeNode(node *xmlcomparator.Node, color string, messages []string) string {
+	graph.count++
+	id := fmt.Sprintf("n%d", graph.count)
+
+	label := node.XMLName.Local
+	if text := strings.TrimSpace(node.CharData); text != "" && len(node.Children) == 0 {
+		if len(text) > dotTextLimit {
+			text = text[:dotTextLimit] + "..."
+		}
+		label += "\n" + text
+	}
+
+	attrs := `label="` + dotEscaper.Replace(label) + `"`
+	if len(messages) != 0 {
+		color = dotChanged
+		attrs += `, tooltip="` + dotEscaper.Replace(strings.Join(messages, "\n")) + `"`
+	}
+	if color != "" {
+		attrs += `, fillcolor="` + color + `"`
+	}
+	fmt.Fprintf(&graph.buf, "  %s [%s];\n", id, attrs)
+	return id
+}
+
+func (graph *dotGraph) writeEdge(from string, to string) {
+	fmt.Fprintf(&graph.buf, "  %s -> %s;\n", from, to)
+}
+
+func containsNode(nodes []*xmlcomparator.Node, node *xmlcomparator.Node) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
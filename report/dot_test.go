@@ -0,0 +1,34 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDOT(t *testing.T) {
+	assertT := assert.New(t)
+
+	graph := DOT(`<a><b x="1">"t"</b><c><d/></c></a>`, `<a><b x="2">"t"</b><e/></a>`)
+	assertT.Equal(`digraph xml {
+  node [shape=box, style="rounded,filled", fillcolor=white, fontname="Helvetica"];
+  n1 [label="a"];
+  n2 [label="b\n\"t\"", tooltip="Attributes differ: 'x=1' vs 'x=2', path='/a/b[0]'", fillcolor="#ffffbb"];
+  n1 -> n2;
+  n3 [label="c", fillcolor="#ffdddd"];
+  n4 [label="d", fillcolor="#ffdddd"];
+  n3 -> n4;
+  n1 -> n3;
+  n5 [label="e", fillcolor="#ddffdd"];
+  n1 -> n5;
+}
+`, graph)
+
+	var buf strings.Builder
+	assertT.Nil(WriteDOT(&buf, `<a><b>0123456789012345678901</b></a>`, `<a><b>0123456789012345678901</b></a>`))
+	assertT.Contains(buf.String(), `n2 [label="b\n01234567890123456789..."];`)
+
+	graph = DOT(`<a>`, `<a/>`)
+	assertT.Equal("digraph xml {\n  node [shape=box, style=\"rounded,filled\", fillcolor=white, fontname=\"Helvetica\"];\n}\n", graph)
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+const htmlStyle = `body { font-family: sans-serif; margin: 1em; }
+table.docs { border-collapse: collapse; width: 100%; table-layout: fixed; }
+table.docs td, table.docs th { border: 1px solid #ccc; vertical-align: top; padding: 0.5em; }
+pre { margin: 0; white-space: pre-wrap; }
+.line { display: block; }
+.line:target, li:target { outline: 2px solid #36c; }
+.removed { background: #fdd; }
+.added { background: #dfd; }
+.changed { background: #ffb; }
+a { color: inherit; }`
+
+// Renders comparison of two XML strings as a standalone HTML page with both documents side by side.
+// Differing elements are highlighted and linked to the list of differences.
+//   - w - destination of the page
+//   - sample1, sample2 - compared (expected and actual) XML strings
+//   - opts - comparison options
+//
+// Returns: error of writing to `w`
+func WriteHTML(w io.Writer, sample1 string, sample2 string, opts ...xmlcomparator.Option) error {
+	_, err := io.WriteString(w, HTML(sample1, sample2, opts...))
+	return err
+}
+
+// Renders comparison of two XML strings as a standalone HTML page - see `WriteHTML`.
+func HTML(sample1 string, sample2 string, opts ...xmlcomparator.Option) string {
+	cmp := compare(sample1, sample2, opts)
+
+	// Differences of each node
+	diffs1 := make(map[*xmlcomparator.Node][]int)
+	diffs2 := make(map[*xmlcomparator.Node][]int)
+	for i := range cmp.diffs {
+		if cmp.diffs[i].Node1 != nil {
+			diffs1[cmp.diffs[i].Node1] = append(diffs1[cmp.diffs[i].Node1], i)
+		}
+		if cmp.diffs[i].Node2 != nil {
+			diffs2[cmp.diffs[i].Node2] = append(diffs2[cmp.diffs[i].Node2], i)
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>XML comparison</title>\n")
+	buf.WriteString("<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n<h1>XML comparison</h1>\n")
+	fmt.Fprintf(&buf, "<p>%d difference(s)</p>\n", len(cmp.diffs))
+
+	buf.WriteString("<table class=\"docs\">\n<tr><th>Expected</th><th>Actual</th></tr>\n<tr>\n")
+	lineIds1 := writeHTMLDocument(&buf, cmp.root1, "l", diffs1, cmp.diffs, "removed")
+	lineIds2 := writeHTMLDocument(&buf, cmp.root2, "r", diffs2, cmp.diffs, "added")
+	buf.WriteString("</tr>\n</table>\n")
+
+	buf.WriteString("<h2>Differences</h2>\n<ol>\n")
+	for i := range cmp.diffs {
+		diff := &cmp.diffs[i]
+		fmt.Fprintf(&buf, "<li id=\"d-%d\"><b>%s</b> ", i+1, diff.Kind)
+		writeHTMLLink(&buf, lineIds1[diff.Node1], diff.Path1)
+		writeHTMLLink(&buf, lineIds2[diff.Node2], diff.Path2)
+		fmt.Fprintf(&buf, "<br>%s</li>\n", html.EscapeString(diff.Message))
+	}
+	buf.WriteString("</ol>\n</body>\n</html>\n")
+
+	return buf.String()
+}
+
+// Writes the document cell.
+//
+// Returns: ids of lines with differing elements
+func writeHTMLDocument(buf *strings.Builder, root *xmlcomparator.Node, prefix string, nodeDiffs map[*xmlcomparator.Node][]int,
+	diffs xmlcomparator.DiffList, missingClass string) map[*xmlcomparator.Node]string {
+	lineIds := make(map[*xmlcomparator.Node]string)
+
+	buf.WriteString("<td><pre>")
+	for i, line := range formatLines(root, "") {
+		indices, ok := nodeDiffs[line.node]
+		if line.node == nil || !ok {
+			buf.WriteString("<span class=\"line\">" + html.EscapeString(line.text) + "</span>")
+			continue
+		}
+
+		id := fmt.Sprintf("%s-%d", prefix, i+1)
+		lineIds[line.node] = id
+		class := "changed"
+		kind := diffs[indices[0]].Kind
+		if kind == xmlcomparator.ElementRemoved || kind == xmlcomparator.ElementAdded {
+			class = missingClass
+		}
+		titles := make([]string, 0, len(indices))
+		for _, idx := range indices {
+			titles = append(titles, diffs[idx].Message)
+		}
+		fmt.Fprintf(buf, "<a class=\"line %s\" id=\"%s\" href=\"#d-%d\" title=\"%s\">%s</a>", class, id, indices[0]+1,
+			html.EscapeString(strings.Join(titles, "\n")), html.EscapeString(line.text))
+	}
+	buf.WriteString("</pre></td>\n")
+
+	return lineIds
+}
+
+func writeHTMLLink(buf *strings.Builder, id string, path string) {
+	if path == "" {
+		return
+	}
+	if id == "" {
+		buf.WriteString("<code>" + html.EscapeString(path) + "</code> ")
+		return
+	}
+	fmt.Fprintf(buf, "<a href=\"#%s\"><code>%s</code></a> ", id, html.EscapeString(path))
+}
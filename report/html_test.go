@@ -0,0 +1,39 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestHTML(t *testing.T) {
+	assertT := assert.New(t)
+
+	page := HTML(`<a><b x="1">t&lt;</b><c/></a>`, `<a><b x="2">t&lt;</b><d/></a>`)
+
+	assertT.True(strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assertT.Contains(page, "<p>3 difference(s)</p>")
+	assertT.Contains(page, `<a class="line changed" id="l-2" href="#d-3" title="Attributes differ: &#39;x=1&#39; vs &#39;x=2&#39;, path=&#39;/a/b[0]&#39;">  &lt;b x=&#34;1&#34;&gt;t&amp;lt;&lt;/b&gt;</a>`)
+	assertT.Contains(page, `<a class="line removed" id="l-3" href="#d-1"`)
+	assertT.Contains(page, `<a class="line added" id="r-3" href="#d-2"`)
+	assertT.Contains(page, `<li id="d-1"><b>ElementRemoved</b> <a href="#l-3"><code>/a/c[1]</code></a> <br>`)
+	assertT.Contains(page, `<li id="d-3"><b>AttrChanged</b> <a href="#l-2"><code>/a/b[0]</code></a> <a href="#r-2"><code>/a/b[0]</code></a> <br>`)
+	assertT.Contains(page, `<span class="line">&lt;/a&gt;</span>`)
+}
+
+func TestHTMLWithoutDifferences(t *testing.T) {
+	assertT := assert.New(t)
+
+	var buf bytes.Buffer
+	assertT.Nil(WriteHTML(&buf, `<a><b/></a>`, `<a><c/></a>`, xmlcomparator.WithIgnoredPaths("/a/b", "/a/c")))
+	assertT.Contains(buf.String(), "<p>0 difference(s)</p>")
+	assertT.Contains(buf.String(), `<span class="line">  &lt;c/&gt;</span>`)
+
+	page := HTML(`<a>`, `<a/>`)
+	assertT.Contains(page, "<p>1 difference(s)</p>")
+	assertT.Contains(page, "<li id=\"d-1\"><b>ParseFailed</b> <br>Can&#39;t parse the first sample: XML syntax error on line 1: unexpected EOF</li>")
+}
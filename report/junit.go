@@ -0,0 +1,118 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Result of comparison of one pair of documents, e.g. files.
+type CaseResult struct {
+	// Name of the test case, e.g. the file name
+	Name  string
+	Diffs xmlcomparator.DiffList
+	// Duration of the comparison
+	Elapsed time.Duration
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Writes comparison results as a JUnit XML report - every compared pair of documents is a test case.
+// A case fails when it has differences with `SeverityError`; parsing errors are reported as errors,
+// and less significant differences are listed in the case output.
+//   - w - destination of the report
+//   - suite - name of the test suite
+//   - results - results of comparisons
+//
+// Returns: error of writing to `w`
+func WriteJUnit(w io.Writer, suite string, results []CaseResult) error {
+	report := junitSuite{Name: suite, Tests: len(results), Cases: make([]junitCase, 0, len(results))}
+	var elapsed time.Duration
+	for i := range results {
+		testCase := junitTestCase(suite, &results[i])
+		if testCase.Failure != nil {
+			report.Failures++
+		}
+		if testCase.Error != nil {
+			report.Errors++
+		}
+		report.Cases = append(report.Cases, testCase)
+		elapsed += results[i].Elapsed
+	}
+	report.Time = formatSeconds(elapsed)
+
+	data, err := xml.MarshalIndent(junitSuites{Tests: report.Tests, Failures: report.Failures, Errors: report.Errors,
+		Suites: []junitSuite{report}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	return err
+}
+
+func junitTestCase(suite string, result *CaseResult) junitCase {
+	testCase := junitCase{Name: result.Name, ClassName: suite, Time: formatSeconds(result.Elapsed)}
+
+	var failures, notes, parseErrors []string
+	for i := range result.Diffs {
+		diff := &result.Diffs[i]
+		_, line := describeLine(diff)
+		switch {
+		case diff.Kind == xmlcomparator.ParseFailed:
+			parseErrors = append(parseErrors, diff.Message)
+		case diff.Severity == xmlcomparator.SeverityError:
+			failures = append(failures, line)
+		default:
+			notes = append(notes, diff.Severity.String()+": "+line)
+		}
+	}
+
+	if len(parseErrors) != 0 {
+		testCase.Error = &junitProblem{Message: parseErrors[0], Type: xmlcomparator.ParseFailed.String(),
+			Text: strings.Join(parseErrors, "\n")}
+	}
+	if len(failures) != 0 {
+		testCase.Failure = &junitProblem{Message: fmt.Sprintf("%d difference(s)", len(failures)), Type: "XMLDifference",
+			Text: strings.Join(failures, "\n")}
+	}
+	testCase.SystemOut = strings.Join(notes, "\n")
+	return testCase
+}
+
+func formatSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	assertT := assert.New(t)
+
+	results := []CaseResult{
+		{Name: "same.xml", Diffs: xmlcomparator.Compare(`<a/>`, `<a/>`).GetStructuredDiffs(), Elapsed: 1500 * time.Microsecond},
+		{Name: "changed.xml", Diffs: xmlcomparator.Compare(`<a x="1"><b>1</b></a>`, `<a x="2"><b>2</b></a>`,
+			xmlcomparator.WithSeverity(xmlcomparator.SeverityInfo, "/a", xmlcomparator.AttrChanged)).GetStructuredDiffs()},
+		{Name: "broken.xml", Diffs: xmlcomparator.Compare(`<a>`, `<a/>`).GetStructuredDiffs()},
+	}
+
+	var buf strings.Builder
+	assertT.Nil(WriteJUnit(&buf, "samples", results))
+	assertT.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" errors="1">
+  <testsuite name="samples" tests="3" failures="1" errors="1" time="0.002">
+    <testcase name="same.xml" classname="samples" time="0.002"></testcase>
+    <testcase name="changed.xml" classname="samples" time="0.000">
+      <failure message="1 difference(s)" type="XMLDifference">~ /a/b TextChanged: &#39;1&#39; -&gt; &#39;2&#39;</failure>
+      <system-out>Info: ~ /a @x: &#39;1&#39; -&gt; &#39;2&#39;</system-out>
+    </testcase>
+    <testcase name="broken.xml" classname="samples" time="0.000">
+      <error message="Can&#39;t parse the first sample: XML syntax error on line 1: unexpected EOF" type="ParseFailed">Can&#39;t parse the first sample: XML syntax error on line 1: unexpected EOF</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
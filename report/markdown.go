@@ -0,0 +1,78 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "`", "\\`")
+
+// Writes differences as a Markdown report suitable for pull request comments - a table of differences
+// in a collapsible section per subtree.
+//   - w - destination of the report
+//   - title - heading of the report
+//   - diffs - structured differences
+//   - depth - depth of subtree roots - see `DiffList.GroupByPath`
+//
+// Returns: error of writing to `w`
+func WriteMarkdown(w io.Writer, title string, diffs xmlcomparator.DiffList, depth int) error {
+	var buf strings.Builder
+	buf.WriteString("### " + title + "\n\n")
+	if len(diffs) == 0 {
+		buf.WriteString("No differences\n")
+		_, err := io.WriteString(w, buf.String())
+		return err
+	}
+
+	errors := 0
+	for i := range diffs {
+		if diffs[i].Severity == xmlcomparator.SeverityError {
+			errors++
+		}
+	}
+	fmt.Fprintf(&buf, "**%d difference(s)**, %d error(s)\n", len(diffs), errors)
+
+	for _, group := range diffs.GroupByPath(depth) {
+		summary := "Document"
+		if group.Path != "" {
+			summary = "<code>" + html.EscapeString(group.Path) + "</code>"
+		}
+		fmt.Fprintf(&buf, "\n<details>\n<summary>%s - %d difference(s)</summary>\n\n", summary, len(group.Diffs))
+		buf.WriteString("| Code | Kind | Severity | Path | Expected | Actual |\n|---|---|---|---|---|---|\n")
+		for i := range group.Diffs {
+			diff := &group.Diffs[i]
+			fmt.Fprintf(&buf, "| %s | %s | %s | %s | %s | %s |\n", diff.Kind.Code(), diff.Kind, diff.Severity,
+				markdownPath(diff), markdownCell(diff.Expected), markdownCell(diff.Actual))
+		}
+		buf.WriteString("\n</details>\n")
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// Path of the difference - in the second sample for added elements, with the attribute name for attributes.
+func markdownPath(diff *xmlcomparator.Diff) string {
+	path := diff.Path1
+	if path == "" {
+		path = diff.Path2
+	}
+	switch diff.Kind {
+	case xmlcomparator.AttrAdded, xmlcomparator.AttrRemoved, xmlcomparator.AttrChanged:
+		path += "/@" + diff.Name
+	case xmlcomparator.ParseFailed:
+		return markdownCell(diff.Message)
+	}
+	if path == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(path, "`", "'") + "`"
+}
+
+func markdownCell(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := xmlcomparator.Compare(`<a><b x="1">t|1</b><c><d/></c></a>`, "<a><b x=\"2\">t\n2</b><c><e/></c></a>",
+		xmlcomparator.WithSeverity(xmlcomparator.SeverityWarning, "/a/c")).GetStructuredDiffs()
+
+	var buf strings.Builder
+	assertT.Nil(WriteMarkdown(&buf, "Contract changes", diffs, 2))
+	assertT.Equal("### Contract changes\n\n**4 difference(s)**, 2 error(s)\n"+`
+<details>
+<summary><code>/a/b[0]</code> - 2 difference(s)</summary>
+
+| Code | Kind | Severity | Path | Expected | Actual |
+|---|---|---|---|---|---|
+| XC002 | TextChanged | Error | `+"`/a/b[0]`"+` | t\|1 | t<br>2 |
+| XC008 | AttrChanged | Error | `+"`/a/b[0]/@x`"+` | 1 | 2 |
+
+</details>
+
+<details>
+<summary><code>/a/c[1]</code> - 2 difference(s)</summary>
+
+| Code | Kind | Severity | Path | Expected | Actual |
+|---|---|---|---|---|---|
+| XC001 | ElementRemoved | Warning | `+"`/a/c[1]/d`"+` |  |  |
+| XC003 | ElementAdded | Warning | `+"`/a/c[1]/e`"+` |  |  |
+
+</details>
+`, buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteMarkdown(&buf, "Same", xmlcomparator.DiffList{}, 2))
+	assertT.Equal("### Same\n\nNo differences\n", buf.String())
+
+	buf.Reset()
+	assertT.Nil(WriteMarkdown(&buf, "Broken", xmlcomparator.Compare(`<a>`, `<a/>`).GetStructuredDiffs(), 2))
+	assertT.Contains(buf.String(), "<summary>Document - 1 difference(s)</summary>")
+	assertT.Contains(buf.String(), "| XC010 | ParseFailed | Error | Can't parse the first sample: XML syntax error on line 1: unexpected EOF |  |  |")
+}
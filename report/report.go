@@ -0,0 +1,99 @@
+// Package report renders results of XML comparison in human- and machine-readable formats.
+package report
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+const indentUnit = "  "
+
+// Compared documents with their differences.
+type comparison struct {
+	root1 *xmlcomparator.Node
+	root2 *xmlcomparator.Node
+	diffs xmlcomparator.DiffList
+}
+
+// Compares samples and resolves roots of both documents.
+func compare(sample1 string, sample2 string, opts []xmlcomparator.Option) comparison {
+	diffs := xmlcomparator.Compare(sample1, sample2, opts...).GetStructuredDiffs()
+	ret := comparison{diffs: diffs}
+
+	// Nodes of differences belong to the compared trees
+	for i := range diffs {
+		if ret.root1 == nil && diffs[i].Node1 != nil {
+			ret.root1 = rootOf(diffs[i].Node1)
+		}
+		if ret.root2 == nil && diffs[i].Node2 != nil {
+			ret.root2 = rootOf(diffs[i].Node2)
+		}
+	}
+	if ret.root1 == nil {
+		ret.root1, _ = xmlcomparator.UnmarshalXMLString(sample1)
+	}
+	if ret.root2 == nil {
+		ret.root2, _ = xmlcomparator.UnmarshalXMLString(sample2)
+	}
+	return ret
+}
+
+func rootOf(node *xmlcomparator.Node) *xmlcomparator.Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node
+}
+
+// Line of the formatted document.
+type docLine struct {
+	text string
+	// Element which start tag is on the line, `nil` for text and end tags
+	node *xmlcomparator.Node
+}
+
+// Formats the element with its subtree one element per line with sorted attributes.
+func formatLines(node *xmlcomparator.Node, indent string) []docLine {
+	if node == nil {
+		return []docLine{}
+	}
+
+	startTag := "<" + node.XMLName.Local
+	attrs := make([]xml.Attr, 0, len(node.Attrs))
+	for _, attr := range node.Attrs {
+		if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name.Local < attrs[j].Name.Local })
+	for _, attr := range attrs {
+		startTag += " " + attr.Name.Local + `="` + escape(attr.Value) + `"`
+	}
+
+	text := escape(strings.TrimSpace(node.CharData))
+	endTag := "</" + node.XMLName.Local + ">"
+	switch {
+	case len(node.Children) == 0 && text == "":
+		return []docLine{{indent + startTag + "/>", node}}
+	case len(node.Children) == 0:
+		return []docLine{{indent + startTag + ">" + text + endTag, node}}
+	}
+
+	lines := []docLine{{indent + startTag + ">", node}}
+	if text != "" {
+		lines = append(lines, docLine{indent + indentUnit + text, nil})
+	}
+	for i := range node.Children {
+		lines = append(lines, formatLines(&node.Children[i], indent+indentUnit)...)
+	}
+	return append(lines, docLine{indent + endTag, nil})
+}
+
+func escape(text string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
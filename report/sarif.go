@@ -0,0 +1,157 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "xmlcomparator"
+	toolURI      = "https://github.com/aknopov/xmlcomparator"
+)
+
+// Differences between two files.
+type ComparedFiles struct {
+	// URIs of the expected and actual files, e.g. relative paths in the repository
+	File1 string
+	File2 string
+	Diffs xmlcomparator.DiffList
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+var sarifLevels = map[xmlcomparator.Severity]string{
+	xmlcomparator.SeverityError:   "error",
+	xmlcomparator.SeverityWarning: "warning",
+	xmlcomparator.SeverityInfo:    "note",
+}
+
+// Writes differences as a SARIF 2.1.0 log for code-scanning UIs.
+// Results are located by line and column in the actual (second) file, or in the expected one for removed nodes;
+// rule IDs are stable codes of diff kinds and levels follow severities.
+//   - w - destination of the log
+//   - comparisons - differences of compared files
+//
+// Returns: error of writing to `w`
+func WriteSARIF(w io.Writer, comparisons []ComparedFiles) error {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: toolName, InformationURI: toolURI, Rules: make([]sarifRule, 0)}},
+		Results: make([]sarifResult, 0)}
+
+	kinds := make(map[xmlcomparator.DiffKind]bool)
+	for _, comparison := range comparisons {
+		for i := range comparison.Diffs {
+			diff := &comparison.Diffs[i]
+			kinds[diff.Kind] = true
+			run.Results = append(run.Results, sarifResultOf(&comparison, diff))
+		}
+	}
+
+	for kind := range kinds {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: kind.Code(), Name: kind.String(),
+			ShortDescription: sarifMessage{Text: kind.String()}})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool { return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID })
+
+	data, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func sarifResultOf(comparison *ComparedFiles, diff *xmlcomparator.Diff) sarifResult {
+	result := sarifResult{RuleID: diff.Kind.Code(), Level: sarifLevels[diff.Severity], Message: sarifMessage{Text: diff.Message}}
+
+	actual := sarifLocationOf(comparison.File2, diff.Path2, diff.Pos2)
+	expected := sarifLocationOf(comparison.File1, diff.Path1, diff.Pos1)
+	switch {
+	case diff.Path2 != "" || diff.Path1 == "":
+		result.Locations = []sarifLocation{actual}
+		if diff.Path1 != "" {
+			expected.ID = 1
+			result.RelatedLocations = []sarifLocation{expected}
+		}
+	default:
+		result.Locations = []sarifLocation{expected}
+	}
+	return result
+}
+
+func sarifLocationOf(file string, path string, pos xmlcomparator.Position) sarifLocation {
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}}}
+	if pos.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column}
+	}
+	if path != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: path, Kind: "element"}}
+	}
+	return location
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := xmlcomparator.Compare("<a x=\"1\"><b>1</b>\n  <c/></a>", `<a x="2"><b>2</b></a>`,
+		xmlcomparator.WithSeverity(xmlcomparator.SeverityInfo, "/a", xmlcomparator.AttrChanged)).GetStructuredDiffs()
+
+	var buf strings.Builder
+	assertT.Nil(WriteSARIF(&buf, []ComparedFiles{{File1: "expected/a.xml", File2: "actual/a.xml", Diffs: diffs}}))
+
+	var log sarifLog
+	assertT.Nil(json.Unmarshal([]byte(buf.String()), &log))
+	assertT.Equal("2.1.0", log.Version)
+	assertT.Equal(1, len(log.Runs))
+
+	run := log.Runs[0]
+	assertT.Equal("xmlcomparator", run.Tool.Driver.Name)
+	assertT.Equal([]string{"XC001", "XC002", "XC008"},
+		[]string{run.Tool.Driver.Rules[0].ID, run.Tool.Driver.Rules[1].ID, run.Tool.Driver.Rules[2].ID})
+	assertT.Equal("ElementRemoved", run.Tool.Driver.Rules[0].Name)
+
+	assertT.Equal(3, len(run.Results))
+	results := make(map[string]sarifResult)
+	for _, result := range run.Results {
+		results[result.RuleID] = result
+	}
+	removed := results["XC001"]
+	assertT.Equal("XC001", removed.RuleID)
+	assertT.Equal("error", removed.Level)
+	assertT.Equal("expected/a.xml", removed.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assertT.Equal("/a/c[1]", removed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assertT.Nil(removed.RelatedLocations)
+	assertT.Equal(&sarifRegion{StartLine: 2, StartColumn: 3}, removed.Locations[0].PhysicalLocation.Region)
+
+	changed := results["XC008"]
+	assertT.Equal("XC008", changed.RuleID)
+	assertT.Equal("note", changed.Level)
+	assertT.Equal("actual/a.xml", changed.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assertT.Equal("/a", changed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assertT.Equal("expected/a.xml", changed.RelatedLocations[0].PhysicalLocation.ArtifactLocation.URI)
+	assertT.Equal("Attributes differ: 'x=1' vs 'x=2', path='/a'", changed.Message.Text)
+
+	buf.Reset()
+	assertT.Nil(WriteSARIF(&buf, []ComparedFiles{{File1: "a.xml", File2: "b.xml", Diffs: xmlcomparator.Compare(`<a>`, `<a/>`).GetStructuredDiffs()}}))
+	assertT.Contains(buf.String(), `"ruleId": "XC010"`)
+	assertT.Contains(buf.String(), `"uri": "b.xml"`)
+	assertT.NotContains(buf.String(), `"region"`)
+}
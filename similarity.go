@@ -0,0 +1,77 @@
+package xmlcomparator
+
+import "fmt"
+
+// Estimates similarity of two XML strings as a number in the range [0, 1], e.g. 0.97 for near-duplicates.
+// Every element has a unit weight shared between its name, own text and attributes; children are matched regardless of order.
+// The score is the doubled weight of matched elements divided by the total count of elements in both samples.
+//   - sample1, sample2 - XML strings to compare
+//   - opts - comparison options; ignored elements don't contribute to the score
+//
+// Returns: similarity score and error if samples can't be parsed
+func Similarity(sample1 string, sample2 string, opts ...Option) (float64, error) {
+	root1, err := parseXML(sample1)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse the first sample: %w", err)
+	}
+	root2, err := parseXML(sample2)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse the second sample: %w", err)
+	}
+
+	recorder := createDiffRecorderEx(newCompareOptions(opts))
+	recorder.selectIgnoredNodes(root1)
+	recorder.selectIgnoredNodes(root2)
+
+	total := recorder.countElements(root1) + recorder.countElements(root2)
+	if total == 0 {
+		return 1.0, nil
+	}
+	if recorder.isIgnoredNode(root1) || recorder.isIgnoredNode(root2) {
+		return 0, nil
+	}
+	return 2 * recorder.matchedWeight(root1, root2) / float64(total), nil
+}
+
+// Weight of matched elements of two subtrees.
+func (recorder *diffRecorder) matchedWeight(node1 *Node, node2 *Node) float64 {
+	if identicalSubtrees(node1, node2) {
+		return float64(recorder.countElements(node1))
+	}
+
+	weight := ownSimilarity(node1, node2)
+	_, indices1 := recorder.selectChildren(node1)
+	_, indices2 := recorder.selectChildren(node2)
+	pairs, _, _ := matchUnordered(node1, node2, indices1, indices2, recorder.opts.elementKey, recorder.opts.strategy)
+	for _, pair := range pairs {
+		weight += recorder.matchedWeight(&node1.Children[pair.idx1], &node2.Children[pair.idx2])
+	}
+	return weight
+}
+
+// Similarity of elements without children in the range [0, 1].
+func ownSimilarity(node1 *Node, node2 *Node) float64 {
+	if nodeName(node1) != nodeName(node2) {
+		return 0
+	}
+
+	// Name and text
+	matched, total := 1, 2
+	if trimmedText(node1) == trimmedText(node2) {
+		matched++
+	}
+
+	attrs2 := make(map[string]string)
+	for _, attr := range node2.extractAttributes() {
+		attrs2[attrQName(&attr)] = attr.Value
+	}
+	attrs1 := node1.extractAttributes()
+	total += max(len(attrs1), len(attrs2))
+	for _, attr := range attrs1 {
+		if value, ok := attrs2[attrQName(&attr)]; ok && value == attr.Value {
+			matched++
+		}
+	}
+
+	return float64(matched) / float64(total)
+}
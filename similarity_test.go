@@ -0,0 +1,37 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	assertT := assert.New(t)
+
+	score, err := Similarity(xmlString1, xmlString1)
+	assertT.Nil(err)
+	assertT.Equal(1.0, score)
+
+	score, _ = Similarity(xmlString1, xmlMixed)
+	assertT.Greater(score, 0.7)
+	assertT.Less(score, 1.0)
+
+	// 5 matched elements of 5 and 6
+	score, _ = Similarity(`<a><b/><c/><d/><e/></a>`, `<a><e/><b/><d/><f/><c/></a>`)
+	assertT.InDelta(2*5.0/11.0, score, eps)
+
+	score, _ = Similarity(`<a x="1">t</a>`, `<a x="2">t</a>`)
+	assertT.InDelta(2.0/3.0, score, eps)
+
+	score, _ = Similarity(`<a/>`, `<b/>`)
+	assertT.Equal(0.0, score)
+
+	score, _ = Similarity(`<a><b/><c>1</c></a>`, `<a><b/><c>2</c></a>`, WithIgnoredPaths("/a/c"))
+	assertT.Equal(1.0, score)
+
+	_, err = Similarity(`<a>`, `<a/>`)
+	assertT.ErrorContains(err, "can't parse the first sample")
+	_, err = Similarity(`<a/>`, `<a>`)
+	assertT.ErrorContains(err, "can't parse the second sample")
+}
@@ -0,0 +1,130 @@
+package xmlcomparator
+
+import "strings"
+
+// Namespace URIs of SOAP envelopes
+const (
+	SOAP11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAP12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Names of WS-Security header elements
+var WSSecurityHeaders = []string{"Security"}
+
+// Names of WS-Addressing header elements
+var WSAddressingHeaders = []string{"Action", "MessageID", "RelatesTo", "To", "From", "ReplyTo", "FaultTo"}
+
+// Option of the SOAP comparison preset.
+type SOAPOption func(*soapSettings)
+
+type soapSettings struct {
+	compareHeader  bool
+	ignoredHeaders []string
+}
+
+// Compares the `<Header>` of envelopes except elements with the names, e.g. `WSSecurityHeaders`.
+// The header is ignored entirely by default.
+//   - names - local names of ignored children of the header
+func SOAPIgnoredHeaders(names ...string) SOAPOption {
+	return func(settings *soapSettings) {
+		settings.compareHeader = true
+		settings.ignoredHeaders = append(settings.ignoredHeaders, names...)
+	}
+}
+
+// Preset for comparing SOAP 1.1 and 1.2 envelopes:
+//   - `<Header>` is ignored, or only its elements set with `SOAPIgnoredHeaders(...)`
+//   - namespaces in `<Body>` are ignored unless `WithStrictNamespaces()` is set - see `WithNamespaceInsensitivePaths`
+//   - a fault in one of bodies, or faults with different codes or reasons, are reported as a single `FaultChanged`
+//     difference like `SOAP faults differ: 'soap:Client: Bad id' vs 'soap:Server: Timeout', path='/Envelope/Body'`
+//     instead of differences of their elements
+//
+// Bodies of envelopes in namespaces other than `SOAP11Namespace` and `SOAP12Namespace` are compared as usual.
+//   - opts - preset options
+func WithSOAP(opts ...SOAPOption) Option {
+	settings := soapSettings{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return func(options *compareOptions) {
+		if !settings.compareHeader {
+			WithIgnoredPaths("/Envelope/Header")(options)
+		}
+		for _, name := range settings.ignoredHeaders {
+			WithIgnoredPaths("/Envelope/Header/" + name)(options)
+		}
+		options.soap = true
+	}
+}
+
+// Records the difference of SOAP faults in bodies of envelopes compared with `WithSOAP()`.
+//
+// Returns: whether bodies have different faults, so their contents are not compared
+func soapFaultsDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	if !diffRecorder.opts.soap || !isSOAPBody(node1) || !isSOAPBody(node2) {
+		return false
+	}
+
+	fault1, found1 := soapFault(node1)
+	fault2, found2 := soapFault(node2)
+	if found1 == found2 && fault1 == fault2 {
+		return false
+	}
+	diffRecorder.addDiff(createTextDiff(DiffFault, fault1, fault2, node1.path(), node1, node2))
+	return true
+}
+
+// Checks whether the node is `<Body>` of a SOAP 1.1 or 1.2 envelope.
+func isSOAPBody(node *Node) bool {
+	if nodeName(node) != "Body" || node.Parent == nil || node.Parent.Parent != nil || nodeName(node.Parent) != "Envelope" {
+		return false
+	}
+	space := nodeSpace(node.Parent)
+	return space == SOAP11Namespace || space == SOAP12Namespace
+}
+
+// Describes the fault in the SOAP body as "code: reason" - `faultcode` and `faultstring` of SOAP 1.1,
+// `Code/Value` and the first `Reason/Text` of SOAP 1.2.
+//
+// Returns: the description and whether the body has a fault
+func soapFault(body *Node) (string, bool) {
+	for i := range body.Children {
+		fault := &body.Children[i]
+		if nodeName(fault) != "Fault" {
+			continue
+		}
+
+		code := childText(fault, "faultcode")
+		if code == "" {
+			code = childText(childElement(fault, "Code"), "Value")
+		}
+		reason := childText(fault, "faultstring")
+		if reason == "" {
+			reason = childText(childElement(fault, "Reason"), "Text")
+		}
+		return strings.TrimPrefix(code+": "+reason, ": "), true
+	}
+	return "", false
+}
+
+// First child with the local name, `nil` if there is none.
+func childElement(node *Node, name string) *Node {
+	if node == nil {
+		return nil
+	}
+	for i := range node.Children {
+		if nodeName(&node.Children[i]) == name {
+			return &node.Children[i]
+		}
+	}
+	return nil
+}
+
+// Trimmed text of the first child with the local name.
+func childText(node *Node, name string) string {
+	if child := childElement(node, name); child != nil {
+		return strings.TrimSpace(child.CharData)
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package xmlcomparator
+
+import (
+	"fmt"
+
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const soapResponse = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+		xmlns:wsa="http://www.w3.org/2005/08/addressing">
+	<soap:Header>
+		<wsa:MessageID>%s</wsa:MessageID>
+		<Tenant>%s</Tenant>
+	</soap:Header>
+	<soap:Body>%s</soap:Body>
+</soap:Envelope>`
+
+func TestSOAPHeader(t *testing.T) {
+	assertT := assert.New(t)
+
+	response1 := fmt.Sprintf(soapResponse, "uuid:1", "acme", `<r:result xmlns:r="urn:v1">ok</r:result>`)
+	response2 := fmt.Sprintf(soapResponse, "uuid:2", "other", `<r:result xmlns:r="urn:v1">ok</r:result>`)
+	assertT.Len(CompareXmlStrings(response1, response2, false), 2)
+	assertT.Empty(Compare(response1, response2, WithSOAP()).GetDiffs())
+	assertT.Equal([]string{"Node texts differ: 'acme' vs 'other', path='/Envelope/Header[0]/Tenant[1]'"},
+		Compare(response1, response2, WithSOAP(SOAPIgnoredHeaders(WSAddressingHeaders...))).GetMessages())
+}
+
+func TestSOAPBodyNamespaces(t *testing.T) {
+	assertT := assert.New(t)
+
+	response1 := fmt.Sprintf(soapResponse, "", "", `<r:result xmlns:r="urn:v1"><r:id>1</r:id></r:result>`)
+	response2 := fmt.Sprintf(soapResponse, "", "", `<r:result xmlns:r="urn:v2"><r:id>2</r:id></r:result>`)
+	assertT.Equal([]string{"Node namespaces differ: 'urn:v1' vs 'urn:v2', path='/Envelope/Body[1]/result'"},
+		Compare(response1, response2, WithStopOnFirst()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '1' vs '2', path='/Envelope/Body[1]/result/id'"},
+		Compare(response1, response2, WithSOAP()).GetMessages())
+
+	// Strict namespaces override the preset
+	assertT.Equal([]string{"Node namespaces differ: 'urn:v1' vs 'urn:v2', path='/Envelope/Body[1]/result'"},
+		Compare(response1, response2, WithSOAP(), WithStrictNamespaces(), WithStopOnFirst()).GetMessages())
+	assertT.Equal([]string{"Node namespaces differ: 'urn:v1' vs 'urn:v2', path='/Envelope/Body[1]/result'"},
+		Compare(response1, response2, WithStrictNamespaces(), WithSOAP(), WithStopOnFirst()).GetMessages())
+
+	// Envelopes in other namespaces are not SOAP ones
+	other1 := `<e:Envelope xmlns:e="urn:envelope"><e:Body><r:id xmlns:r="urn:v1">1</r:id></e:Body></e:Envelope>`
+	other2 := `<e:Envelope xmlns:e="urn:envelope"><e:Body><r:id xmlns:r="urn:v2">2</r:id></e:Body></e:Envelope>`
+	assertT.Equal([]string{"Node namespaces differ: 'urn:v1' vs 'urn:v2', path='/Envelope/Body/id'",
+		"Node texts differ: '1' vs '2', path='/Envelope/Body/id'"}, Compare(other1, other2, WithSOAP()).GetMessages())
+}
+
+func TestSOAPFaults(t *testing.T) {
+	assertT := assert.New(t)
+
+	result := fmt.Sprintf(soapResponse, "", "", `<result>ok</result>`)
+	fault11 := fmt.Sprintf(soapResponse, "", "", `<soap:Fault><faultcode>soap:Server</faultcode>
+		<faultstring>Timeout</faultstring><detail><code>1</code></detail></soap:Fault>`)
+	fault12 := `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>
+		<env:Code><env:Value>env:Sender</env:Value></env:Code>
+		<env:Reason><env:Text xml:lang="en">Bad id</env:Text></env:Reason>
+	</env:Fault></env:Body></env:Envelope>`
+
+	diffs := Compare(result, fault11, WithSOAP()).GetStructuredDiffs()
+	assertT.Len(diffs, 1)
+	assertT.Equal(FaultChanged, diffs[0].Kind)
+	assertT.Equal("XC016", diffs[0].Kind.Code())
+	assertT.Equal("SOAP faults differ: '' vs 'soap:Server: Timeout', path='/Envelope/Body[1]'", diffs[0].Message)
+
+	// Versions of envelopes differ too
+	assertT.Equal([]string{"Node namespaces differ: '" + SOAP11Namespace + "' vs '" + SOAP12Namespace + "', path='/Envelope'",
+		"SOAP faults differ: 'soap:Server: Timeout' vs 'env:Sender: Bad id', path='/Envelope/Body[1]'"},
+		Compare(fault11, fault12, WithSOAP()).GetMessages())
+
+	// Details of equal faults are compared as usual
+	fault11Detail := fmt.Sprintf(soapResponse, "", "", `<soap:Fault><faultcode>soap:Server</faultcode>
+		<faultstring>Timeout</faultstring><detail><code>2</code></detail></soap:Fault>`)
+	assertT.Equal([]string{"Node texts differ: '1' vs '2', path='/Envelope/Body[1]/Fault/detail[2]/code'"},
+		Compare(fault11, fault11Detail, WithSOAP()).GetMessages())
+
+	// Faults in bodies of other envelopes are compared element by element
+	other1 := `<Envelope><Body><result>ok</result></Body></Envelope>`
+	other2 := `<Envelope><Body><Fault><faultcode>Server</faultcode></Fault></Body></Envelope>`
+	assertT.Equal([]string{"Children differ: counts 1 vs 1: result[0]:+1, Fault[0]:-1, path='/Envelope/Body'"},
+		Compare(other1, other2, WithSOAP()).GetMessages())
+}
+
+func TestNamespaceInsensitivePaths(t *testing.T) {
+	assertT := assert.New(t)
+
+	xml1 := `<a xmlns:x="urn:1"><x:b><x:c/></x:b><x:d/></a>`
+	xml2 := `<a xmlns:x="urn:2"><x:b><x:c/></x:b><x:d/></a>`
+	assertT.Equal([]string{"Node namespaces differ: 'urn:1' vs 'urn:2', path='/a/d[1]'"},
+		Compare(xml1, xml2, WithStrictNamespaces(), WithNamespaceInsensitivePaths("/a/b")).GetMessages())
+}
@@ -0,0 +1,447 @@
+package xmlcomparator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Token stream of a compared document.
+type xmlStream struct {
+	dec *xml.Decoder
+	// "first" or "second" - for error messages
+	ordinal string
+}
+
+// Error of reading one of the streams.
+type streamError struct {
+	stream *xmlStream
+	err    error
+}
+
+func (err streamError) Error() string {
+	return "Can't parse the " + err.stream.ordinal + " sample: " + err.err.Error()
+}
+
+func (err streamError) Unwrap() error {
+	return err.err
+}
+
+// Compares XML documents token by token with memory bounded by the largest buffered subtree rather than the document size.
+// Elements down to the depth set with `WithStreamingDepth(depth)` (the root by default) are read as tokens and their
+// children are paired in order; deeper subtrees are buffered one pair at a time and compared as usual. Elements which
+// children are matched regardless of order (`WithIgnoreOrder()`, `WithUnorderedPaths(...)`) or compared as text
+// (`WithMaxDepth(...)`) are buffered entirely.
+//
+// Streamed children are paired in order. When paired children have different names (or keys of `WithKeyAttributes`),
+// up to 8 following children of each document are buffered to realign them, so that an inserted or removed child
+// is reported alone; children that don't realign within the window are reported as removed and added elements.
+// Own text of streamed elements keeps whitespace only between texts, so whitespace of element-only content
+// isn't accumulated unless `WithWhitespace(WhitespaceExact)` is set.
+//
+// Limitations of the streaming mode:
+//   - paths of children of streamed elements always have indices, even for only children
+//   - comments and processing instructions are compared only inside buffered subtrees
+//   - ignored XPath expressions are evaluated against each buffered subtree as if it were a document
+//   - positions, source contexts and `Summary.NodesMatched` are not available
+//
+// Parameters:
+//   - r1 - reader of the first document
+//   - r2 - reader of the second document
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareXmlStreams(r1 io.Reader, r2 io.Reader, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareStreams(r1, r2)
+}
+
+// Compares XML documents token by token - see `CompareXmlStreams`.
+func (cmp *Comparator) CompareStreams(r1 io.Reader, r2 io.Reader) DiffRecorder {
+	diffRecorder := cmp.newRecorder()
+	defer diffRecorder.finish()
+
+	limits := cmp.opts.documentLimits()
+	dec1, release1 := limits.decoder(limits.reader(r1))
+	defer release1()
+	dec2, release2 := limits.decoder(limits.reader(r2))
+	defer release2()
+	stream1 := &xmlStream{dec: dec1, ordinal: "first"}
+	stream2 := &xmlStream{dec: dec2, ordinal: "second"}
+	start1, err := stream1.rootElement(limits)
+	if err != nil {
+		diffRecorder.streamFailed(err)
+		return diffRecorder
+	}
+	start2, err := stream2.rootElement(limits)
+	if err != nil {
+		diffRecorder.streamFailed(err)
+		return diffRecorder
+	}
+
+	if err = streamedElementsDifferent(stream1, stream2, start1, start2, nil, nil, 0, 0, diffRecorder); err != nil {
+		diffRecorder.streamFailed(err)
+	}
+	return diffRecorder
+}
+
+// Records the error of reading streams; `DiffRecorder.Err()` returns an exceeded limit.
+func (recorder *diffRecorder) streamFailed(err error) {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		recorder.limitErr = limitErr
+	}
+	recorder.addDiff(parserError{text: err.Error()})
+}
+
+// Skips the prolog of the document.
+func (stream *xmlStream) rootElement(limits documentLimits) (*xml.StartElement, error) {
+	start, err := readProlog(stream.dec, limits)
+	if err == io.EOF {
+		return nil, streamError{stream, errors.New("no root element")}
+	} else if err != nil {
+		return nil, streamError{stream, err}
+	}
+	return start, nil
+}
+
+// Count of following children buffered to realign streamed children with different keys
+const streamLookahead = 8
+
+// Own text of a streamed element. Whitespace-only texts are kept only between other texts unless whitespace is exact.
+type streamText struct {
+	exact      bool
+	text       strings.Builder
+	whitespace strings.Builder
+}
+
+func (st *streamText) write(data []byte) {
+	switch {
+	case st.exact:
+		st.text.Write(data)
+	case len(bytes.TrimSpace(data)) == 0:
+		if st.text.Len() != 0 {
+			st.whitespace.Write(data)
+		}
+	default:
+		st.text.WriteString(st.whitespace.String())
+		st.whitespace.Reset()
+		st.text.Write(data)
+	}
+}
+
+func (st *streamText) String() string {
+	return st.text.String()
+}
+
+// Children of a streamed element read from the stream and not compared yet.
+type streamedChildren struct {
+	stream *xmlStream
+	parent *Node
+	text   *streamText
+	// Children read ahead; all but the last one are buffered
+	pending []streamedChild
+	// Count of children read so far
+	count int
+	done  bool
+}
+
+// Child of a streamed element.
+type streamedChild struct {
+	start *xml.StartElement
+	// Buffered subtree; `nil` while the stream is positioned after the start tag
+	node  *Node
+	index int
+	key   string
+}
+
+// Reads children up to the one at the position among pending children.
+//
+// Returns: the child or `nil` if the element has fewer children
+func (children *streamedChildren) peek(i int, diffRecorder *diffRecorder) (*streamedChild, error) {
+	for len(children.pending) <= i && !children.done {
+		if n := len(children.pending); n != 0 {
+			if err := children.buffer(n - 1); err != nil {
+				return nil, err
+			}
+		}
+		start, err := children.stream.nextComparedChild(children.parent, &children.count, children.text, diffRecorder)
+		if err != nil {
+			return nil, err
+		}
+		if start == nil {
+			children.done = true
+			break
+		}
+		key := diffRecorder.opts.elementKey(&Node{XMLName: start.Name, Attrs: start.Attr})
+		children.pending = append(children.pending, streamedChild{start: start, index: children.count - 1, key: key})
+	}
+	if i < len(children.pending) {
+		return &children.pending[i], nil
+	}
+	return nil, nil
+}
+
+// Reads the rest of the pending child into its subtree.
+func (children *streamedChildren) buffer(i int) error {
+	child := &children.pending[i]
+	if child.node != nil {
+		return nil
+	}
+	child.node = streamedNode(child.start, children.parent, child.index)
+	return children.stream.bufferSubtree(child.node, child.start)
+}
+
+func (children *streamedChildren) pop() streamedChild {
+	child := children.pending[0]
+	children.pending = children.pending[1:]
+	return child
+}
+
+// Looks for a child with the key among children following the first pending one within the lookahead window.
+//
+// Returns: count of children preceding the found one or 0 if it's not found
+func (children *streamedChildren) find(key string, diffRecorder *diffRecorder) (int, error) {
+	for i := 1; i <= streamLookahead; i++ {
+		child, err := children.peek(i, diffRecorder)
+		if child == nil || err != nil {
+			return 0, err
+		}
+		if child.key == key {
+			return i, nil
+		}
+	}
+	return 0, nil
+}
+
+// Reads content of the current element up to the start of the next child, collecting own text.
+//
+// Returns: start of the child or `nil` at the end of the element
+func (stream *xmlStream) nextChild(text *streamText) (*xml.StartElement, error) {
+	for {
+		token, err := stream.dec.Token()
+		if err != nil {
+			return nil, streamError{stream, err}
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return &t, nil
+		case xml.EndElement:
+			return nil, nil
+		case xml.CharData:
+			text.write(t)
+		}
+	}
+}
+
+// Reads the rest of the element into the subtree of the node.
+func (stream *xmlStream) bufferSubtree(node *Node, start *xml.StartElement) error {
+	if err := stream.dec.DecodeElement(node, start); err != nil {
+		return streamError{stream, err}
+	}
+	node.linkChildren()
+	node.walk(func(n *Node) bool {
+		n.CDATA = n.ownCDATA()
+		return true
+	})
+	node.hashCode()
+	return nil
+}
+
+func (stream *xmlStream) skip() error {
+	if err := stream.dec.Skip(); err != nil {
+		return streamError{stream, err}
+	}
+	return nil
+}
+
+// Element read from the stream; its children are not kept.
+//   - parent - streamed parent or `nil` for the root
+//   - index - position among siblings
+func streamedNode(start *xml.StartElement, parent *Node, index int) *Node {
+	node := &Node{XMLName: start.Name, Attrs: start.Attr, Parent: parent}
+	if parent != nil {
+		node.streamIndex = index + 1
+	}
+	return node
+}
+
+// Compares elements which start tags were just read from the streams; the streams are positioned after their ends then.
+//
+// Returns: error of reading streams, if any
+func streamedElementsDifferent(stream1 *xmlStream, stream2 *xmlStream, start1 *xml.StartElement, start2 *xml.StartElement,
+	parent1 *Node, parent2 *Node, index1 int, index2 int, diffRecorder *diffRecorder) error {
+	node1 := streamedNode(start1, parent1, index1)
+	node2 := streamedNode(start2, parent2, index2)
+	opts := diffRecorder.opts
+
+	if node1.depth() > opts.streamingDepth || opts.isUnordered(node1) || opts.isOpaque(node1) {
+		if err := stream1.bufferSubtree(node1, start1); err != nil {
+			return err
+		}
+		if err := stream2.bufferSubtree(node2, start2); err != nil {
+			return err
+		}
+		bufferedSubtreesDifferent(node1, node2, diffRecorder)
+		return nil
+	}
+
+	// Buffered subtrees are accounted by the decoder
+	if err := spendNode(stream1.dec); err != nil {
+		return streamError{stream1, err}
+	}
+	if err := spendNode(stream2.dec); err != nil {
+		return streamError{stream2, err}
+	}
+
+	diffRecorder.nodesCompared++
+	stopOnFirst := opts.stopOnFirst
+	switch {
+	case nodeNamesDifferent(node1, node2, diffRecorder) && stopOnFirst:
+		return nil
+	case nodeSpacesDifferent(node1, node2, diffRecorder) && stopOnFirst:
+		return nil
+	case attributesDifferent(node1, node2, diffRecorder) && stopOnFirst:
+		return nil
+	}
+
+	text1, text2 := &streamText{exact: opts.whitespace == WhitespaceExact}, &streamText{exact: opts.whitespace == WhitespaceExact}
+	children1 := &streamedChildren{stream: stream1, parent: node1, text: text1}
+	children2 := &streamedChildren{stream: stream2, parent: node2, text: text2}
+	for !diffRecorder.isComplete() {
+		child1, err := children1.peek(0, diffRecorder)
+		if err != nil {
+			return err
+		}
+		child2, err := children2.peek(0, diffRecorder)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case child1 == nil && child2 == nil:
+			node1.CharData, node2.CharData = text1.String(), text2.String()
+			nodesTextDifferent(node1, node2, diffRecorder)
+			return nil
+		case child1 != nil && child2 != nil && child1.key == child2.key:
+			err = pairedChildrenDifferent(children1, children2, diffRecorder)
+		case child1 != nil && child2 != nil:
+			err = realignChildren(children1, children2, child1.key, child2.key, diffRecorder)
+		case child1 != nil:
+			err = extraChildren(children1, children2, 1, diffDelete, diffRecorder)
+		default:
+			err = extraChildren(children1, children2, 1, diffAdd, diffRecorder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compares the first pending children with the same keys - streamed if they are not buffered yet.
+func pairedChildrenDifferent(children1 *streamedChildren, children2 *streamedChildren, diffRecorder *diffRecorder) error {
+	if children1.pending[0].node == nil && children2.pending[0].node == nil {
+		child1, child2 := children1.pop(), children2.pop()
+		return streamedElementsDifferent(children1.stream, children2.stream, child1.start, child2.start, children1.parent,
+			children2.parent, child1.index, child2.index, diffRecorder)
+	}
+
+	if err := children1.buffer(0); err != nil {
+		return err
+	}
+	if err := children2.buffer(0); err != nil {
+		return err
+	}
+	child1, child2 := children1.pop(), children2.pop()
+	bufferedSubtreesDifferent(child1.node, child2.node, diffRecorder)
+	return nil
+}
+
+// Looks ahead for a child with the key of the other document's first pending child; children preceding the closest one
+// are reported as removed or added. Without one, both first pending children are reported.
+func realignChildren(children1 *streamedChildren, children2 *streamedChildren, key1 string, key2 string,
+	diffRecorder *diffRecorder) error {
+	added, err := children2.find(key1, diffRecorder)
+	if err != nil {
+		return err
+	}
+	removed, err := children1.find(key2, diffRecorder)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case added != 0 && (removed == 0 || added <= removed):
+		return extraChildren(children1, children2, added, diffAdd, diffRecorder)
+	case removed != 0:
+		return extraChildren(children1, children2, removed, diffDelete, diffRecorder)
+	}
+	if err = extraChildren(children1, children2, 1, diffDelete, diffRecorder); err != nil {
+		return err
+	}
+	return extraChildren(children1, children2, 1, diffAdd, diffRecorder)
+}
+
+// Reads the next child of the streamed element, skipping children excluded by ignored paths.
+//   - count - count of children read so far, updated
+func (stream *xmlStream) nextComparedChild(parent *Node, count *int, text *streamText,
+	diffRecorder *diffRecorder) (*xml.StartElement, error) {
+	for {
+		start, err := stream.nextChild(text)
+		if start == nil || err != nil {
+			return nil, err
+		}
+		*count++
+		if !diffRecorder.opts.isIgnoredPath(streamedNode(start, parent, *count-1)) {
+			return start, nil
+		}
+		if err = stream.skip(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Records the first pending children as present in only one of documents.
+//   - count - count of the children
+//   - kind - `diffDelete` for children of the first document, `diffAdd` for the second one
+func extraChildren(children1 *streamedChildren, children2 *streamedChildren, count int, kind editType,
+	diffRecorder *diffRecorder) error {
+	children := children1
+	if kind == diffAdd {
+		children = children2
+	}
+
+	for ; count > 0; count-- {
+		if err := children.buffer(0); err != nil {
+			return err
+		}
+		child := children.pop()
+		selectIgnoredInSubtree(child.node, diffRecorder)
+		if !diffRecorder.isIgnoredNode(child.node) {
+			diffs := []diffT[Node]{{e: *child.node, t: kind, aIdx: child.index, bIdx: child.index}}
+			diffRecorder.addDiff(createChildrenDiff(diffs, children1.count, children2.count, children1.parent.path(),
+				children1.parent, children2.parent))
+		}
+	}
+	return nil
+}
+
+// Compares subtrees buffered from streams with the regular algorithm.
+func bufferedSubtreesDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) {
+	selectIgnoredInSubtree(node1, diffRecorder)
+	selectIgnoredInSubtree(node2, diffRecorder)
+	if !diffRecorder.isIgnoredNode(node1) && !diffRecorder.isIgnoredNode(node2) {
+		nodesDifferent(node1, node2, diffRecorder)
+	}
+}
+
+// Selects nodes by ignored XPath expressions treating the buffered subtree as a document.
+func selectIgnoredInSubtree(node *Node, diffRecorder *diffRecorder) {
+	parent := node.Parent
+	node.Parent = nil
+	diffRecorder.selectIgnoredNodes(node)
+	node.Parent = parent
+}
@@ -0,0 +1,164 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compareStrings(sample1 string, sample2 string, opts ...Option) DiffList {
+	return CompareXmlStreams(strings.NewReader(sample1), strings.NewReader(sample2), opts...).GetStructuredDiffs()
+}
+
+func TestCompareXmlStreams(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Empty(compareStrings(xmlString1, xmlString1))
+	assertT.Equal([]DiffKind{ElementRemoved, ElementAdded, ElementRemoved, ElementAdded, ElementAdded},
+		collectKinds(compareStrings(`<a><b/><c/><d/></a>`, `<a><x/><c/><y/><z/></a>`)))
+
+	diffs := compareStrings(`<?xml version="1.0"?><a x="1"><b>1</b><c><d>2</d></c>t</a>`, `<a x="2"><b>1</b><c><d>3</d></c>u</a>`)
+	assertT.Equal(3, len(diffs))
+	assertT.Equal(AttrChanged, diffs[0].Kind)
+	assertT.Equal("/a", diffs[0].Path1)
+	assertT.Equal(TextChanged, diffs[1].Kind)
+	assertT.Equal("/a/c[1]/d", diffs[1].Path1)
+	assertT.Equal(TextChanged, diffs[2].Kind)
+	assertT.Equal("/a", diffs[2].Path1)
+	assertT.Equal("u", diffs[2].Actual)
+}
+
+func TestStreamingExtraChildren(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := compareStrings(`<a><b/><c>1</c><d/></a>`, `<a><b/></a>`)
+	assertT.Equal(2, len(diffs))
+	assertT.Equal(ElementRemoved, diffs[0].Kind)
+	assertT.Equal("/a/c[1]", diffs[0].Path1)
+	assertT.Equal(ElementRemoved, diffs[1].Kind)
+	assertT.Equal("/a/d[2]", diffs[1].Path1)
+
+	diffs = compareStrings(`<a><b/></a>`, `<a><b/><c/></a>`)
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ElementAdded, diffs[0].Kind)
+	assertT.Equal("/a/c[1]", diffs[0].Path2)
+}
+
+func TestStreamingRealignment(t *testing.T) {
+	assertT := assert.New(t)
+
+	var buf1, buf2 strings.Builder
+	buf1.WriteString("<orders>")
+	buf2.WriteString("<orders><note/>")
+	for i := range 100 {
+		fmt.Fprintf(&buf1, "<order id=\"%d\"><qty>1</qty></order>", i)
+		fmt.Fprintf(&buf2, "<order id=\"%d\"><qty>1</qty></order>", i)
+		if i == 50 {
+			buf1.WriteString("<total/><tax/>")
+		}
+	}
+	buf1.WriteString("</orders>")
+	buf2.WriteString("</orders>")
+
+	// Inserted and removed children don't shift pairing of the following ones
+	diffs := compareStrings(buf1.String(), buf2.String())
+	assertT.Equal([]DiffKind{ElementAdded, ElementRemoved, ElementRemoved}, collectKinds(diffs))
+	assertT.Equal("/orders/note[0]", diffs[0].Path2)
+	assertT.Equal("/orders/total[51]", diffs[1].Path1)
+	assertT.Equal("/orders/tax[52]", diffs[2].Path1)
+
+	// Children are realigned by keys
+	diffs = compareStrings(`<a><i k="1"/><i k="2">x</i><i k="3"/></a>`, `<a><i k="2">y</i><i k="3"/></a>`, WithKeyAttributes("k"))
+	assertT.Equal([]DiffKind{ElementRemoved, TextChanged}, collectKinds(diffs))
+	assertT.Equal("/a/i[1]", diffs[1].Path1)
+	assertT.Equal("/a/i[0]", diffs[1].Path2)
+
+	// Children beyond the window are not realigned
+	sample2 := "<a>" + strings.Repeat("<x/>", streamLookahead+1) + "<b/></a>"
+	assertT.Equal(streamLookahead+3, len(compareStrings(`<a><b/></a>`, sample2)))
+}
+
+func TestStreamingText(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Whitespace of element-only content is not kept
+	text := &streamText{}
+	for _, data := range []string{"\n  ", "\n  ", "Hello ", "\n", " there", "\n  ", "\n"} {
+		text.write([]byte(data))
+	}
+	assertT.Equal("Hello \n there", text.String())
+	text = &streamText{exact: true}
+	text.write([]byte("\n "))
+	assertT.Equal("\n ", text.String())
+
+	assertT.Empty(compareStrings("<a>\n  <b/>\n  text <c/> more\n</a>", "<a><b/>text <c/> more</a>"))
+	assertT.Equal(1, len(compareStrings("<a>\n  <b/>\n</a>", "<a><b/></a>", WithWhitespace(WhitespaceExact))))
+}
+
+func TestStreamingDepth(t *testing.T) {
+	assertT := assert.New(t)
+
+	var buf1, buf2 strings.Builder
+	buf1.WriteString("<export><orders>")
+	buf2.WriteString("<export><orders>")
+	for i := range 1000 {
+		fmt.Fprintf(&buf1, "<order id=\"%d\"><qty>%d</qty></order>", i, i)
+		fmt.Fprintf(&buf2, "<order id=\"%d\"><qty>%d</qty></order>", i, i+i/999)
+	}
+	buf1.WriteString("</orders></export>")
+	buf2.WriteString("</orders></export>")
+
+	// Children of streamed elements always have indices
+	for depth, path := range map[int]string{1: "/export/orders[0]/order[999]/qty", 2: "/export/orders[0]/order[999]/qty",
+		3: "/export/orders[0]/order[999]/qty[0]"} {
+		diffs := compareStrings(buf1.String(), buf2.String(), WithStreamingDepth(depth))
+		assertT.Equal(1, len(diffs))
+		assertT.Equal(path, diffs[0].Path1)
+	}
+}
+
+func TestStreamingOptions(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Unordered elements are buffered
+	assertT.Empty(compareStrings(`<a><b>1</b><b>2</b></a>`, `<a><b>2</b><b>1</b></a>`, WithIgnoreOrder()))
+	// Streamed children are paired in order
+	assertT.Equal([]DiffKind{TextChanged, TextChanged},
+		collectKinds(compareStrings(`<a><b>1</b><b>2</b></a>`, `<a><b>2</b><b>1</b></a>`)))
+
+	// Ignored paths and XPath expressions
+	assertT.Empty(compareStrings(`<a><b>1</b><c>1</c></a>`, `<a><b>2</b><c>1</c></a>`, WithIgnoredPaths("/a/b")))
+	assertT.Empty(compareStrings(`<a><b><t>1</t></b></a>`, `<a><b><t>2</t></b><b><t/></b></a>`,
+		WithIgnoredXPath("//t", "//b[t='']")))
+
+	// Stop on first
+	assertT.Equal(1, len(compareStrings(`<a x="1"><b>1</b></a>`, `<b x="2"><b>2</b></b>`, WithStopOnFirst())))
+
+	assertT.Equal(4, CompareXmlStreams(strings.NewReader(`<a><b/><c/><d/></a>`), strings.NewReader(`<a><b/><c/><d/></a>`)).
+		GetSummary().NodesCompared)
+}
+
+func TestStreamingErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	diffs := compareStrings(`<a><b/>`, `<a><b/></a>`)
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+	assertT.Equal("Can't parse the first sample: XML syntax error on line 1: unexpected EOF", diffs[0].Message)
+
+	diffs = compareStrings(`<a/>`, ``)
+	assertT.Equal("Can't parse the second sample: no root element", diffs[0].Message)
+
+	diffs = compareStrings(`<a><b>x</c></a>`, `<a><b/></a>`)
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+}
+
+func collectKinds(diffs DiffList) []DiffKind {
+	kinds := make([]DiffKind, len(diffs))
+	for i := range diffs {
+		kinds[i] = diffs[i].Kind
+	}
+	return kinds
+}
@@ -0,0 +1,50 @@
+package xmlcomparator
+
+import "time"
+
+// Statistics of a comparison.
+type Summary struct {
+	// Counts of elementary differences per kind
+	DiffCounts map[DiffKind]int
+	// Count of element pairs compared one by one; identical subtrees are compared at once
+	NodesCompared int
+	// Count of elements of the first sample that have a counterpart in the second sample
+	NodesMatched int
+	// Duration of parsing and comparison
+	Elapsed time.Duration
+}
+
+func (recorder diffRecorder) GetSummary() Summary {
+	diffs := recorder.GetStructuredDiffs()
+	ret := Summary{DiffCounts: make(map[DiffKind]int), NodesCompared: recorder.nodesCompared, Elapsed: recorder.elapsed}
+
+	removed := 0
+	for i := range diffs {
+		ret.DiffCounts[diffs[i].Kind]++
+		if diffs[i].Kind == ElementRemoved {
+			removed += recorder.countElements(diffs[i].Node1)
+		}
+	}
+	if recorder.root1 != nil {
+		ret.NodesMatched = recorder.countElements(recorder.root1) - removed
+	}
+	return ret
+}
+
+// Records differences held for move detection and remembers duration of the comparison.
+func (recorder *diffRecorder) finish() {
+	recorder.recordMoves()
+	recorder.elapsed = time.Since(recorder.started)
+}
+
+// Counts elements of the subtree participating in comparison.
+func (recorder *diffRecorder) countElements(node *Node) int {
+	if node == nil || recorder.isIgnoredNode(node) {
+		return 0
+	}
+	count := 1
+	for i := range node.Children {
+		count += recorder.countElements(&node.Children[i])
+	}
+	return count
+}
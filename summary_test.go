@@ -0,0 +1,30 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	assertT := assert.New(t)
+
+	summary := Compare(`<a><b x="1">t</b><c><d/></c><e/></a>`, `<a><b x="2">u</b><f/><e/></a>`).GetSummary()
+	assertT.Equal(map[DiffKind]int{TextChanged: 1, AttrChanged: 1, ElementRemoved: 1, ElementAdded: 1}, summary.DiffCounts)
+	assertT.Equal(2, summary.NodesCompared)
+	assertT.Equal(3, summary.NodesMatched)
+	assertT.Greater(int64(summary.Elapsed), int64(0))
+
+	summary = Compare(xmlString1, xmlString1).GetSummary()
+	assertT.Equal(0, len(summary.DiffCounts))
+	assertT.Equal(1, summary.NodesCompared)
+	assertT.Equal(6, summary.NodesMatched)
+
+	summary = Compare(`<a>`, `<a/>`).GetSummary()
+	assertT.Equal(map[DiffKind]int{ParseFailed: 1}, summary.DiffCounts)
+	assertT.Equal(0, summary.NodesMatched)
+
+	summary = CompareSubtrees(`<a><b><c/></b></a>`, `<x><b><c/><d/></b></x>`, "/a/b", "/x/b", WithIgnoredPaths("/a/b/c")).GetSummary()
+	assertT.Equal(1, summary.NodesMatched)
+	assertT.Greater(int64(summary.Elapsed), int64(0))
+}
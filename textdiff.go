@@ -0,0 +1,108 @@
+package xmlcomparator
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// Texts of this length and longer are described by changed fragments rather than full values
+	longTextLength = 200
+	// Count of unchanged lines or characters shown around changes of long texts
+	textContext = 2
+	// Limit of edit graph points analysed for long texts; texts needing more are described by full values
+	maxTextGraphs = 200000
+)
+
+// Describes differences of long texts with Myers' diff - hunks of changed lines for multi-line texts and changed
+// fragments marked as `[-removed-]{+added+}` within a line otherwise.
+//
+// Returns: description of changes or an empty string if texts are short or too different
+func describeTextChanges(text1 string, text2 string) string {
+	if len(text1) < longTextLength && len(text2) < longTextLength {
+		return ""
+	}
+
+	if strings.Contains(text1, "\n") || strings.Contains(text2, "\n") {
+		lines1, lines2 := strings.Split(text1, "\n"), strings.Split(text2, "\n")
+		if textEdits(lines1, lines2) == nil {
+			return ""
+		}
+		return strings.TrimSuffix(unifiedHunks(lines1, lines2, textContext), "\n")
+	}
+
+	diffs := textEdits([]rune(text1), []rune(text2))
+	if diffs == nil {
+		return ""
+	}
+	var buf strings.Builder
+	for start := 0; start < len(diffs); {
+		end := start + 1
+		for end < len(diffs) && (diffs[end].t == diffs[start].t || diffs[start].t != diffSame && diffs[end].t != diffSame) {
+			end++
+		}
+		if diffs[start].t != diffSame {
+			// Removed characters of a changed fragment go first
+			slices.SortStableFunc(diffs[start:end], func(d1, d2 diffT[rune]) int { return int(d1.t) - int(d2.t) })
+			for mid := start; mid < end; mid++ {
+				if diffs[mid].t != diffs[start].t {
+					writeTextFragment(&buf, diffs[start:mid], false, false)
+					start = mid
+					break
+				}
+			}
+		}
+		writeTextFragment(&buf, diffs[start:end], start == 0, end == len(diffs))
+		start = end
+	}
+	return buf.String()
+}
+
+// Edit script of texts with equal elements included.
+//
+// Returns: the edit script or `nil` if the analysis was stopped by the limit of edit graph points
+func textEdits[T comparable](a []T, b []T) []diffT[T] {
+	diffs := compareSequencesEx(a, b, func(x, y T) bool { return x == y }, true, maxTextGraphs)
+	count1, count2 := 0, 0
+	for i := range diffs {
+		if diffs[i].t != diffAdd {
+			count1++
+		}
+		if diffs[i].t != diffDelete {
+			count2++
+		}
+	}
+	if count1 != len(a) || count2 != len(b) {
+		return nil
+	}
+	return diffs
+}
+
+// Writes a run of characters with the same edit type; long unchanged runs are shortened to context around changes.
+//   - first, last - whether the run starts or ends the text
+func writeTextFragment(buf *strings.Builder, run []diffT[rune], first bool, last bool) {
+	text := make([]rune, len(run))
+	for i := range run {
+		text[i] = run[i].e
+	}
+
+	switch run[0].t {
+	case diffDelete:
+		buf.WriteString("[-" + string(text) + "-]")
+	case diffAdd:
+		buf.WriteString("{+" + string(text) + "+}")
+	case diffSame:
+		head, tail := textContext*4, textContext*4
+		if first {
+			head = 0
+		}
+		if last {
+			tail = 0
+		}
+		if len(text) <= head+tail {
+			buf.WriteString(string(text))
+			return
+		}
+		buf.WriteString(string(text[:head]) + "..." + string(text[len(text)-tail:]))
+	}
+}
@@ -0,0 +1,36 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeTextChanges(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal("", describeTextChanges("short", "text"))
+
+	long := strings.Repeat("0123456789", 25)
+	assertT.Equal("...12345678[-9-]{+X+}01234567...23456789{+!+}", describeTextChanges(long, long[:99]+"X"+long[100:]+"!"))
+	assertT.Equal("[-0-]12345678...", describeTextChanges(long, long[1:]))
+
+	lines := make([]string, 0)
+	for i := range 50 {
+		lines = append(lines, "line "+strings.Repeat("x", i%7))
+	}
+	csv1 := strings.Join(lines, "\n")
+	lines[20] = "changed"
+	csv2 := strings.Join(lines, "\n")
+	assertT.Equal("@@ -19,5 +19,5 @@\n line xxxx\n line xxxxx\n-line xxxxxx\n+changed\n line \n line x", describeTextChanges(csv1, csv2))
+}
+
+func TestLongTextDiffMessage(t *testing.T) {
+	assertT := assert.New(t)
+
+	text := strings.Repeat("abcdefghij", 30)
+	diffs := Compare("<a>"+text+"</a>", "<a>"+strings.Replace(text, "fgh", "FGH", 1)+"</a>")
+	assertT.Equal([]string{"Long node texts differ: 'abcde[-fgh-]{+FGH+}ijabcdef...', path='/a'"}, diffs.GetMessages())
+	assertT.Equal(text, diffs.GetStructuredDiffs()[0].Expected)
+}
@@ -0,0 +1,38 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"unicode/utf8"
+)
+
+// Difference which message can show shortened values.
+type valueTruncator interface {
+	// Sets the length of values in the message, see `WithValueTruncation`
+	truncateValues(maxLen int)
+}
+
+func (diff *textualDiff) truncateValues(maxLen int) {
+	diff.truncation = maxLen
+}
+
+func (diff *attributeDiff) truncateValues(maxLen int) {
+	diff.truncation = maxLen
+}
+
+// Elides the value longer than the limit to its prefix followed by the length and the hash of the whole value,
+// e.g. `iVBORw0KGgo...(1048576 bytes, fnv64a 9f2c4e1ab0d3c755)`.
+//   - maxLen - length of the kept prefix in bytes; the value is kept as is if not positive
+func truncateValue(value string, maxLen int) string {
+	if maxLen <= 0 || len(value) <= maxLen {
+		return value
+	}
+
+	prefix := maxLen
+	for prefix > 0 && !utf8.RuneStart(value[prefix]) {
+		prefix--
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(value))
+	return fmt.Sprintf("%s...(%d bytes, fnv64a %016x)", value[:prefix], len(value), hash.Sum64())
+}
@@ -0,0 +1,42 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateValue(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal("abc", truncateValue("abc", 0))
+	assertT.Equal("abc", truncateValue("abc", 3))
+	assertT.Equal("ab...(5 bytes, fnv64a 6348c52d762364a8)", truncateValue("abcde", 2))
+	assertT.NotEqual(truncateValue("abcde", 2), truncateValue("abcdf", 2))
+	// Multibyte characters are not split
+	assertT.True(strings.HasPrefix(truncateValue("aéb", 2), "a..."))
+}
+
+func TestValueTruncation(t *testing.T) {
+	assertT := assert.New(t)
+
+	blob1, blob2 := strings.Repeat("QUJD", 100000), strings.Repeat("QUJD", 99999)+"QUJE"
+	sample1 := `<a id="` + blob1 + `">` + blob1 + `</a>`
+	sample2 := `<a id="` + blob2 + `">` + blob2 + `</a>`
+
+	diffs := Compare(sample1, sample2, WithValueTruncation(8))
+	messages := diffs.GetMessages()
+	assertT.Len(messages, 2)
+	for _, message := range messages {
+		assertT.Less(len(message), 300)
+	}
+	assertT.Contains(messages[1], "'id=QUJDQUJD...(400000 bytes, fnv64a ")
+	// Structured differences keep full values
+	for _, diff := range diffs.GetStructuredDiffs() {
+		assertT.Equal(blob1, diff.Expected)
+		assertT.Equal(blob2, diff.Actual)
+	}
+
+	assertT.Greater(len(Compare(sample1, sample2).GetMessages()[1]), 800000)
+}
@@ -6,43 +6,74 @@ import (
 	"strings"
 )
 
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
 // Creates a string representation of the XML path to the node.
 //
 // path elements are node names separated by slashes.
 //
 // Child element might have its index, unless it is the only child - handy for dealing with arrays.
-func (node *parseNode) path() string {
-	path := make([]string, 0)
-	currNode := node
-
-	for currNode.Parent != nil {
-		siblings := currNode.Parent.Children
-		nodeName := nodeName(currNode)
-		if len(siblings) == 1 {
-			path = append(path, "/"+nodeName)
-		} else {
-			for i := 0; i < len(siblings); i++ {
-				if siblings[i].Hash == currNode.Hash {
-					path = append(path, "/"+nodeName+"["+strconv.Itoa(i)+"]")
-					break
-				}
-			}
-		}
-		currNode = currNode.Parent
+//
+// Segments of parsed and linked nodes are precomputed, so the path is built in O(depth).
+func (node *Node) path() string {
+	segments := make([]string, 0, 16)
+	size := 0
+	for currNode := node; currNode != nil; currNode = currNode.Parent {
+		segment := currNode.pathSegment()
+		segments = append(segments, segment)
+		size += len(segment)
 	}
-	path = append(path, "/"+nodeName(currNode))
 
-	// Reverse the path
-	size := len(path)
-	for i := 0; i < size/2; i++ {
-		path[i], path[size-i-1] = path[size-i-1], path[i]
+	var buf strings.Builder
+	buf.Grow(size)
+	for i := len(segments) - 1; i >= 0; i-- {
+		buf.WriteString(segments[i])
 	}
+	return buf.String()
+}
+
+// Last segment of the node path.
+func (node *Node) pathSegment() string {
+	switch {
+	case node.Parent == nil:
+		return "/" + nodeName(node)
+	case node.streamIndex > 0:
+		return "/" + nodeName(node) + "[" + strconv.Itoa(node.streamIndex-1) + "]"
+	case node.segment != "":
+		return node.segment
+	case len(node.Parent.Children) == 1:
+		return "/" + nodeName(node)
+	default:
+		return "/" + nodeName(node) + "[" + strconv.Itoa(siblingPosition(node.Parent.Children, node)) + "]"
+	}
+}
+
+// Position of the node among siblings; copies of nodes are found by content.
+func siblingPosition(siblings []Node, node *Node) int {
+	for i := range siblings {
+		if &siblings[i] == node {
+			return i
+		}
+	}
+	for i := range siblings {
+		if identicalSubtrees(&siblings[i], node) {
+			return i
+		}
+	}
+	return -1
+}
 
-	return strings.Join(path, "")
+// Level of the node in the tree; the root element has depth 1.
+func (node *Node) depth() int {
+	depth := 1
+	for currNode := node.Parent; currNode != nil; currNode = currNode.Parent {
+		depth++
+	}
+	return depth
 }
 
 // Converts XML node to a string that includes node name and attribites.
-func (node *parseNode) String() string {
+func (node *Node) String() string {
 	attStr := ""
 	for i := range node.Attrs {
 		attStr += attrName(&node.Attrs[i]) + "=" + node.Attrs[i].Value
@@ -62,17 +93,45 @@ func (node *parseNode) String() string {
 
 // Convenience shortcut functions
 
-func nodeName(node *parseNode) string {
+func nodeName(node *Node) string {
 	return node.XMLName.Local
 }
-func nodeSpace(node *parseNode) string {
+
+func nodeSpace(node *Node) string {
 	return node.XMLName.Space
 }
 
+// Checks whether the element is marked with `xsi:nil="true"` and has no content.
+func isNil(node *Node) bool {
+	if len(node.Children) != 0 || strings.TrimSpace(node.CharData) != "" {
+		return false
+	}
+	for i := range node.Attrs {
+		attr := &node.Attrs[i]
+		if attrName(attr) == "nil" && (attr.Name.Space == xsiNamespace || attr.Name.Space == "xsi") {
+			value := strings.TrimSpace(attr.Value)
+			return value == "true" || value == "1"
+		}
+	}
+	return false
+}
+
+func trimmedText(node *Node) string {
+	return strings.TrimSpace(node.CharData)
+}
+
 func attrName(attr *xml.Attr) string {
 	return attr.Name.Local
 }
 
+// Attribute name qualified with namespace URI in Clark notation `{uri}name` if the attribute has namespace.
+func attrQName(attr *xml.Attr) string {
+	if attrSpace(attr) == "" {
+		return attrName(attr)
+	}
+	return "{" + attrSpace(attr) + "}" + attrName(attr)
+}
+
 func attrSpace(attr *xml.Attr) string {
 	return attr.Name.Space
 }
@@ -1,6 +1,7 @@
 package xmlcomparator
 
 import (
+	"encoding/xml"
 	"fmt"
 	"testing"
 
@@ -46,3 +47,34 @@ func TestStringerInterface(t *testing.T) {
 	assertT.Equal("Envelope[SOAP-ENV=http://www.w3.org/2001/12/soap-envelope, encodingStyle=http://www.w3.org/2001/12/soap-encoding]",
 		fmt.Sprint(root))
 }
+
+func TestIsNil(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><b xsi:nil="true"/><c xsi:nil="false"/><d xsi:nil="true">x</d><e nil="true"/></a>`)
+	assertT.True(isNil(&root.Children[0]))
+	assertT.False(isNil(&root.Children[1]))
+	assertT.False(isNil(&root.Children[2]))
+	assertT.False(isNil(&root.Children[3]))
+	assertT.False(isNil(root))
+}
+
+func TestPathSegments(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b><c/></b><b/></a>`)
+	assertT.Equal("/b[1]", root.Children[1].segment)
+	assertT.Equal("/c", root.Children[0].Children[0].segment)
+	assertT.Equal("/a/b[0]/c", root.Children[0].Children[0].path())
+
+	// Segments follow modifications
+	root.Children[0].AppendChild(E("d"))
+	root.RemoveChild(1)
+	assertT.Equal("/a/b/c[0]", root.Children[0].Children[0].path())
+	assertT.Equal("/a/b/d[1]", root.Children[0].Children[1].path())
+
+	// Nodes linked without segments
+	parent := &Node{XMLName: xml.Name{Local: "p"}, Children: []Node{{XMLName: xml.Name{Local: "q"}}, {XMLName: xml.Name{Local: "q"}}}}
+	parent.Children[1].Parent = parent
+	assertT.Equal("/p/q[1]", parent.Children[1].path())
+}
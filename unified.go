@@ -0,0 +1,132 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const indentUnit = "  "
+
+// Renders differences of two XML strings as a unified diff (`---`/`+++`/`@@`) of their normalized forms.
+// Both samples are formatted with one element per line and sorted attributes, so only meaningful differences remain.
+//   - sample1, sample2 - XML strings to compare
+//   - label1, label2 - names of the samples in the diff header
+//   - context - count of unchanged lines around changes
+//
+// Returns: unified diff or an empty string if normalized samples are equal, and error if samples can't be parsed
+func UnifiedDiff(sample1 string, sample2 string, label1 string, label2 string, context int) (string, error) {
+	root1, err := parseXML(sample1)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the first sample: %w", err)
+	}
+	root2, err := parseXML(sample2)
+	if err != nil {
+		return "", fmt.Errorf("can't parse the second sample: %w", err)
+	}
+
+	return unifiedDiff(normalizedLines(root1), normalizedLines(root2), label1, label2, max(context, 0)), nil
+}
+
+// Formats the element with its subtree one element per line with sorted attributes.
+// Namespace prefixes are dropped, so namespaces are declared as default ones where they change.
+func normalizedLines(node *Node) []string {
+	normalized := node.Clone()
+	normalized.walk(func(n *Node) bool {
+		attrs := n.extractAttributes()
+		sort.Slice(attrs, func(i, j int) bool { return attrQName(&attrs[i]) < attrQName(&attrs[j]) })
+		n.Attrs = attrs
+		return true
+	})
+	return strings.Split(normalized.Pretty(indentUnit), "\n")
+}
+
+// Line of the edit script with positions in both sequences before it.
+type editLine struct {
+	diffT[string]
+	pos1 int
+	pos2 int
+}
+
+func unifiedDiff(lines1 []string, lines2 []string, label1 string, label2 string, context int) string {
+	hunks := unifiedHunks(lines1, lines2, context)
+	if hunks == "" {
+		return ""
+	}
+	return "--- " + label1 + "\n+++ " + label2 + "\n" + hunks
+}
+
+// Hunks (`@@`) of the unified diff without the header.
+func unifiedHunks(lines1 []string, lines2 []string, context int) string {
+	diffs := compareSequencesEx(lines1, lines2, func(a, b string) bool { return a == b }, true, defaultMaxDiffs)
+	// Removed lines of a changed block go first
+	for start := 0; start < len(diffs); start++ {
+		end := start
+		for end < len(diffs) && diffs[end].t != diffSame {
+			end++
+		}
+		sort.SliceStable(diffs[start:end], func(i, j int) bool { return diffs[start+i].t < diffs[start+j].t })
+		start = end
+	}
+
+	script := make([]editLine, 0, len(diffs))
+	changes := make([]int, 0)
+	pos1, pos2 := 0, 0
+	for i := range diffs {
+		script = append(script, editLine{diffs[i], pos1, pos2})
+		switch diffs[i].t {
+		case diffSame:
+			pos1++
+			pos2++
+		case diffDelete:
+			pos1++
+			changes = append(changes, i)
+		case diffAdd:
+			pos2++
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	for k := 0; k < len(changes); {
+		// Merge changes which contexts overlap
+		first, last := changes[k], changes[k]
+		for k++; k < len(changes) && changes[k]-last <= 2*context+1; k++ {
+			last = changes[k]
+		}
+		writeHunk(&buf, script[max(first-context, 0):min(last+context+1, len(script))])
+	}
+	return buf.String()
+}
+
+func writeHunk(buf *strings.Builder, hunk []editLine) {
+	count1, count2 := 0, 0
+	for i := range hunk {
+		if hunk[i].t != diffAdd {
+			count1++
+		}
+		if hunk[i].t != diffDelete {
+			count2++
+		}
+	}
+
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(hunk[0].pos1, count1), hunkRange(hunk[0].pos2, count2))
+	for i := range hunk {
+		prefix := map[editType]string{diffSame: " ", diffDelete: "-", diffAdd: "+"}[hunk[i].t]
+		buf.WriteString(prefix + hunk[i].e + "\n")
+	}
+}
+
+// Range of lines in the hunk header; empty ranges refer to the preceding line.
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,70 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizedLines(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a y="2" x="&quot;1"><b>t&lt;</b>text<c/><n:d xmlns:n="urn:n" n:k="v"><n:e/></n:d></a>`)
+	assertT.Equal([]string{
+		`<a x="&quot;1" y="2">`,
+		`  text`,
+		`  <b>t&lt;</b>`,
+		`  <c/>`,
+		`  <d xmlns="urn:n" xmlns:ns1="urn:n" ns1:k="v">`,
+		`    <e/>`,
+		`  </d>`,
+		`</a>`}, normalizedLines(root))
+	assertT.Equal(`<a y="2" x="&quot;1">`, strings.Split(root.Pretty("  "), "\n")[0])
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><b>1</b><c>2</c><d/><e/><f/><g/><h/><i/><j>3</j></a>`
+	xmlSample2 := `<a>
+  <b>1</b>
+  <c>4</c>
+  <d/><e/><f/><g/><h/><i/>
+  <j>5</j>
+  <k/>
+</a>`
+
+	diff, err := UnifiedDiff(xmlSample1, xmlSample2, "expected.xml", "actual.xml", 1)
+	assertT.Nil(err)
+	assertT.Equal(`--- expected.xml
++++ actual.xml
+@@ -2,3 +2,3 @@
+   <b>1</b>
+-  <c>2</c>
++  <c>4</c>
+   <d/>
+@@ -9,3 +9,4 @@
+   <i/>
+-  <j>3</j>
++  <j>5</j>
++  <k/>
+ </a>
+`, diff)
+
+	diff, _ = UnifiedDiff(xmlSample1, xmlSample2, "1", "2", 3)
+	assertT.Equal(1, strings.Count(diff, "@@ -"))
+
+	diff, _ = UnifiedDiff(`<a><b/></a>`, `<a>
+	<b></b>
+</a>`, "1", "2", 3)
+	assertT.Equal("", diff)
+
+	diff, _ = UnifiedDiff(`<a><b/></a>`, `<a/>`, "1", "2", 0)
+	assertT.Equal("--- 1\n+++ 2\n@@ -1,3 +1 @@\n-<a>\n-  <b/>\n-</a>\n+<a/>\n", diff)
+
+	_, err = UnifiedDiff(`<a>`, `<a/>`, "1", "2", 3)
+	assertT.NotNil(err)
+	_, err = UnifiedDiff(`<a/>`, ``, "1", "2", 3)
+	assertT.NotNil(err)
+}
@@ -0,0 +1,218 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Checks whether text or attribute values are equal according to comparison settings.
+//   - node - node of the first sample the values belong to
+//   - attr - attribute name or empty string for the node text
+//   - value1, value2 - values from the first and second samples
+func (options *compareOptions) valuesEqual(node *Node, attr string, value1 string, value2 string) bool {
+	if value1 == value2 || (options.caseInsensitiveValues && strings.EqualFold(value1, value2)) {
+		return true
+	}
+
+	if len(options.valueComparators) > 0 {
+		path := valuePath(node, attr)
+		for _, comparator := range options.valueComparators {
+			if equal, handled := comparator(path, value1, value2); handled {
+				return equal
+			}
+		}
+	}
+
+	if options.regexPlaceholders && strings.Contains(value1, placeholderPrefix) {
+		return options.matchesPlaceholders(value1, value2)
+	}
+
+	return areEqualNumbers(value1, value2) || options.numbersWithinTolerance(node, value1, value2) ||
+		options.timestampsEqual(value1, value2) || options.booleansEqual(value1, value2)
+}
+
+// Compares lexical representations of booleans like `true` and `1`.
+func (options *compareOptions) booleansEqual(value1 string, value2 string) bool {
+	if !options.normalizeBooleans {
+		return false
+	}
+
+	bool1, ok1 := options.parseBoolean(value1)
+	bool2, ok2 := options.parseBoolean(value2)
+	return ok1 && ok2 && bool1 == bool2
+}
+
+func (options *compareOptions) parseBoolean(value string) (bool, bool) {
+	switch value {
+	case "true", "1":
+		return true, true
+	case "false", "0":
+		return false, true
+	}
+	if options.yesNoBooleans {
+		switch strings.ToLower(value) {
+		case "yes":
+			return true, true
+		case "no":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// Layouts of recognized ISO-8601 / RFC 3339 timestamps
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02Z07:00",
+	"2006-01-02",
+}
+
+var timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?)?(Z|[+-]\d{2}:\d{2})?$`)
+
+// Compares timestamps as instants with optional tolerance.
+func (options *compareOptions) timestampsEqual(value1 string, value2 string) bool {
+	if !options.compareTimestamps {
+		return false
+	}
+
+	time1, zoned1, ok1 := parseTimestamp(value1)
+	time2, zoned2, ok2 := parseTimestamp(value2)
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	// Timestamps without zone are treated as local wall-clock time - only comparable with their kind
+	if zoned1 != zoned2 && !options.ignoreTimezone {
+		return false
+	}
+	if options.ignoreTimezone {
+		time1 = time.Date(time1.Year(), time1.Month(), time1.Day(), time1.Hour(), time1.Minute(), time1.Second(), time1.Nanosecond(), time.UTC)
+		time2 = time.Date(time2.Year(), time2.Month(), time2.Day(), time2.Hour(), time2.Minute(), time2.Second(), time2.Nanosecond(), time.UTC)
+	}
+
+	delta := time1.Sub(time2)
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta <= options.timestampTolerance
+}
+
+// Parses the timestamp in one of the ISO-8601 layouts.
+//
+// Returns: parsed time, whether it has time zone and whether parsing succeeded
+func parseTimestamp(value string) (time.Time, bool, bool) {
+	if !timestampPattern.MatchString(value) {
+		return time.Time{}, false, false
+	}
+
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, strings.Contains(layout, "Z07:00"), true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// Path of the value - node path for texts or node path with `/@name` suffix for attributes.
+func valuePath(node *Node, attr string) string {
+	path := pathOf(node)
+	if attr != "" {
+		path += "/@" + attr
+	}
+	return path
+}
+
+const placeholderPrefix = "${regex:"
+
+// Matches the value against the template with regex placeholders like `${regex:[0-9]+}`.
+func (options *compareOptions) matchesPlaceholders(template string, value string) bool {
+	cached, ok := options.placeholders.Load(template)
+	if !ok {
+		regex, err := compilePlaceholders(template)
+		if err != nil {
+			regex = nil
+		}
+		cached, _ = options.placeholders.LoadOrStore(template, regex)
+	}
+
+	regex := cached.(*regexp.Regexp)
+	return regex != nil && regex.MatchString(value)
+}
+
+// Converts the template into a regular expression - literal parts are quoted.
+func compilePlaceholders(template string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	rest := template
+	for {
+		start := strings.Index(rest, placeholderPrefix)
+		if start < 0 {
+			break
+		}
+		sb.WriteString(regexp.QuoteMeta(rest[:start]))
+		rest = rest[start+len(placeholderPrefix):]
+
+		// Regular expression might have braces - find the matching one
+		depth, end := 1, -1
+		for i := 0; i < len(rest) && end < 0; i++ {
+			switch rest[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in '%s'", template)
+		}
+		sb.WriteString("(?:" + rest[:end] + ")")
+		rest = rest[end+1:]
+	}
+	sb.WriteString(regexp.QuoteMeta(rest))
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// Compares numeric-looking values with absolute and relative tolerances.
+func (options *compareOptions) numbersWithinTolerance(node *Node, value1 string, value2 string) bool {
+	if options.numericTolerance <= 0 && len(options.relativeTolerances) == 0 {
+		return false
+	}
+	if !numberPattern.MatchString(value1) || !numberPattern.MatchString(value2) {
+		return false
+	}
+
+	val1, err1 := strconv.ParseFloat(value1, 64)
+	val2, err2 := strconv.ParseFloat(value2, 64)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	delta := math.Abs(val2 - val1)
+	if delta <= options.numericTolerance {
+		return true
+	}
+
+	magnitude := math.Max(math.Abs(val1), math.Abs(val2))
+	for _, rule := range options.relativeTolerances {
+		if delta <= rule.ratio*magnitude && (len(rule.paths) == 0 || (node != nil && matchesAnyPath(rule.paths, node))) {
+			return true
+		}
+	}
+	return false
+}
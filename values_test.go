@@ -0,0 +1,156 @@
+package xmlcomparator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericTolerance(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a><price currency="EUR" rate="0.5">1.0</price></a>`
+	xmlSample2 := `<a><price currency="EUR" rate="0.5001">0.9999</price></a>`
+
+	assertT.Equal([]string{"Node texts differ: '1.0' vs '0.9999', path='/a/price'",
+		"Attributes differ: 'rate=0.5' vs 'rate=0.5001', path='/a/price'"}, Compare(xmlSample1, xmlSample2).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithNumericTolerance(0.001)).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithNumericTolerance(-0.0002)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '1.0' vs '0.9999', path='/price'"},
+		Compare(`<price>1.0</price>`, `<price>0.9999</price>`, WithNumericTolerance(0.00005)).GetMessages())
+}
+
+func TestNumericToleranceIgnoresText(t *testing.T) {
+	assertT := assert.New(t)
+
+	options := newCompareOptions([]Option{WithNumericTolerance(1)})
+	assertT.True(options.valuesEqual(nil, "", "abc", "abc"))
+	assertT.True(options.valuesEqual(nil, "", "1", "1.5"))
+	assertT.False(options.valuesEqual(nil, "", "1", "2.5"))
+	assertT.False(options.valuesEqual(nil, "", "1a", "1b"))
+}
+
+func TestRelativeTolerance(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<m><small>0.001</small><large v="1000000000">1000000000</large></m>`
+	xmlSample2 := `<m><small>0.002</small><large v="1000500000">1000500000</large></m>`
+
+	assertT.Equal(3, len(Compare(xmlSample1, xmlSample2).GetMessages()))
+	assertT.Equal([]string{"Node texts differ: '0.001' vs '0.002', path='/m/small[0]'"},
+		Compare(xmlSample1, xmlSample2, WithRelativeTolerance(0.001)).GetMessages())
+	assertT.Equal(emptyList,
+		Compare(xmlSample1, xmlSample2, WithRelativeTolerance(0.001, "/m/large"), WithNumericTolerance(0.01)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '1000000000' vs '1000500000', path='/m/large[1]'",
+		"Attributes differ: 'v=1000000000' vs 'v=1000500000', path='/m/large[1]'"},
+		Compare(xmlSample1, xmlSample2, WithRelativeTolerance(0.001, "/m/small"), WithNumericTolerance(0.01)).GetMessages())
+}
+
+func TestRegexPlaceholders(t *testing.T) {
+	assertT := assert.New(t)
+
+	expected := `<r id="${regex:\d+}"><id>${regex:[0-9a-f-]{36}}</id><name>user-${regex:[a-z]+}!</name></r>`
+	actual := `<r id="42"><id>123e4567-e89b-12d3-a456-426614174000</id><name>user-bob!</name></r>`
+
+	assertT.Equal(3, len(Compare(expected, actual).GetMessages()))
+	assertT.Equal(emptyList, Compare(expected, actual, WithRegexPlaceholders()).GetMessages())
+	assertT.Equal([]string{"Node texts differ: 'user-${regex:[a-z]+}!' vs 'user-Bob!', path='/r/name[1]'"},
+		Compare(expected, `<r id="1"><id>123e4567-e89b-12d3-a456-426614174000</id><name>user-Bob!</name></r>`, WithRegexPlaceholders()).GetMessages())
+	// Placeholders in the second sample are not special
+	assertT.Equal(3, len(Compare(actual, expected, WithRegexPlaceholders()).GetMessages()))
+}
+
+func TestCompilePlaceholders(t *testing.T) {
+	assertT := assert.New(t)
+
+	regex, err := compilePlaceholders("a.${regex:b{2}}-${regex:c|d}")
+	assertT.Nil(err)
+	assertT.Equal(`^a\.(?:b{2})-(?:c|d)$`, regex.String())
+
+	_, err = compilePlaceholders("${regex:[a-z]")
+	assertT.NotNil(err)
+	_, err = compilePlaceholders("${regex:[a-z}")
+	assertT.NotNil(err)
+
+	options := newCompareOptions([]Option{WithRegexPlaceholders()})
+	assertT.False(options.valuesEqual(nil, "", "${regex:(}", "("))
+	assertT.False(options.valuesEqual(nil, "", "${regex:(}", "("))
+	assertT.True(options.valuesEqual(nil, "", "${regex:}", ""))
+}
+
+func TestValueComparator(t *testing.T) {
+	assertT := assert.New(t)
+
+	paths := make([]string, 0)
+	dates := func(path string, expected string, actual string) (bool, bool) {
+		paths = append(paths, path)
+		if path == "/a/date[0]" {
+			return strings.ReplaceAll(expected, ".", "/") == actual, true
+		}
+		return false, false
+	}
+	secret := func(path string, expected string, actual string) (bool, bool) {
+		return strings.HasSuffix(path, "/@secret"), strings.HasSuffix(path, "/@secret")
+	}
+
+	xmlSample1 := `<a><date>01.02.2024</date><b secret="x" v="1">text</b></a>`
+	xmlSample2 := `<a><date>01/02/2024</date><b secret="y" v="1">text</b></a>`
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithValueComparator(dates), WithValueComparator(secret)).GetMessages())
+	assertT.Equal([]string{"/a/date[0]", "/a/b[1]/@secret"}, paths)
+
+	never := func(path string, expected string, actual string) (bool, bool) { return false, true }
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample1, WithValueComparator(never)).GetMessages())
+	assertT.Equal([]string{"Node texts differ: '01.02.2024' vs '01/02/2024', path='/a/date[0]'",
+		"Attributes differ: 'secret=x' vs 'secret=y', path='/a/b[1]'"},
+		Compare(xmlSample1, xmlSample2, WithValueComparator(never)).GetMessages())
+}
+
+func TestTimestamps(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a at="2024-01-01T00:00:00Z"><t>2024-01-01T00:00:00Z</t></a>`
+	xmlSample2 := `<a at="2024-01-01T01:00:01+01:00"><t>2024-01-01T01:00:00+01:00</t></a>`
+
+	assertT.Equal(2, len(Compare(xmlSample1, xmlSample2).GetMessages()))
+	assertT.Equal([]string{"Attributes differ: 'at=2024-01-01T00:00:00Z' vs 'at=2024-01-01T01:00:01+01:00', path='/a'"},
+		Compare(xmlSample1, xmlSample2, WithTimestampTolerance(0)).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithTimestampTolerance(2*time.Second)).GetMessages())
+}
+
+func TestTimezoneInsensitiveTimestamps(t *testing.T) {
+	assertT := assert.New(t)
+
+	options := newCompareOptions([]Option{WithTimezoneInsensitiveTimestamps()})
+	assertT.True(options.valuesEqual(nil, "", "2024-01-01T10:00:00Z", "2024-01-01T10:00:00+05:00"))
+	assertT.True(options.valuesEqual(nil, "", "2024-01-01T10:00:00", "2024-01-01T10:00:00.000-03:00"))
+	assertT.False(options.valuesEqual(nil, "", "2024-01-01T10:00:00Z", "2024-01-01T11:00:00+01:00"))
+
+	options = newCompareOptions([]Option{WithTimestampTolerance(time.Minute)})
+	assertT.True(options.valuesEqual(nil, "", "2024-01-01", "2024-01-01T00:00:30"))
+	assertT.True(options.valuesEqual(nil, "", "2024-01-01 00:00:00Z", "2024-01-01T00:00:00.5Z"))
+	assertT.False(options.valuesEqual(nil, "", "2024-01-01T00:00:00", "2024-01-01T00:00:00Z"))
+	assertT.False(options.valuesEqual(nil, "", "2024-01-01", "2024-13-01"))
+	assertT.False(options.valuesEqual(nil, "", "2024-01-01", "tomorrow"))
+}
+
+func TestBooleanNormalization(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<a flag="true"><b>0</b></a>`
+	xmlSample2 := `<a flag="1"><b>false</b></a>`
+
+	assertT.Equal(2, len(Compare(xmlSample1, xmlSample2).GetMessages()))
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithBooleanNormalization(false)).GetMessages())
+
+	options := newCompareOptions([]Option{WithBooleanNormalization(false)})
+	assertT.False(options.valuesEqual(nil, "", "true", "0"))
+	assertT.False(options.valuesEqual(nil, "", "yes", "true"))
+	assertT.False(options.valuesEqual(nil, "", "TRUE", "1"))
+
+	options = newCompareOptions([]Option{WithBooleanNormalization(true)})
+	assertT.True(options.valuesEqual(nil, "", "Yes", "true"))
+	assertT.True(options.valuesEqual(nil, "", "no", "0"))
+	assertT.False(options.valuesEqual(nil, "", "no", "yes"))
+}
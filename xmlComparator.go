@@ -1,7 +1,10 @@
 package xmlcomparator
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
+	"io"
 	"math"
 	"regexp"
 	"slices"
@@ -14,12 +17,14 @@ import (
 
 const (
 	eps = 1.e-6
+	// Count of compared nodes between checks of the comparison context
+	contextCheckInterval = 64
 )
 
 var numberPattern = regexp.MustCompile(`^[-+]?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?$`)
 
-var hashComparator = func(x, y uint32) bool { return x < y }
-var attrComparator = func(x, y xml.Attr) bool { return attrName(&x) < attrName(&y) }
+var hashComparator = func(x, y uint64) bool { return x < y }
+var attrComparator = func(x, y xml.Attr) bool { return attrQName(&x) < attrQName(&y) }
 
 // Compares two XML strings.
 //   - sample1 - first XML string
@@ -53,72 +58,321 @@ func CompareXmlStringsEx(sample1 string, sample2 string, stopOnFirst bool, ignor
 // Returns:
 // A list of detected discrepancies
 func ComputeDifferences(sample1 string, sample2 string, stopOnFirst bool, ignoredDiscrepancies []string) DiffRecorder {
-	diffRecorder := createDiffRecorder(ignoredDiscrepancies)
+	opts := []Option{WithIgnoredDiscrepancies(ignoredDiscrepancies...)}
+	if stopOnFirst {
+		opts = append(opts, WithStopOnFirst())
+	}
+	return Compare(sample1, sample2, opts...)
+}
 
-	root1, err := parseXML(sample1)
-	if root1 == nil || err != nil {
-		diffRecorder.addDiff(parserError{text: "Can't parse the first sample: " + err.Error()})
-		return diffRecorder
+// Compares two XML strings with configurable behavior.
+//   - sample1 - first XML string
+//   - sample2 - second XML string
+//   - opts - comparison options like `WithStopOnFirst()` or `WithIgnoredPaths(...)`
+//
+// Returns:
+// A list of detected discrepancies
+func Compare(sample1 string, sample2 string, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).Compare(sample1, sample2)
+}
+
+// Compares two XML strings, aborting comparison when the context is canceled or its deadline is exceeded.
+//   - ctx - context of the comparison
+//   - sample1 - first XML string
+//   - sample2 - second XML string
+//   - opts - comparison options
+//
+// Returns:
+// A list of discrepancies detected before cancellation and `ctx.Err()` if comparison was aborted,
+// or `*LimitError` if a document exceeded limits
+func CompareContext(ctx context.Context, sample1 string, sample2 string, opts ...Option) (DiffRecorder, error) {
+	return NewComparator(opts...).CompareContext(ctx, sample1, sample2)
+}
+
+// Compares XML documents read from readers; documents are parsed directly from the readers,
+// and a copy of each read document is kept with its tree as in `UnmarshalXMLReader`.
+//   - r1 - reader of the first document
+//   - r2 - reader of the second document
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareXmlReaders(r1 io.Reader, r2 io.Reader, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareReaders(r1, r2)
+}
+
+// Compares XML files; a file that can't be read is reported as `ParseFailed` difference.
+// Files with extensions `.gz`, `.bz2` and `.zip` (with a single XML file) are decompressed before parsing;
+// `WithMaxDocumentSize` limits the decompressed size.
+//   - path1 - path of the first file
+//   - path2 - path of the second file
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareXmlFiles(path1 string, path2 string, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareFiles(path1, path2)
+}
+
+// Compares parsed trees, so a document parsed once can be compared against many others; trees are not modified.
+// Nodes might be subtrees - paths in discrepancies are relative to documents roots then.
+//   - node1 - root of the first tree
+//   - node2 - root of the second tree
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareNodes(node1 *Node, node2 *Node, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareNodes(node1, node2)
+}
+
+// Converter of a document in another format into a tree of nodes, e.g. `UnmarshalYAMLString`.
+type Parser func(sample string) (*Node, error)
+
+// Compares documents of another format converted to trees by the parser with the same options as XML.
+// Size and node count limits apply to the documents and the converted trees.
+//   - sample1 - first document
+//   - sample2 - second document
+//   - parse - converter of documents into trees; trees may be built with exported fields only
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareParsed(sample1 string, sample2 string, parse Parser, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareParsed(sample1, sample2, parse)
+}
+
+func parseLimited(sample string, parse Parser, limits documentLimits) (*Node, error) {
+	if limits.maxSize > 0 && int64(len(sample)) > limits.maxSize {
+		return nil, &LimitError{Unit: "bytes", Limit: limits.maxSize}
+	}
+	root, err := parse(sample)
+	if err != nil {
+		return nil, err
+	}
+	if root != nil {
+		root.Parent = nil
+		root.linkChildren()
+		root.hashCode()
+	}
+	return existingNode(root, limits)
+}
+
+func existingNode(node *Node, limits documentLimits) (*Node, error) {
+	if node == nil {
+		return nil, errors.New("node is nil")
+	}
+	if err := limits.checkNodes(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func compareInputs(parse1 func() (*Node, error), parse2 func() (*Node, error), diffRecorder *diffRecorder) DiffRecorder {
+	root1, root2 := parseInputs(parse1, parse2, diffRecorder)
+	if root1 != nil && root2 != nil {
+		compareRoots(root1, root2, diffRecorder)
+	}
+
+	diffRecorder.finish()
+	return diffRecorder
+}
+
+// Compares two XML strings passing elementary differences to the handler as soon as they are found.
+// With `WithMoveDetection()` differences are passed when the comparison is complete.
+//   - sample1 - first XML string
+//   - sample2 - second XML string
+//   - handler - receiver of differences; returns `false` to abort comparison
+//   - opts - comparison options
+func CompareWithHandler(sample1 string, sample2 string, handler func(Diff) bool, opts ...Option) {
+	diffRecorder := createDiffRecorderEx(newCompareOptions(opts))
+	diffRecorder.handler = handler
+
+	root1, root2 := parseSamples(sample1, sample2, diffRecorder)
+	if root1 != nil && root2 != nil {
+		compareRoots(root1, root2, diffRecorder)
 	}
+	diffRecorder.finish()
+}
 
-	root2, err := parseXML(sample2)
-	if root2 == nil || err != nil {
-		diffRecorder.addDiff(parserError{text: "Can't parse the second sample: " + err.Error()})
+// Compares subtrees of two XML strings, e.g. `/envelope/body/result` of one document against `/response/data` of another.
+//   - sample1 - first XML string
+//   - sample2 - second XML string
+//   - path1, path2 - paths of subtree roots in the format reported in diffs; the first matching element is used
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies; paths in discrepancies are relative to documents roots
+func CompareSubtrees(sample1 string, sample2 string, path1 string, path2 string, opts ...Option) DiffRecorder {
+	diffRecorder := createDiffRecorderEx(newCompareOptions(opts))
+	defer diffRecorder.finish()
+
+	root1, root2 := parseSamples(sample1, sample2, diffRecorder)
+	if root1 == nil || root2 == nil {
 		return diffRecorder
 	}
 
-	nodesDifferent(root1, root2, diffRecorder, stopOnFirst)
+	subtree1 := findNode(root1, compilePathPattern(path1))
+	if subtree1 == nil {
+		diffRecorder.addDiff(parserError{text: "Can't find '" + path1 + "' in the first sample"})
+		return diffRecorder
+	}
+	subtree2 := findNode(root2, compilePathPattern(path2))
+	if subtree2 == nil {
+		diffRecorder.addDiff(parserError{text: "Can't find '" + path2 + "' in the second sample"})
+		return diffRecorder
+	}
 
+	compareRoots(subtree1, subtree2, diffRecorder)
 	return diffRecorder
 }
 
-func nodesDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder, stopOnFirst bool) {
+func parseSamples(sample1 string, sample2 string, diffRecorder *diffRecorder) (*Node, *Node) {
+	limits := diffRecorder.opts.documentLimits()
+	return parseInputs(func() (*Node, error) { return parseLimitedXML(sample1, limits) },
+		func() (*Node, error) { return parseLimitedXML(sample2, limits) }, diffRecorder)
+}
+
+// Parses both inputs concurrently; a failure is recorded as a difference.
+//
+// Returns: roots of parsed documents or `nil`s on failure
+func parseInputs(parse1 func() (*Node, error), parse2 func() (*Node, error), diffRecorder *diffRecorder) (*Node, *Node) {
+	var root2 *Node
+	var err2 error
+	parsed2 := make(chan void)
+	go func() {
+		defer close(parsed2)
+		root2, err2 = parse2()
+	}()
+
+	root1, err := parse1()
+	<-parsed2
+	if root1 == nil || err != nil {
+		diffRecorder.parseFailed("first", err)
+		return nil, nil
+	}
+
+	if err = err2; root2 == nil || err != nil {
+		diffRecorder.parseFailed("second", err)
+		return nil, nil
+	}
+
+	diffRecorder.selectIgnoredNodes(root1)
+	diffRecorder.selectIgnoredNodes(root2)
+	return root1, root2
+}
+
+// Records the parser error; `DiffRecorder.Err()` returns an exceeded limit.
+//   - ordinal - "first" or "second"
+func (recorder *diffRecorder) parseFailed(ordinal string, err error) {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		recorder.limitErr = limitErr
+	}
+	recorder.addDiff(parserError{text: "Can't parse the " + ordinal + " sample: " + err.Error()})
+}
+
+func compareRoots(root1 *Node, root2 *Node, diffRecorder *diffRecorder) {
+	diffRecorder.root1 = root1
+	if !diffRecorder.isIgnoredNode(root1) && !diffRecorder.isIgnoredNode(root2) {
+		nodesDifferent(root1, root2, diffRecorder)
+	}
+}
+
+// Finds the first element in document order which path matches the pattern.
+func findNode(node *Node, pattern pathPattern) *Node {
+	path := node.path()
+	if pattern.matches(path) {
+		return node
+	}
+	if len(splitPath(path)) >= len(pattern) {
+		return nil
+	}
+
+	for i := range node.Children {
+		if found := findNode(&node.Children[i], pattern); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func nodesDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) {
+	if diffRecorder.isComplete() {
+		return
+	}
+	if diffRecorder.session != nil && len(node1.Children) > 0 {
+		diffRecorder.session.compareUnlessEqual(node1, node2, diffRecorder)
+		return
+	}
+	pairDifferent(node1, node2, diffRecorder)
+}
+
+// Compares names, texts, attributes and children of the nodes.
+func pairDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) {
+	diffRecorder.nodesCompared++
+	if diffRecorder.nodesCompared%contextCheckInterval == 0 && diffRecorder.isCanceled() {
+		return
+	}
+	stopOnFirst := diffRecorder.opts.stopOnFirst
 	switch {
 	case nodeNamesDifferent(node1, node2, diffRecorder) && stopOnFirst:
 		return
 	case nodeSpacesDifferent(node1, node2, diffRecorder) && stopOnFirst:
 		return
+	case soapFaultsDifferent(node1, node2, diffRecorder):
+		return
 	case nodesTextDifferent(node1, node2, diffRecorder) && stopOnFirst:
 		return
+	case nodesMarkupDifferent(node1, node2, diffRecorder) && stopOnFirst:
+		return
+	case serializationsDifferent(node1, node2, diffRecorder) && stopOnFirst:
+		return
 	case attributesDifferent(node1, node2, diffRecorder) && stopOnFirst:
 		return
-	case childrenDifferent(node1, node2, diffRecorder, stopOnFirst):
+	case childrenDifferent(node1, node2, diffRecorder):
 		return
 	}
 }
 
-func nodeNamesDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder) bool {
+func nodeNamesDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
 	name1 := nodeName(node1)
 	name2 := nodeName(node2)
-	if name1 == name2 {
+	if name1 == name2 || (diffRecorder.opts.caseInsensitiveNames && strings.EqualFold(name1, name2)) {
 		return false
 	}
 
-	diffRecorder.addDiff(createTextDiff(DiffName, name1, name2, node1.path()))
+	diffRecorder.addDiff(createTextDiff(DiffName, name1, name2, node1.path(), node1, node2))
 	return true
 }
 
-func nodeSpacesDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder) bool {
+func nodeSpacesDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
 	space1 := nodeSpace(node1)
 	space2 := nodeSpace(node2)
-	if space1 == space2 || space1 == "" || space2 == "" {
+	if space1 == space2 || (!diffRecorder.opts.strictNamespaces && (space1 == "" || space2 == "")) ||
+		diffRecorder.opts.isNamespaceInsensitive(node1) {
 		return false
 	}
 
 	if diffRecorder.areNamespacesNew(space1, space2) {
-		diffRecorder.addDiff(createTextDiff(DiffSpace, space1, space2, node1.path()))
+		diffRecorder.addDiff(createTextDiff(DiffSpace, space1, space2, node1.path(), node1, node2))
+	} else {
+		diffRecorder.suppressed++
 	}
 	return true
 }
-func nodesTextDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder) bool {
-	ownText1 := strings.TrimSpace(node1.CharData)
-
-	ownText2 := strings.TrimSpace(node2.CharData)
-	if ownText1 == ownText2 || areEqualNumbers(ownText1, ownText2) {
-		return false
+func nodesTextDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	ownText1, ownText2 := node1.CharData, node2.CharData
+	if diffRecorder.opts.isOpaque(node1) {
+		ownText1, ownText2 = string(node1.Content), string(node2.Content)
+	}
+	ownText1 = diffRecorder.opts.normalizeSpace(ownText1)
+	ownText2 = diffRecorder.opts.normalizeSpace(ownText2)
+	if diffRecorder.opts.valuesEqual(node1, "", ownText1, ownText2) {
+		return diffRecorder.opts.strictCDATA && textRepresentationsDifferent(node1, node2, diffRecorder)
 	}
 
-	diffRecorder.addDiff(createTextDiff(DiffContent, ownText1, ownText2, node1.path()))
+	diffRecorder.addDiff(createTextDiff(DiffContent, ownText1, ownText2, node1.path(), node1, node2))
 	return true
 }
 
@@ -131,20 +385,96 @@ func areEqualNumbers(text1, text2 string) bool {
 	return false
 }
 
-func attributesDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder) bool {
-	attrs1 := node1.extractAttributes()
-	attrs2 := node2.extractAttributes()
-	if slices.Equal(attrs1, attrs2) || slices.Equal(sorted(attrs1, attrComparator), sorted(attrs2, attrComparator)) {
+func textRepresentationsDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	if node1.CDATA == node2.CDATA {
 		return false
 	}
 
-	diffs := compareSequences(attrs1, attrs2, func(a, b xml.Attr) bool { return a == b })
-	diffRecorder.addDiff(createAttributeDiff(diffs, len(attrs1), len(attrs2), node1.path()))
+	diffRecorder.addDiff(createTextDiff(DiffRepresentation, textRepresentation(node1), textRepresentation(node2), node1.path(), node1, node2))
+	return true
+}
+
+func textRepresentation(node *Node) string {
+	if node.CDATA {
+		return "CDATA"
+	}
+	return "text"
+}
+
+func serializationsDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	if !diffRecorder.opts.strictSelfClosing || node1.SelfClosing == node2.SelfClosing ||
+		len(node1.Content) != 0 || len(node2.Content) != 0 {
+		return false
+	}
+
+	diffRecorder.addDiff(createTextDiff(DiffSerialization, serialization(node1), serialization(node2), node1.path(), node1, node2))
+	return true
+}
+
+func serialization(node *Node) string {
+	if node.SelfClosing {
+		return "self-closing"
+	}
+	return "empty"
+}
+
+// Compares comments and processing instructions if requested.
+func nodesMarkupDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	opts := diffRecorder.opts
+	if !opts.compareComments && !opts.compareProcInsts {
+		return false
+	}
+
+	comments1, procInsts1 := node1.ownMarkup()
+	comments2, procInsts2 := node2.ownMarkup()
+	different := false
+	if opts.compareComments && !slices.Equal(comments1, comments2) {
+		diffRecorder.addDiff(createTextDiff(DiffComments, strings.Join(comments1, "|"), strings.Join(comments2, "|"), node1.path(), node1, node2))
+		different = true
+	}
+	if opts.compareProcInsts && !slices.Equal(procInsts1, procInsts2) {
+		diffRecorder.addDiff(createTextDiff(DiffProcInsts, strings.Join(procInsts1, ""), strings.Join(procInsts2, ""), node1.path(), node1, node2))
+		different = true
+	}
+	return different
+}
+
+func attributesDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	attrs1 := diffRecorder.opts.comparedAttributes(node1)
+	attrs2 := diffRecorder.opts.comparedAttributes(node2)
+	attrKey := diffRecorder.opts.attrKey
+	equals := func(a, b xml.Attr) bool {
+		return attrKey(&a) == attrKey(&b) && diffRecorder.opts.valuesEqual(node1, attrName(&a), a.Value, b.Value)
+	}
+	comparator := func(x, y xml.Attr) bool { return attrKey(&x) < attrKey(&y) }
+	if slices.EqualFunc(sorted(attrs1, comparator), sorted(attrs2, comparator), equals) {
+		return diffRecorder.opts.strictAttributeOrder && attributesOrderDifferent(attrs1, attrs2, node1, node2, diffRecorder)
+	}
+
+	diffs := compareSequences(attrs1, attrs2, equals)
+	attrDiff := createAttributeDiff(diffs, len(attrs1), len(attrs2), node1.path(), node1, node2)
+	attrDiff.namer = attrKey
+	diffRecorder.addDiff(attrDiff)
 
 	return true
 }
 
-func (node *parseNode) extractAttributes() []xml.Attr {
+func attributesOrderDifferent(attrs1 []xml.Attr, attrs2 []xml.Attr, node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	names1 := make([]string, len(attrs1))
+	names2 := make([]string, len(attrs2))
+	for i := range attrs1 {
+		names1[i] = attrQName(&attrs1[i])
+		names2[i] = attrQName(&attrs2[i])
+	}
+	if slices.Equal(names1, names2) {
+		return false
+	}
+
+	diffRecorder.addDiff(createTextDiff(DiffAttributesOrder, strings.Join(names1, ", "), strings.Join(names2, ", "), node1.path(), node1, node2))
+	return true
+}
+
+func (node *Node) extractAttributes() []xml.Attr {
 	attrs := make([]xml.Attr, 0, len(node.Attrs))
 	for i := range node.Attrs {
 		// Namesapce attributes are processed separately
@@ -155,50 +485,284 @@ func (node *parseNode) extractAttributes() []xml.Attr {
 	return attrs
 }
 
-func childrenDifferent(node1 *parseNode, node2 *parseNode, diffRecorder *diffRecorder, stopOnFirst bool) bool {
+// Extracts attributes that participate in comparison.
+func (options *compareOptions) comparedAttributes(node *Node) []xml.Attr {
+	attrs := node.extractAttributes()
+	if len(options.ignoredAttributes) == 0 && !options.emptyAttributesAsMissing {
+		return attrs
+	}
+
+	ret := make([]xml.Attr, 0, len(attrs))
+	for i := range attrs {
+		if options.emptyAttributesAsMissing && attrs[i].Value == "" {
+			continue
+		}
+		if !options.isIgnoredAttribute(node, &attrs[i]) {
+			ret = append(ret, attrs[i])
+		}
+	}
+	return ret
+}
+
+func childrenDifferent(node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	if diffRecorder.opts.isOpaque(node1) {
+		return false
+	}
+
+	children1, indices1 := diffRecorder.selectChildren(node1)
+	children2, indices2 := diffRecorder.selectChildren(node2)
+
 	// Simple case - identical children by hash
-	hashes1 := extractChildHashes(node1)
-	hashes2 := extractChildHashes(node2)
-	if slices.Equal(hashes1, hashes2) {
+	hashes1 := extractChildHashes(children1)
+	hashes2 := extractChildHashes(children2)
+	if slices.Equal(hashes1, hashes2) && (diffRecorder.opts.deepComparison() || diffRecorder.identicalSequences(children1, children2)) {
+		if diffRecorder.opts.deepComparison() {
+			return alignedChildrenDifferent(indices1, indices2, node1, node2, diffRecorder)
+		}
 		return false
 	}
 
+	if diffRecorder.opts.isUnordered(node1) {
+		return unorderedChildrenDifferent(node1, node2, indices1, indices2, diffRecorder)
+	}
+
 	// Simple case - permutation of children
 	if len(hashes1) == len(hashes2) {
 		sortedHashes1 := sorted(hashes1, hashComparator)
 		sortedHashes2 := sorted(hashes2, hashComparator)
-		if slices.Equal(sortedHashes1, sortedHashes2) {
-			diffRecorder.addDiff(createOrderDiff(len(hashes1), node1.path()))
+		if slices.Equal(sortedHashes1, sortedHashes2) && isPermutation(node1, node2, indices1, indices2) {
+			diffRecorder.addDiff(createOrderDiff(len(hashes1), node1.path(), node1, node2))
 			// TODO Implement comparison and output of sorted children
+			if diffRecorder.opts.deepComparison() {
+				pairs, _, _ := matchUnordered(node1, node2, indices1, indices2, diffRecorder.opts.elementKey, diffRecorder.opts.strategy)
+				nodePairs := make([]nodePair, len(pairs))
+				for i, pair := range pairs {
+					nodePairs[i] = nodePair{&node1.Children[pair.idx1], &node2.Children[pair.idx2]}
+				}
+				pairsDifferent(nodePairs, diffRecorder)
+			}
 			return true
 		}
 	}
 
-	diffs := compareSequences(node1.Children, node2.Children, func(a, b parseNode) bool { return a.Hash == b.Hash })
+	deep := diffRecorder.opts.deepComparison()
+	var allDiffs []diffT[Node]
+	if diffRecorder.opts.strategy == StrategyFast {
+		allDiffs = greedyAlignment(children1, children2, deep)
+	} else {
+		allDiffs = compareSequencesEx(children1, children2, func(a, b Node) bool { return diffRecorder.identical(&a, &b) }, deep, defaultMaxDiffs)
+	}
+	restoreIndices(allDiffs, indices1, indices2)
+
+	// Equal subtrees are recorded only for deep comparison
+	diffs := make([]diffT[Node], 0, len(allDiffs))
+	same := make([]diffT[Node], 0)
+	for i := range allDiffs {
+		if allDiffs[i].t == diffSame {
+			same = append(same, allDiffs[i])
+		} else {
+			diffs = append(diffs, allDiffs[i])
+		}
+	}
 
-	diffRecorder.addDiff(createChildrenDiff(diffs, len(node1.Children), len(node2.Children), node1.path()))
+	childDiff := createChildrenDiff(diffs, len(children1), len(children2), node1.path(), node1, node2)
+	childDiff.namer = diffRecorder.opts.elementKey
+	diffRecorder.addDiff(childDiff)
 
-	matchingdMap := createMatchingElementsMap(diffs, nodeName)
 	// Recursion!
-	iterateMatchingNodes(matchingdMap, diffs, diffRecorder, stopOnFirst)
+	var pairs []nodePair
+	if diffRecorder.opts.strategy == StrategyPrecise {
+		pairs = similarNodes(diffs, diffRecorder.opts.elementKey)
+	} else {
+		pairs = matchingNodes(createMatchingElementsMap(diffs, diffRecorder.opts.elementKey), diffs)
+	}
+	for i := range same {
+		pairs = append(pairs, nodePair{&node1.Children[same[i].aIdx], &node2.Children[same[i].bIdx]})
+	}
+	pairsDifferent(pairs, diffRecorder)
 
 	return true
 }
 
-func extractChildHashes(node *parseNode) []uint32 {
-	hashes := make([]uint32, len(node.Children))
+// Compares children pairwise - used when hashes don't reflect all compared features.
+func alignedChildrenDifferent(indices1 []int, indices2 []int, node1 *Node, node2 *Node, diffRecorder *diffRecorder) bool {
+	diffsCount := diffRecorder.recorded
+	pairs := make([]nodePair, len(indices1))
+	for k := range indices1 {
+		pairs[k] = nodePair{&node1.Children[indices1[k]], &node2.Children[indices2[k]]}
+	}
+	pairsDifferent(pairs, diffRecorder)
+	return diffRecorder.recorded != diffsCount
+}
+
+// Compares children matched regardless of their order.
+func unorderedChildrenDifferent(node1 *Node, node2 *Node, indices1 []int, indices2 []int, diffRecorder *diffRecorder) bool {
+	pairs, unmatched1, unmatched2 := matchUnordered(node1, node2, indices1, indices2, diffRecorder.opts.elementKey, diffRecorder.opts.strategy)
+
+	different := len(unmatched1) != 0 || len(unmatched2) != 0
+	if different {
+		diffs := make([]diffT[Node], 0, len(unmatched1)+len(unmatched2))
+		for _, i := range unmatched1 {
+			diffs = append(diffs, diffT[Node]{e: node1.Children[i], t: diffDelete, aIdx: i, bIdx: i})
+		}
+		for _, j := range unmatched2 {
+			diffs = append(diffs, diffT[Node]{e: node2.Children[j], t: diffAdd, aIdx: j, bIdx: j})
+		}
+		childDiff := createChildrenDiff(diffs, len(indices1), len(indices2), node1.path(), node1, node2)
+		childDiff.namer = diffRecorder.opts.elementKey
+		diffRecorder.addDiff(childDiff)
+	}
+
+	// Recursion!
+	nodePairs := make([]nodePair, 0, len(pairs))
+	for _, pair := range pairs {
+		child1, child2 := &node1.Children[pair.idx1], &node2.Children[pair.idx2]
+		if !diffRecorder.identical(child1, child2) || diffRecorder.opts.deepComparison() {
+			different = true
+			nodePairs = append(nodePairs, nodePair{child1, child2})
+		}
+	}
+	pairsDifferent(nodePairs, diffRecorder)
+
+	return different
+}
+
+// Selects children that participate in comparison along with their indices in the siblings list.
+func (recorder *diffRecorder) selectChildren(node *Node) ([]Node, []int) {
+	children := make([]Node, 0, len(node.Children))
+	indices := make([]int, 0, len(node.Children))
 	for i := range node.Children {
-		hashes[i] = node.Children[i].Hash
+		if !recorder.isIgnoredNode(&node.Children[i]) {
+			children = append(children, node.Children[i])
+			indices = append(indices, i)
+		}
+	}
+	return children, indices
+}
+
+// Converts indices in the diff list from selected children to the original siblings list.
+func restoreIndices(diffs []diffT[Node], indices1 []int, indices2 []int) {
+	for i := range diffs {
+		switch diffs[i].t {
+		case diffDelete:
+			diffs[i].aIdx = indices1[diffs[i].aIdx]
+			diffs[i].bIdx = diffs[i].aIdx
+		case diffAdd:
+			diffs[i].aIdx = indices2[diffs[i].aIdx]
+			diffs[i].bIdx = diffs[i].aIdx
+		case diffSame:
+			diffs[i].aIdx = indices1[diffs[i].aIdx]
+			diffs[i].bIdx = indices2[diffs[i].bIdx]
+		}
+	}
+}
+
+// Checks whether children with equal hashes are really identical.
+func (recorder *diffRecorder) identicalSequences(children1 []Node, children2 []Node) bool {
+	for i := range children1 {
+		if !recorder.identical(&children1[i], &children2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks whether children of the nodes are the same identical subtrees in different order.
+func isPermutation(node1 *Node, node2 *Node, indices1 []int, indices2 []int) bool {
+	_, rest1, rest2 := matchIdentical(node1, node2, indices1, indices2)
+	return len(rest1) == 0 && len(rest2) == 0
+}
+
+func extractChildHashes(children []Node) []uint64 {
+	hashes := make([]uint64, len(children))
+	for i := range children {
+		hashes[i] = children[i].Hash
 	}
 	return hashes
 }
 
-func iterateMatchingNodes(matchingMap *bimap.BiMap[int, int], diffs []diffT[parseNode], diffRecorder *diffRecorder, stopOnFirst bool) {
+// Pairs of modified nodes matched in the diff list.
+func matchingNodes(matchingMap *bimap.BiMap[int, int], diffs []diffT[Node]) []nodePair {
+	pairs := make([]nodePair, 0, matchingMap.Size())
 	it := matchingMap.Iterator()
 	for it.HasNext() {
 		i, j := it.Next()
-		nodesDifferent(&diffs[i].e, &diffs[j].e, diffRecorder, stopOnFirst)
+		// Keep the first sample on the left side
+		if diffs[i].t == diffAdd {
+			i, j = j, i
+		}
+		pairs = append(pairs, nodePair{originalNode(&diffs[i]), originalNode(&diffs[j])})
+	}
+	return pairs
+}
+
+// Pairs removed and added elements with the same name by maximal similarity.
+func similarNodes(diffs []diffT[Node], namer func(*Node) string) []nodePair {
+	removed, added := make([]*diffT[Node], 0), make([]*diffT[Node], 0)
+	for i := range diffs {
+		if diffs[i].t == diffDelete {
+			removed = append(removed, &diffs[i])
+		} else {
+			added = append(added, &diffs[i])
+		}
+	}
+	keys1 := make([]string, len(removed))
+	for k := range removed {
+		keys1[k] = namer(&removed[k].e)
+	}
+	keys2 := make([]string, len(added))
+	for k := range added {
+		keys2[k] = namer(&added[k].e)
+	}
+
+	matched := matchSameNamed(keys1, keys2, func(k1, k2 int) float64 {
+		return nodeSimilarity(originalNode(removed[k1]), originalNode(added[k2]))
+	}, StrategyPrecise)
+	pairs := make([]nodePair, len(matched))
+	for i, pair := range matched {
+		pairs[i] = nodePair{originalNode(removed[pair.idx1]), originalNode(added[pair.idx2])}
+	}
+	return pairs
+}
+
+// Aligns children in one pass - children identical by hashes are kept, otherwise the child which doesn't occur
+// later in the other sample is removed or added.
+//   - recordEquals - whether to include kept children into the result
+//
+// Returns: the edit script with indices in the children lists
+func greedyAlignment(children1 []Node, children2 []Node, recordEquals bool) []diffT[Node] {
+	later1 := make(map[uint64]int)
+	for i := range children1 {
+		later1[children1[i].Hash]++
+	}
+	later2 := make(map[uint64]int)
+	for j := range children2 {
+		later2[children2[j].Hash]++
+	}
+
+	diffs := make([]diffT[Node], 0)
+	i, j := 0, 0
+	for i < len(children1) || j < len(children2) {
+		switch {
+		case i < len(children1) && j < len(children2) && identicalSubtrees(&children1[i], &children2[j]):
+			if recordEquals {
+				diffs = append(diffs, diffT[Node]{e: children1[i], t: diffSame, aIdx: i, bIdx: j})
+			}
+			later1[children1[i].Hash]--
+			later2[children2[j].Hash]--
+			i++
+			j++
+		case i < len(children1) && (j == len(children2) || later2[children1[i].Hash] == 0 || later1[children2[j].Hash] > 0):
+			diffs = append(diffs, diffT[Node]{e: children1[i], t: diffDelete, aIdx: i, bIdx: i})
+			later1[children1[i].Hash]--
+			i++
+		default:
+			diffs = append(diffs, diffT[Node]{e: children2[j], t: diffAdd, aIdx: j, bIdx: j})
+			later2[children2[j].Hash]--
+			j++
+		}
 	}
+	return diffs
 }
 
 func sorted[T comparable](slice []T, isLess func(T, T) bool) []T {
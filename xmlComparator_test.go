@@ -1,6 +1,12 @@
 package xmlcomparator
 
 import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -230,3 +236,201 @@ func TestAreEqualNumbers(t *testing.T) {
 	assertT.False(areEqualNumbers("1.2", "1,2"))
 	assertT.False(areEqualNumbers("2", "abc"))
 }
+
+func TestNamespacePrefixesInsensitivity(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal(emptyList, CompareXmlStrings(`<a:foo xmlns:a="urn:x"/>`, `<b:foo xmlns:b="urn:x"/>`, false))
+	assertT.Equal(emptyList, CompareXmlStrings(`<r xmlns:a="urn:x" xmlns:b="urn:y"><e a:v="1" b:v="2"/></r>`,
+		`<r xmlns:c="urn:x" xmlns:d="urn:y"><e d:v="2" c:v="1"/></r>`, false))
+	assertT.Equal([]string{"Attributes differ: '{urn:y}v=1' vs '{urn:y}v=2', '{urn:x}v=2' vs '{urn:x}v=1', path='/r/e'"},
+		CompareXmlStrings(`<r xmlns:a="urn:x" xmlns:b="urn:y"><e b:v="1" a:v="2"/></r>`,
+			`<r xmlns:c="urn:x" xmlns:d="urn:y"><e d:v="2" c:v="1"/></r>`, false))
+	assertT.Equal([]string{"Attributes differ: counts 1 vs 1: {urn:x}v[0]:+1, v[0]:-1, path='/r/e'"},
+		CompareXmlStrings(`<r xmlns:a="urn:x"><e a:v="1"/></r>`, `<r><e v="1"/></r>`, false))
+}
+
+func TestCompareSubtrees(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<envelope><header/><body><result><v>1</v><w>2</w></result></body></envelope>`
+	xmlSample2 := `<response><data><v>1</v><w>3</w></data></response>`
+
+	assertT.Equal([]string{"Node names differ: 'envelope' vs 'response', path='/envelope'"}, Compare(xmlSample1, xmlSample2, WithStopOnFirst()).GetMessages())
+	assertT.Equal([]string{"Node names differ: 'result' vs 'data', path='/envelope/body[1]/result'",
+		"Node texts differ: '2' vs '3', path='/envelope/body[1]/result/w[1]'"},
+		CompareSubtrees(xmlSample1, xmlSample2, "/envelope/body/result", "/response/data").GetMessages())
+	assertT.Equal(emptyList, CompareSubtrees(xmlSample1, xmlSample2, "/envelope/*/result/v", "/response/data/v").GetMessages())
+	assertT.Equal([]string{"Can't find '/envelope/result' in the first sample"},
+		CompareSubtrees(xmlSample1, xmlSample2, "/envelope/result", "/response/data").GetMessages())
+	assertT.Equal([]string{"Can't find '/data' in the second sample"},
+		CompareSubtrees(xmlSample1, xmlSample2, "/envelope", "/data").GetMessages())
+}
+
+func TestCompareWithHandler(t *testing.T) {
+	assertT := assert.New(t)
+
+	expected := Compare(xmlString1, xmlMixed).GetStructuredDiffs()
+	found := make(DiffList, 0)
+	CompareWithHandler(xmlString1, xmlMixed, func(diff Diff) bool {
+		found = append(found, diff)
+		return true
+	})
+	assertT.Equal(expected, found)
+
+	found = found[:0]
+	CompareWithHandler(xmlString1, xmlMixed, func(diff Diff) bool {
+		found = append(found, diff)
+		return false
+	})
+	assertT.Equal(expected[:1], found)
+
+	// Attribute differences of the same element are passed separately
+	found = found[:0]
+	CompareWithHandler(`<a x="1" y="1"><b>1</b></a>`, `<a x="2" y="2"><b>2</b></a>`, func(diff Diff) bool {
+		found = append(found, diff)
+		return len(found) < 2
+	})
+	assertT.Equal([]DiffKind{AttrChanged, AttrChanged}, []DiffKind{found[0].Kind, found[1].Kind})
+
+	found = found[:0]
+	CompareWithHandler(`<a>`, `<a/>`, func(diff Diff) bool {
+		found = append(found, diff)
+		return true
+	})
+	assertT.Equal(1, len(found))
+	assertT.Equal(ParseFailed, found[0].Kind)
+}
+
+func TestCompareXmlReaders(t *testing.T) {
+	assertT := assert.New(t)
+
+	expected := Compare(xmlString1, xmlMixed).GetMessages()
+	assertT.Equal(expected, CompareXmlReaders(strings.NewReader(xmlString1), strings.NewReader(xmlMixed)).GetMessages())
+
+	diffs := CompareXmlReaders(strings.NewReader(`<a/>`), strings.NewReader(`<a>`)).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+}
+
+func TestCompareXmlFiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	dir := t.TempDir()
+	path1, path2 := filepath.Join(dir, "1.xml"), filepath.Join(dir, "2.xml")
+	assertT.Nil(os.WriteFile(path1, []byte(xmlString1), 0o600))
+	assertT.Nil(os.WriteFile(path2, []byte(xmlMixed), 0o600))
+
+	assertT.Equal(Compare(xmlString1, xmlMixed).GetMessages(), CompareXmlFiles(path1, path2, WithMaxDiffs(100)).GetMessages())
+	assertT.Empty(CompareXmlFiles(path1, path1).GetDiffs())
+
+	diffs := CompareXmlFiles(path1, filepath.Join(dir, "missing.xml")).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+	assertT.Contains(diffs[0].Message, "Can't parse the second sample: open ")
+}
+
+func TestCompareNodes(t *testing.T) {
+	assertT := assert.New(t)
+
+	golden, _ := parseXML(xmlString1)
+	mixed, _ := parseXML(xmlMixed)
+	expected := Compare(xmlString1, xmlMixed).GetMessages()
+	assertT.Equal(expected, CompareNodes(golden, mixed).GetMessages())
+	// Trees are reusable
+	assertT.Equal(expected, CompareNodes(golden, mixed).GetMessages())
+	assertT.Empty(CompareNodes(golden, golden.Clone()).GetDiffs())
+
+	root1, _ := parseXML(`<a><b><c>1</c></b></a>`)
+	built := E("b", E("c", Text("2")))
+	diffs := CompareNodes(&root1.Children[0], built, WithIgnoredXPath("//d")).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal("/a/b/c", diffs[0].Path1)
+	assertT.Equal("/b/c", diffs[0].Path2)
+
+	diffs = CompareNodes(root1, nil).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal("Can't parse the second sample: node is nil", diffs[0].Message)
+}
+
+func TestCompareParsed(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Trees built with exported fields only
+	parseCSV := func(sample string) (*Node, error) {
+		if sample == "" {
+			return nil, errors.New("empty line")
+		}
+		root := &Node{XMLName: xml.Name{Local: "row"}}
+		for _, value := range strings.Split(sample, ",") {
+			root.Children = append(root.Children, Node{XMLName: xml.Name{Local: "cell"}, CharData: value})
+		}
+		return root, nil
+	}
+
+	assertT.Empty(CompareParsed("1,2.0", "1.0,2", parseCSV).GetDiffs())
+	assertT.Equal([]string{"Node texts differ: '2' vs '3', path='/row/cell[1]'"}, CompareParsed("1,2", "1,3", parseCSV).GetMessages())
+	assertT.Equal([]string{"Can't parse the second sample: empty line"}, CompareParsed("1", "", parseCSV).GetMessages())
+	assertT.Equal(1, len(NewComparator(WithMaxDocumentSize(3)).CompareParsed("1,2", "1,2,3", parseCSV).GetDiffs()))
+}
+
+func TestHashCollisions(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Hashes of different subtrees are forced to be equal
+	collide := func(node1 *Node, node2 *Node) {
+		node2.Hash = node1.Hash
+	}
+
+	root1, root2 := E("a", E("b", Text("1"))), E("a", E("b", Text("2")))
+	collide(&root1.Children[0], &root2.Children[0])
+	collide(root1, root2)
+	assertT.False(root1.Equal(root2))
+	diffs := CompareNodes(root1, root2).GetStructuredDiffs()
+	assertT.Equal(1, len(diffs))
+	assertT.Equal(TextChanged, diffs[0].Kind)
+
+	// Permutation of children
+	root1, root2 = E("a", E("b", Text("1")), E("c")), E("a", E("c"), E("b", Text("2")))
+	collide(&root1.Children[0], &root2.Children[1])
+	diffs = CompareNodes(root1, root2).GetStructuredDiffs()
+	assertT.NotContains(collectKinds(diffs), OrderChanged)
+	diffs = CompareNodes(root1, root2, WithIgnoreOrder()).GetStructuredDiffs()
+	assertT.Equal([]DiffKind{TextChanged}, collectKinds(diffs))
+
+	// Paths of siblings
+	root := E("a", E("b", Text("1")), E("b", Text("2")))
+	collide(&root.Children[0], &root.Children[1])
+	assertT.Equal("/a/b[1]", root.Children[1].path())
+	copied := root.Children[1]
+	assertT.Equal("/a/b[1]", copied.path())
+}
+
+func TestOrderedInsertionsDontCascade(t *testing.T) {
+	assertT := assert.New(t)
+
+	var buf1, buf2 strings.Builder
+	buf1.WriteString("<list>")
+	buf2.WriteString("<list><new/>")
+	for i := range 500 {
+		fmt.Fprintf(&buf1, "<item>%d</item>", i)
+		if i != 250 {
+			fmt.Fprintf(&buf2, "<item>%d</item>", i)
+		}
+		if i == 400 {
+			buf2.WriteString("<item>inserted</item>")
+		}
+	}
+	buf1.WriteString("</list>")
+	buf2.WriteString("</list>")
+
+	// The removed and the inserted items are paired as a modified element
+	diffs := Compare(buf1.String(), buf2.String()).GetStructuredDiffs()
+	assertT.Equal(2, len(diffs))
+	assertT.Equal(ElementAdded, diffs[0].Kind)
+	assertT.Equal("/list/new[0]", diffs[0].Path2)
+	assertT.Equal(TextChanged, diffs[1].Kind)
+	assertT.Equal("/list/item[250]", diffs[1].Path1)
+	assertT.Equal("250", diffs[1].Expected)
+	assertT.Equal("inserted", diffs[1].Actual)
+}
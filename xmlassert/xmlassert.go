@@ -0,0 +1,85 @@
+// Package xmlassert provides testify-style assertions of XML documents equality.
+//
+//	func TestResponse(t *testing.T) {
+//		xmlassert.Equal(t, expected, actual, xmlcomparator.WithIgnoredAttributes("timestamp"))
+//	}
+package xmlassert
+
+import (
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+// Test state the assertions report failures to, e.g. `*testing.T`.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// Optional part of `TestingT` marking helper functions.
+type tHelper interface {
+	Helper()
+}
+
+// Asserts that XML documents are equal according to comparison options.
+// The failure message lists differences one per line.
+//   - t - test state
+//   - expected, actual - compared XML strings
+//   - opts - comparison options
+//
+// Returns: whether the assertion succeeded
+func Equal(t TestingT, expected string, actual string, opts ...xmlcomparator.Option) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	diffs := xmlcomparator.Compare(expected, actual, opts...).GetStructuredDiffs()
+	return check(t, "XML documents differ", diffs)
+}
+
+// Asserts that XML documents differ according to comparison options.
+//
+// Returns: whether the assertion succeeded
+func NotEqual(t TestingT, expected string, actual string, opts ...xmlcomparator.Option) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	diffs := xmlcomparator.Compare(expected, actual, opts...).GetStructuredDiffs()
+	if len(diffs) == 0 {
+		t.Errorf("XML documents should differ:\n%s", actual)
+		return false
+	}
+	return check(t, "XML documents can't be compared", diffs.OfKind(xmlcomparator.ParseFailed))
+}
+
+// Asserts that the actual document contains the expected one - elements and attributes may be added to the actual
+// document, while expected ones should be present with the same values.
+//   - t - test state
+//   - expected - XML string with required elements and attributes
+//   - actual - checked XML string
+//   - opts - comparison options
+//
+// Returns: whether the assertion succeeded
+func Subset(t TestingT, expected string, actual string, opts ...xmlcomparator.Option) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	diffs := xmlcomparator.Compare(expected, actual, opts...).GetStructuredDiffs()
+	missing := diffs.Filter(xmlcomparator.Not(xmlcomparator.ByKind(xmlcomparator.ElementAdded, xmlcomparator.AttrAdded)))
+	return check(t, "XML document is not a superset of the expected one", missing)
+}
+
+// Fails the test if there are differences.
+func check(t TestingT, title string, diffs xmlcomparator.DiffList) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	if len(diffs) == 0 {
+		return true
+	}
+
+	var buf strings.Builder
+	_ = report.WriteText(&buf, diffs, report.ColorNever)
+	t.Errorf("%s:\n%s", title, buf.String())
+	return false
+}
@@ -0,0 +1,62 @@
+package xmlassert
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Test state collecting failure messages.
+type mockT struct {
+	messages []string
+}
+
+func (t *mockT) Errorf(format string, args ...any) {
+	t.messages = append(t.messages, fmt.Sprintf(format, args...))
+}
+
+func TestEqual(t *testing.T) {
+	assertT := assert.New(t)
+
+	mock := &mockT{}
+	assertT.True(Equal(mock, `<a x="1"><b/></a>`, `<a x="1">  <b></b> </a>`))
+	assertT.True(Equal(mock, `<a x="1"/>`, `<a x="2"/>`, xmlcomparator.WithIgnoredAttributes("x")))
+	assertT.Empty(mock.messages)
+
+	assertT.False(Equal(mock, `<a x="1"><b>1</b></a>`, `<a x="2"><b>2</b><c/></a>`))
+	assertT.Equal([]string{"XML documents differ:\n~ /a @x: '1' -> '2'\n+ /a/c[1] <c>\n~ /a/b TextChanged: '1' -> '2'\n"},
+		mock.messages)
+
+	// Real test state is accepted
+	Equal(t, `<a/>`, `<a></a>`)
+}
+
+func TestNotEqual(t *testing.T) {
+	assertT := assert.New(t)
+
+	mock := &mockT{}
+	assertT.True(NotEqual(mock, `<a>1</a>`, `<a>2</a>`))
+	assertT.Empty(mock.messages)
+
+	assertT.False(NotEqual(mock, `<a>1</a>`, `<a> 1 </a>`))
+	assertT.False(NotEqual(mock, `<a>1</a>`, `<a>`))
+	assertT.Len(mock.messages, 2)
+	assertT.Equal("XML documents should differ:\n<a> 1 </a>", mock.messages[0])
+	assertT.Contains(mock.messages[1], "XML documents can't be compared:\n! Can't parse the second sample")
+}
+
+func TestSubset(t *testing.T) {
+	assertT := assert.New(t)
+
+	mock := &mockT{}
+	assertT.True(Subset(mock, `<a x="1"><b>1</b></a>`, `<a x="1" y="2"><b>1</b><c/></a>`))
+	assertT.Empty(mock.messages)
+
+	assertT.False(Subset(mock, `<a x="1" z="3"><b>1</b><d/></a>`, `<a x="1" y="2"><b>2</b><c/></a>`))
+	assertT.Len(mock.messages, 1)
+	assertT.Equal("XML document is not a superset of the expected one:\n- /a @z='3'\n- /a/d[1] <d>\n"+
+		"~ /a/b[0] TextChanged: '1' -> '2'\n", mock.messages[0])
+}
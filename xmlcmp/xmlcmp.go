@@ -0,0 +1,122 @@
+// Package xmlcmp compares raw XML strings semantically with go-cmp. Structures holding XML payloads are compared with
+// an option that applies to pairs of XML strings only, and a reporter describes their differences with the comparator:
+//
+//	reporter := xmlcmp.NewReporter(xmlcomparator.WithIgnoreOrder())
+//	if !cmp.Equal(want, got, xmlcmp.Option(xmlcomparator.WithIgnoreOrder()), cmp.Reporter(reporter)) {
+//		t.Errorf("mismatch (-want +got):\n%s", reporter)
+//	}
+//
+// The package is a separate module to keep the comparator free of the dependency.
+package xmlcmp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+// Creates a go-cmp option comparing pairs of XML strings semantically; other values are compared as usual.
+//   - opts - comparison options
+func Option(opts ...xmlcomparator.Option) cmp.Option {
+	return cmp.FilterValues(AreXML, cmp.Comparer(Equal(opts...)))
+}
+
+// Creates an equality function for `cmp.Comparer`.
+//   - opts - comparison options
+//
+// Returns: function reporting whether XML strings are equal; strings that aren't well-formed XML are equal
+// only if they are identical
+func Equal(opts ...xmlcomparator.Option) func(x string, y string) bool {
+	return func(x string, y string) bool {
+		if x == y {
+			return true
+		}
+		return len(xmlcomparator.Compare(x, y, opts...).GetStructuredDiffs()) == 0
+	}
+}
+
+// Filter for `cmp.FilterValues` selecting pairs of strings that look like XML documents, so the comparer
+// doesn't apply to other strings of compared structures.
+func AreXML(x string, y string) bool {
+	return looksLikeXML(x) && looksLikeXML(y)
+}
+
+// Describes differences of XML strings one per line, as `xmldiff` prints them.
+//   - x, y - compared XML strings
+//   - opts - comparison options
+//
+// Returns: differences or an empty string if documents are equal
+func Diff(x string, y string, opts ...xmlcomparator.Option) string {
+	var buf strings.Builder
+	_ = report.WriteText(&buf, xmlcomparator.Compare(x, y, opts...).GetStructuredDiffs(), report.ColorNever)
+	return buf.String()
+}
+
+// Reporter of `cmp.Equal` - see `cmp.Reporter`. Unequal XML strings are described by `Diff`,
+// other unequal values by their go-cmp paths and values.
+type Reporter struct {
+	opts  []xmlcomparator.Option
+	path  cmp.Path
+	diffs []string
+}
+
+// Creates a reporter for a single `cmp.Equal` call.
+//   - opts - comparison options, the same as of `Option`
+func NewReporter(opts ...xmlcomparator.Option) *Reporter {
+	return &Reporter{opts: opts}
+}
+
+// Enters a step of compared values - `cmp.Reporter` requirement.
+func (r *Reporter) PushStep(step cmp.PathStep) {
+	r.path = append(r.path, step)
+}
+
+// Records a difference of the values at the current step - `cmp.Reporter` requirement.
+func (r *Reporter) Report(result cmp.Result) {
+	if result.Equal() {
+		return
+	}
+
+	vx, vy := r.path.Last().Values()
+	if x, y, ok := xmlStrings(vx, vy); ok {
+		r.diffs = append(r.diffs, fmt.Sprintf("%#v:\n%s", r.path, strings.TrimRight(Diff(x, y, r.opts...), "\n")))
+	} else {
+		r.diffs = append(r.diffs, fmt.Sprintf("%#v:\n\t-: %s\n\t+: %s", r.path, formatValue(vx), formatValue(vy)))
+	}
+}
+
+// Leaves the step of compared values - `cmp.Reporter` requirement.
+func (r *Reporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+// Reported differences separated by new lines; empty if values are equal.
+func (r *Reporter) String() string {
+	return strings.Join(r.diffs, "\n")
+}
+
+// Strings of the values if both are XML strings.
+func xmlStrings(vx reflect.Value, vy reflect.Value) (string, string, bool) {
+	if !vx.IsValid() || !vy.IsValid() || vx.Kind() != reflect.String || vy.Kind() != reflect.String {
+		return "", "", false
+	}
+	x, y := vx.String(), vy.String()
+	return x, y, AreXML(x, y)
+}
+
+func formatValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "<missing>"
+	}
+	return fmt.Sprintf("%+v", value)
+}
+
+func looksLikeXML(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">")
+}
@@ -0,0 +1,73 @@
+package xmlcmp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestEqual(t *testing.T) {
+	assertT := assert.New(t)
+
+	equal := Equal()
+	assertT.True(equal(`<a x="1"><b/></a>`, `<a x="1">  <b></b> </a>`))
+	assertT.False(equal(`<a x="1"/>`, `<a x="2"/>`))
+	assertT.True(equal("not xml", "not xml"))
+	assertT.False(equal("not xml", "not xml either"))
+
+	assertT.True(Equal(xmlcomparator.WithIgnoreOrder())(`<a><b/><c/></a>`, `<a><c/><b/></a>`))
+}
+
+func TestAreXML(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.True(AreXML(`<a/>`, " <b>1</b>\n"))
+	assertT.False(AreXML(`<a/>`, "a"))
+	assertT.False(AreXML("", `<a/>`))
+}
+
+func TestDiff(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Empty(Diff(`<a><b/></a>`, `<a><b></b></a>`))
+	assertT.Equal("~ /a @x: '1' -> '2'\n", Diff(`<a x="1"/>`, `<a x="2"/>`))
+	assertT.Empty(Diff(`<a x="1"/>`, `<a x="2"/>`, xmlcomparator.WithIgnoredAttributes("x")))
+}
+
+type message struct {
+	ID      int
+	Payload string
+	Tags    []string
+}
+
+func TestOption(t *testing.T) {
+	assertT := assert.New(t)
+
+	want := message{ID: 1, Payload: `<a x="1"><b/><c/></a>`, Tags: []string{"<x>"}}
+	got := message{ID: 1, Payload: `<a x="1"> <c></c><b/> </a>`, Tags: []string{"<x>"}}
+	assertT.True(cmp.Equal(want, got, Option(xmlcomparator.WithIgnoreOrder())))
+	assertT.False(cmp.Equal(want, got, Option()))
+	assertT.False(cmp.Equal(want, got))
+
+	got.Tags = []string{"<y>"}
+	assertT.False(cmp.Equal(want, got, Option(xmlcomparator.WithIgnoreOrder())))
+}
+
+func TestReporter(t *testing.T) {
+	assertT := assert.New(t)
+
+	want := message{ID: 1, Payload: `<a x="1"><b/></a>`}
+	got := message{ID: 2, Payload: `<a x="2"><b></b></a>`}
+
+	reporter := NewReporter()
+	assertT.False(cmp.Equal(want, got, Option(), cmp.Reporter(reporter)))
+	assertT.Equal("{xmlcmp.message}.ID:\n\t-: 1\n\t+: 2\n{xmlcmp.message}.Payload:\n~ /a @x: '1' -> '2'", reporter.String())
+
+	reporter = NewReporter(xmlcomparator.WithIgnoredAttributes("x"))
+	got.ID = 1
+	assertT.True(cmp.Equal(want, got, Option(xmlcomparator.WithIgnoredAttributes("x")), cmp.Reporter(reporter)))
+	assertT.Empty(reporter.String())
+}
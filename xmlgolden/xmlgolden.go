@@ -0,0 +1,122 @@
+// Package xmlgolden compares XML documents produced by tests with golden files:
+//
+//	func TestExport(t *testing.T) {
+//		xmlgolden.Assert(t, export(), "testdata/export.golden.xml")
+//	}
+//
+// Golden files are created or rewritten with the actual documents when tests run with `-update` flag, e.g.
+// `go test ./export -update` - the flag is defined only in test binaries of packages importing `xmlgolden`.
+package xmlgolden
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+var update = flag.Bool("update", false, "rewrite XML golden files with actual documents")
+
+// Test state the assertions report failures to, e.g. `*testing.T`.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Function converting parsed actual document to the content of a golden file.
+type Normalizer func(node *xmlcomparator.Node) (string, error)
+
+// Assertion option.
+type Option func(*settings)
+
+type settings struct {
+	compareOpts []xmlcomparator.Option
+	normalize   Normalizer
+}
+
+// Options of comparing the actual document with the golden one.
+func WithCompareOptions(opts ...xmlcomparator.Option) Option {
+	return func(s *settings) {
+		s.compareOpts = append(s.compareOpts, opts...)
+	}
+}
+
+// Sets the conversion of the actual document written to a golden file in update mode.
+// By default documents are pretty-printed with two spaces indentation - see `PrettyNormalizer`.
+func WithNormalizer(normalize Normalizer) Option {
+	return func(s *settings) {
+		s.normalize = normalize
+	}
+}
+
+// Normalizer pretty-printing documents with the indentation unit.
+func PrettyNormalizer(indent string) Normalizer {
+	return func(node *xmlcomparator.Node) (string, error) {
+		return node.Pretty(indent) + "\n", nil
+	}
+}
+
+// Normalizer writing Canonical XML of documents.
+func CanonicalNormalizer() Normalizer {
+	return func(node *xmlcomparator.Node) (string, error) {
+		return node.Canonical() + "\n", nil
+	}
+}
+
+// Asserts that the actual document is semantically equal to the golden file. With `-update` flag the golden file
+// is written with the normalized actual document instead.
+//   - t - test state
+//   - actual - XML string produced by the test
+//   - path - path of the golden file
+//   - opts - assertion options
+//
+// Returns: whether the assertion succeeded
+func Assert(t TestingT, actual string, path string, opts ...Option) bool {
+	t.Helper()
+	s := settings{normalize: PrettyNormalizer("  ")}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if *update {
+		if err := write(actual, path, s.normalize); err != nil {
+			t.Errorf("Can't update golden file %s: %v", path, err)
+			return false
+		}
+		return true
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("Can't read golden file: %v (run tests with -update to create it)", err)
+		return false
+	}
+	diffs := xmlcomparator.Compare(string(golden), actual, s.compareOpts...).GetStructuredDiffs()
+	if len(diffs) == 0 {
+		return true
+	}
+
+	var buf strings.Builder
+	_ = report.WriteText(&buf, diffs, report.ColorNever)
+	t.Errorf("XML document differs from golden file %s (run tests with -update to accept changes):\n%s", path, buf.String())
+	return false
+}
+
+func write(actual string, path string, normalize Normalizer) error {
+	node, err := xmlcomparator.UnmarshalXMLString(actual)
+	if err != nil {
+		return fmt.Errorf("can't parse actual document: %w", err)
+	}
+	content, err := normalize(node)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
@@ -0,0 +1,74 @@
+package xmlgolden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Test state collecting failure messages.
+type mockT struct {
+	messages []string
+}
+
+func (t *mockT) Helper() {}
+
+func (t *mockT) Errorf(format string, args ...any) {
+	t.messages = append(t.messages, fmt.Sprintf(format, args...))
+}
+
+func setUpdate(t *testing.T, value bool) {
+	saved := *update
+	*update = value
+	t.Cleanup(func() { *update = saved })
+}
+
+func TestAssert(t *testing.T) {
+	assertT := assert.New(t)
+	path := filepath.Join(t.TempDir(), "doc.golden.xml")
+	assertT.NoError(os.WriteFile(path, []byte("<a x=\"1\">\n  <b>1</b>\n</a>\n"), 0o644))
+
+	mock := &mockT{}
+	assertT.True(Assert(mock, `<a x="1"><b>1</b></a>`, path))
+	assertT.True(Assert(mock, `<a x="2"><b>1</b></a>`, path,
+		WithCompareOptions(xmlcomparator.WithIgnoredAttributes("x"))))
+	assertT.Empty(mock.messages)
+
+	assertT.False(Assert(mock, `<a x="2"><b>1</b></a>`, path))
+	assertT.Equal([]string{"XML document differs from golden file " + path +
+		" (run tests with -update to accept changes):\n~ /a @x: '1' -> '2'\n"}, mock.messages)
+
+	mock = &mockT{}
+	assertT.False(Assert(mock, `<a/>`, filepath.Join(t.TempDir(), "missing.xml")))
+	assertT.Len(mock.messages, 1)
+	assertT.Contains(mock.messages[0], "run tests with -update to create it")
+}
+
+func TestUpdate(t *testing.T) {
+	assertT := assert.New(t)
+	setUpdate(t, true)
+	path := filepath.Join(t.TempDir(), "testdata", "doc.golden.xml")
+
+	mock := &mockT{}
+	assertT.True(Assert(mock, `<a x="1"><b>1</b><c/></a>`, path))
+	content, err := os.ReadFile(path)
+	assertT.NoError(err)
+	assertT.Equal("<a x=\"1\">\n  <b>1</b>\n  <c/>\n</a>\n", string(content))
+
+	assertT.True(Assert(mock, `<a x="1"><b>1</b></a>`, path, WithNormalizer(CanonicalNormalizer())))
+	content, err = os.ReadFile(path)
+	assertT.NoError(err)
+	assertT.Equal("<a x=\"1\"><b>1</b></a>\n", string(content))
+
+	assertT.False(Assert(mock, `<a>`, path))
+	assertT.Len(mock.messages, 1)
+	assertT.Contains(mock.messages[0], "Can't update golden file "+path+": can't parse actual document")
+
+	setUpdate(t, false)
+	assertT.True(Assert(mock, `<a x="1"> <b>1</b> </a>`, path))
+}
@@ -0,0 +1,18 @@
+module github.com/aknopov/xmlcomparator/xmlhtml
+
+go 1.23
+
+require (
+	github.com/aknopov/xmlcomparator v0.0.0
+	github.com/stretchr/testify v1.10.0
+	golang.org/x/net v0.33.0
+)
+
+require (
+	github.com/aknopov/handymaps v0.0.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/aknopov/xmlcomparator => ../
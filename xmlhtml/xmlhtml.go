@@ -0,0 +1,145 @@
+// Package xmlhtml compares rendered HTML pages, emails and fragments, which are rarely well-formed XML, with
+// xmlcomparator. Documents are parsed by the HTML5 parser of golang.org/x/net/html, so broken markup is repaired
+// the same way browsers do it. The package is a separate module to keep the comparator free of the dependency.
+package xmlhtml
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+// Boolean HTML attributes, which presence rather than value matters
+var booleanAttrs = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true, "autoplay": true, "checked": true, "compact": true,
+	"controls": true, "default": true, "defer": true, "disabled": true, "formnovalidate": true, "hidden": true,
+	"inert": true, "ismap": true, "itemscope": true, "loop": true, "multiple": true, "muted": true, "nohref": true,
+	"noresize": true, "noshade": true, "novalidate": true, "nowrap": true, "open": true, "playsinline": true,
+	"readonly": true, "required": true, "reversed": true, "selected": true,
+}
+
+// Namespace URIs of foreign elements and attributes by names used by the parser
+var namespaceURIs = map[string]string{
+	"svg":   "http://www.w3.org/2000/svg",
+	"math":  "http://www.w3.org/1998/Math/MathML",
+	"xlink": "http://www.w3.org/1999/xlink",
+	"xml":   "http://www.w3.org/XML/1998/namespace",
+	"xmlns": "xmlns",
+}
+
+// Start of a whole document rather than a fragment
+var documentStart = regexp.MustCompile(`(?is)^\s*(<!--.*?-->\s*)*<(!doctype|html[\s/>])`)
+
+// Context of fragments - elements and texts are parsed as if they were the content of `<body>`
+var bodyContext = &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+// Parses HTML document or fragment into a tree of nodes. Markup is repaired by the rules of HTML5 parsing:
+// names are lower case, void elements (`<br>`, `<img>`, ...) have no children, unclosed and misnested elements
+// are closed and reopened as browsers do it, implied `<head>` and `<body>` are added to documents and entities
+// are resolved. Comments and the doctype are skipped, and boolean attributes like `disabled` have empty values
+// regardless of the way they are written.
+//
+// Input starting with a doctype or `<html>` is a document with the root "html". Otherwise it's a fragment
+// of body content; a fragment with several top level elements or text is wrapped into the element "fragment".
+//   - htmlString - HTML to parse
+//
+// Returns: root node of the tree and error if any
+func Unmarshal(htmlString string) (*xmlcomparator.Node, error) {
+	if documentStart.MatchString(htmlString) {
+		doc, err := html.Parse(strings.NewReader(htmlString))
+		if err != nil {
+			return nil, err
+		}
+		for child := doc.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode {
+				return convert(child), nil
+			}
+		}
+		return nil, errors.New("no HTML content")
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(htmlString), bodyContext)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]xmlcomparator.NodeItem, 0, len(nodes))
+	for _, node := range nodes {
+		items = appendContent(items, node)
+	}
+	fragment := xmlcomparator.E("fragment", items...)
+
+	if strings.TrimSpace(fragment.CharData) == "" {
+		switch len(fragment.Children) {
+		case 0:
+			return nil, errors.New("no HTML content")
+		case 1:
+			return fragment.Children[0].Clone(), nil
+		}
+	}
+	return fragment, nil
+}
+
+// Compares HTML documents or fragments parsed with `Unmarshal`.
+//   - sample1 - first HTML string
+//   - sample2 - second HTML string
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func Compare(sample1 string, sample2 string, opts ...xmlcomparator.Option) xmlcomparator.DiffRecorder {
+	return xmlcomparator.CompareParsed(sample1, sample2, Unmarshal, opts...)
+}
+
+// Converts the element with its subtree.
+func convert(element *html.Node) *xmlcomparator.Node {
+	items := make([]xmlcomparator.NodeItem, 0, len(element.Attr))
+	items = appendAttrs(items, element)
+	for child := element.FirstChild; child != nil; child = child.NextSibling {
+		items = appendContent(items, child)
+	}
+	return xmlcomparator.E(clarkName(element.Namespace, element.Data), items...)
+}
+
+// Adds child elements and texts to items of the element; comments and doctypes are skipped.
+func appendContent(items []xmlcomparator.NodeItem, child *html.Node) []xmlcomparator.NodeItem {
+	switch child.Type {
+	case html.ElementNode:
+		return append(items, convert(child))
+	case html.TextNode:
+		return append(items, xmlcomparator.Text(child.Data))
+	}
+	return items
+}
+
+// Adds attributes with normalized values of boolean attributes to items of the element;
+// repeated attributes are dropped.
+func appendAttrs(items []xmlcomparator.NodeItem, element *html.Node) []xmlcomparator.NodeItem {
+	seen := make(map[string]bool, len(element.Attr))
+	for _, attr := range element.Attr {
+		name := clarkName(attr.Namespace, attr.Key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		value := attr.Val
+		if attr.Namespace == "" && element.Namespace == "" && booleanAttrs[attr.Key] {
+			value = ""
+		}
+		items = append(items, xmlcomparator.A(name, value))
+	}
+	return items
+}
+
+// Name in Clark notation `{uri}name` for names in foreign content like SVG.
+func clarkName(namespace string, name string) string {
+	if uri, ok := namespaceURIs[namespace]; ok {
+		return "{" + uri + "}" + name
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package xmlhtml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalDocument(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, err := Unmarshal(`<!DOCTYPE html><!-- page --><HTML><Body BGCOLOR=white>` +
+		`<P class=x>a &nbsp;&copy; 1 < 2<br><INPUT type=checkbox CHECKED><p>b</p>` +
+		`<script>if (a < b && c) {}</script></body></html>`)
+	assertT.NoError(err)
+	assertT.Equal(`<html><head></head><body bgcolor="white"><p class="x">a `+"\u00a0\u00a9"+` 1 &lt; 2<br></br>`+
+		`<input checked="" type="checkbox"></input></p><p>b</p><script>if (a &lt; b &amp;&amp; c) {}</script></body></html>`,
+		root.Canonical())
+	assertT.Equal("html", root.XMLName.Local)
+	assertT.Same(root, root.Children[1].Parent)
+	assertT.NotZero(root.Hash)
+}
+
+func TestUnmarshalFragment(t *testing.T) {
+	assertT := assert.New(t)
+
+	// Unquoted values end with whitespace or '>' only
+	root, err := Unmarshal(`<img src=x.png alt=foo/>`)
+	assertT.NoError(err)
+	assertT.Equal(`<img alt="foo/" src="x.png"></img>`, root.Canonical())
+	assertT.Nil(root.Parent)
+
+	root, err = Unmarshal(`<div attr="a<b">x</div>`)
+	assertT.NoError(err)
+	assertT.Equal(`<div attr="a&lt;b">x</div>`, root.Canonical())
+
+	// Misnested elements are closed and reopened
+	root, err = Unmarshal(`<b>bold <i>both</b> italic</i>`)
+	assertT.NoError(err)
+	assertT.Equal(`<fragment><b>bold <i>both</i></b><i> italic</i></fragment>`, root.Canonical())
+
+	root, err = Unmarshal(`<ul><li>one<li>two</ul>`)
+	assertT.NoError(err)
+	assertT.Equal(`<ul><li>one</li><li>two</li></ul>`, root.Canonical())
+
+	root, err = Unmarshal(`just text`)
+	assertT.NoError(err)
+	assertT.Equal(`<fragment>just text</fragment>`, root.Canonical())
+
+	root, err = Unmarshal(`<svg viewBox="0 0 1 1"><use xlink:href="#a"/></svg>`)
+	assertT.NoError(err)
+	assertT.Equal("http://www.w3.org/2000/svg", root.XMLName.Space)
+	assertT.Equal("viewBox", root.Attrs[0].Name.Local)
+	value, found := root.Children[0].GetAttrNS("http://www.w3.org/1999/xlink", "href")
+	assertT.True(found)
+	assertT.Equal("#a", value)
+
+	_, err = Unmarshal(" <!-- nothing --> ")
+	assertT.EqualError(err, "no HTML content")
+}
+
+func TestCompare(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Empty(Compare(`<P><INPUT disabled>Hello<BR></P>`, `<p><input disabled="disabled"/>Hello<br/></p>`).GetDiffs())
+	assertT.Empty(Compare(`<select><option selected>a<option>b</select>`,
+		`<select><option selected="">a</option><option>b</option></select>`).GetDiffs())
+	assertT.Empty(Compare(`<b>bold <i>both</b> italic</i>`, `<b>bold <i>both</i></b><i> italic</i>`).GetDiffs())
+	assertT.Equal([]string{"Node texts differ: 'Hello' vs 'Bye', path='/p'"},
+		Compare(`<p>Hello<br></p>`, `<p>Bye<br></p>`).GetMessages())
+	assertT.Equal([]string{"Can't parse the second sample: no HTML content"}, Compare(`<p/>`, ``).GetMessages())
+}
@@ -0,0 +1,21 @@
+module github.com/aknopov/xmlcomparator/xmlmatcher
+
+go 1.23
+
+require (
+	github.com/aknopov/xmlcomparator v0.0.0
+	github.com/onsi/gomega v1.36.2
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/aknopov/handymaps v0.0.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/aknopov/xmlcomparator => ../
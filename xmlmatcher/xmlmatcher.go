@@ -0,0 +1,97 @@
+// Package xmlmatcher provides Gomega matcher of XML documents:
+//
+//	Expect(response).To(xmlmatcher.MatchXML(expected, xmlcomparator.WithIgnoreOrder()))
+//
+// The package is a separate module to keep the comparator free of the dependency on Gomega.
+package xmlmatcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"github.com/aknopov/xmlcomparator"
+	"github.com/aknopov/xmlcomparator/report"
+)
+
+var _ types.GomegaMatcher = (*XMLMatcher)(nil)
+
+// Matcher of XML documents equality according to comparison options.
+type XMLMatcher struct {
+	Expected any
+	opts     []xmlcomparator.Option
+	// Differences found by the last match
+	diffs xmlcomparator.DiffList
+}
+
+// Creates a matcher succeeding when the actual document is equal to the expected one.
+//   - expected - XML document as a string, a byte slice or a `*xmlcomparator.Node`
+//   - opts - comparison options
+//
+// Returns: Gomega matcher
+func MatchXML(expected any, opts ...xmlcomparator.Option) *XMLMatcher {
+	return &XMLMatcher{Expected: expected, opts: opts}
+}
+
+// Compares the actual document with the expected one.
+//   - actual - XML document as a string, a byte slice or a `*xmlcomparator.Node`
+//
+// Returns: whether documents are equal, or an error if any of them is not a well-formed XML document
+func (matcher *XMLMatcher) Match(actual any) (bool, error) {
+	expectedXML, err := toXML(matcher.Expected)
+	if err != nil {
+		return false, fmt.Errorf("MatchXML expected value: %w", err)
+	}
+	actualXML, err := toXML(actual)
+	if err != nil {
+		return false, fmt.Errorf("MatchXML actual value: %w", err)
+	}
+
+	matcher.diffs = xmlcomparator.Compare(expectedXML, actualXML, matcher.opts...).GetStructuredDiffs()
+	if failed := matcher.diffs.OfKind(xmlcomparator.ParseFailed); len(failed) != 0 {
+		return false, fmt.Errorf("MatchXML: %s", failed[0].Message)
+	}
+	return len(matcher.diffs) == 0, nil
+}
+
+// Message for a failed `Should`/`To` assertion - documents followed by differences one per line.
+func (matcher *XMLMatcher) FailureMessage(actual any) string {
+	var buf strings.Builder
+	_ = report.WriteText(&buf, matcher.diffs, report.ColorNever)
+	return "Expected\n" + indented(actual) + "\nto match XML\n" + indented(matcher.Expected) +
+		"\ndifferences:\n" + indent(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// Message for a failed `ShouldNot`/`NotTo` assertion.
+func (matcher *XMLMatcher) NegatedFailureMessage(actual any) string {
+	return "Expected\n" + indented(actual) + "\nnot to match XML\n" + indented(matcher.Expected)
+}
+
+func toXML(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case *xmlcomparator.Node:
+		if v == nil {
+			return "", fmt.Errorf("nil node")
+		}
+		return v.XML()
+	default:
+		return "", fmt.Errorf("unsupported type %T - string, []byte or *xmlcomparator.Node expected", value)
+	}
+}
+
+func indented(value any) string {
+	text, err := toXML(value)
+	if err != nil {
+		text = fmt.Sprint(value)
+	}
+	return indent(text)
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(text, "\n", "\n    ")
+}
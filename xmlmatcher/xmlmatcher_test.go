@@ -0,0 +1,71 @@
+package xmlmatcher
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aknopov/xmlcomparator"
+)
+
+func TestMatchXML(t *testing.T) {
+	assertT := assert.New(t)
+
+	success, err := MatchXML(`<a x="1"><b/></a>`).Match([]byte(`<a x="1">  <b></b> </a>`))
+	assertT.NoError(err)
+	assertT.True(success)
+
+	success, err = MatchXML(`<a><b/><c/></a>`, xmlcomparator.WithIgnoreOrder()).Match(`<a><c/><b/></a>`)
+	assertT.NoError(err)
+	assertT.True(success)
+
+	node, err := xmlcomparator.UnmarshalXMLString(`<a><b>1</b></a>`)
+	assertT.NoError(err)
+	success, err = MatchXML(node).Match(`<a><b>1</b></a>`)
+	assertT.NoError(err)
+	assertT.True(success)
+}
+
+func TestGomegaAssertions(t *testing.T) {
+	assertT := assert.New(t)
+
+	g := gomega.NewWithT(t)
+	g.Expect(`<a x="1"><b/><c/></a>`).To(MatchXML(`<a x="1"><c/><b/></a>`, xmlcomparator.WithIgnoreOrder()))
+	g.Expect([]byte(`<a x="1"/>`)).NotTo(MatchXML(`<a x="2"/>`))
+
+	failures := make([]string, 0)
+	failing := gomega.NewGomega(func(message string, _ ...int) {
+		failures = append(failures, message)
+	})
+	failing.Expect(`<a x="2"/>`).To(MatchXML(`<a x="1"/>`))
+	failing.Expect(`<a/>`).To(MatchXML(`<a>`))
+	assertT.Equal(2, len(failures))
+	assertT.Contains(failures[0], "~ /a @x: '1' -> '2'")
+	assertT.Contains(failures[1], "MatchXML")
+}
+
+func TestFailureMessage(t *testing.T) {
+	assertT := assert.New(t)
+
+	matcher := MatchXML(`<a x="1"/>`)
+	success, err := matcher.Match(`<a x="2"/>`)
+	assertT.NoError(err)
+	assertT.False(success)
+	assertT.Equal("Expected\n    <a x=\"2\"/>\nto match XML\n    <a x=\"1\"/>\ndifferences:\n    ~ /a @x: '1' -> '2'",
+		matcher.FailureMessage(`<a x="2"/>`))
+	assertT.Equal("Expected\n    <a x=\"2\"/>\nnot to match XML\n    <a x=\"1\"/>", matcher.NegatedFailureMessage(`<a x="2"/>`))
+}
+
+func TestMatchErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	_, err := MatchXML(`<a/>`).Match(42)
+	assertT.EqualError(err, "MatchXML actual value: unsupported type int - string, []byte or *xmlcomparator.Node expected")
+
+	_, err = MatchXML((*xmlcomparator.Node)(nil)).Match(`<a/>`)
+	assertT.EqualError(err, "MatchXML expected value: nil node")
+
+	_, err = MatchXML(`<a/>`).Match(`<a>`)
+	assertT.ErrorContains(err, "MatchXML: Can't parse the second sample")
+}
@@ -0,0 +1,673 @@
+package xmlcomparator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Subset of XPath 1.0 for selecting elements of parsed trees.
+//
+// Supported are absolute and relative location paths with `/` and `//` separators, `.` and `..` steps,
+// name tests (`name`, `*`; prefixes are ignored) and predicates with positions, attributes (`@name`, `@*`),
+// `text()`, relative paths, literals, numbers, comparison operators, `and`, `or`, `not()`, `position()` and `last()`.
+
+type xpathAxis int
+
+const (
+	axisChild xpathAxis = iota
+	axisDescendant
+	axisSelf
+	axisParent
+)
+
+type xpathStep struct {
+	axis       xpathAxis
+	name       string
+	predicates []xpathExpr
+}
+
+// Compiled location path.
+type xpathPath struct {
+	absolute bool
+	steps    []xpathStep
+	// Name of attribute selected by the last step - allowed only in predicates
+	attribute string
+}
+
+// Predicate expression node.
+type xpathExpr interface {
+	eval(ctx *xpathContext) xpathValue
+}
+
+// Evaluation context of predicates.
+type xpathContext struct {
+	node     *Node
+	position int
+	size     int
+}
+
+type xpathValueKind int
+
+const (
+	valueBool xpathValueKind = iota
+	valueNumber
+	valueString
+	valueNodes
+)
+
+// Result of expression evaluation; node-sets are represented by string values of nodes.
+type xpathValue struct {
+	kind    xpathValueKind
+	boolean bool
+	number  float64
+	str     string
+	strs    []string
+}
+
+// Selects elements matching XPath expression, e.g. `//order[@status='open']/id`.
+// Relative expressions are evaluated against the node, absolute ones - against the document root.
+//   - expr - expression in the supported subset of XPath 1.0
+//
+// Returns: selected elements in document order and error if the expression is invalid
+func (node *Node) SelectAll(expr string) ([]*Node, error) {
+	path, err := compileXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.selectNodes(node), nil
+}
+
+// Selects the first element matching XPath expression - see `SelectAll`.
+//
+// Returns: the element or `nil` if nothing matches, and error if the expression is invalid
+func (node *Node) SelectFirst(expr string) (*Node, error) {
+	nodes, err := node.SelectAll(expr)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// Compiles XPath expression - panics if the expression is invalid.
+func mustCompileXPath(expr string) *xpathPath {
+	path, err := compileXPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// Compiles XPath expression.
+func compileXPath(expr string) (*xpathPath, error) {
+	parser := &xpathParser{input: expr}
+	path, err := parser.parsePath()
+	if err == nil && !parser.atEnd() {
+		err = parser.errorf("unexpected '%s'", parser.input[parser.pos:])
+	}
+	if err == nil && path.attribute != "" {
+		err = parser.errorf("path should select elements")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// Selects elements of the tree matching the path; relative paths start from `node`.
+func (path *xpathPath) selectNodes(node *Node) []*Node {
+	var context []*Node
+	if path.absolute {
+		context = nil // document node
+	} else {
+		context = []*Node{node}
+	}
+
+	root := node
+	for root.Parent != nil {
+		root = root.Parent
+	}
+
+	for i := range path.steps {
+		context = path.steps[i].apply(context, root)
+	}
+	return context
+}
+
+// Applies the step to the context nodes; `nil` context means the document node.
+func (step *xpathStep) apply(context []*Node, root *Node) []*Node {
+	if step.axis == axisDescendant {
+		return step.applyDescendant(context, root)
+	}
+	if context == nil {
+		// Document node - its only child is the root element
+		if step.axis == axisChild {
+			return step.filter([]*Node{root})
+		}
+		return []*Node{}
+	}
+
+	ret := make([]*Node, 0)
+	seen := make(map[*Node]void)
+	for _, node := range context {
+		var candidates []*Node
+		switch step.axis {
+		case axisChild:
+			candidates = childrenOf(node)
+		case axisSelf:
+			candidates = []*Node{node}
+		case axisParent:
+			if node.Parent == nil {
+				continue
+			}
+			candidates = []*Node{node.Parent}
+		}
+
+		for _, n := range step.filter(candidates) {
+			if _, ok := seen[n]; !ok {
+				seen[n] = empty
+				ret = append(ret, n)
+			}
+		}
+	}
+	return ret
+}
+
+// Applies `//step` as `descendant-or-self::node()/child::step`, so that predicates like `[1]` are evaluated
+// against children of each element rather than against all descendants.
+//
+// Returns: selected nodes in document order
+func (step *xpathStep) applyDescendant(context []*Node, root *Node) []*Node {
+	selected := make(map[*Node]void)
+	addChildren := func(parent *Node) {
+		for _, n := range step.filter(childrenOf(parent)) {
+			selected[n] = empty
+		}
+	}
+
+	if context == nil {
+		// Children of the document node
+		for _, n := range step.filter([]*Node{root}) {
+			selected[n] = empty
+		}
+		context = []*Node{root}
+	}
+	visited := make(map[*Node]void)
+	for _, node := range context {
+		node.walk(func(n *Node) bool {
+			// Subtrees of nested context nodes are visited once
+			if _, ok := visited[n]; ok {
+				return false
+			}
+			visited[n] = empty
+			addChildren(n)
+			return true
+		})
+	}
+
+	ret := make([]*Node, 0, len(selected))
+	root.walk(func(n *Node) bool {
+		if _, ok := selected[n]; ok {
+			ret = append(ret, n)
+		}
+		return true
+	})
+	return ret
+}
+
+// Filters candidates by name test and predicates.
+func (step *xpathStep) filter(candidates []*Node) []*Node {
+	ret := make([]*Node, 0, len(candidates))
+	for _, n := range candidates {
+		if step.name == "*" || step.name == nodeName(n) {
+			ret = append(ret, n)
+		}
+	}
+
+	for _, predicate := range step.predicates {
+		filtered := make([]*Node, 0, len(ret))
+		for i, n := range ret {
+			ctx := &xpathContext{node: n, position: i + 1, size: len(ret)}
+			value := predicate.eval(ctx)
+			if value.kind == valueNumber {
+				if int(value.number) == ctx.position && value.number == math.Trunc(value.number) {
+					filtered = append(filtered, n)
+				}
+			} else if value.toBool() {
+				filtered = append(filtered, n)
+			}
+		}
+		ret = filtered
+	}
+	return ret
+}
+
+func childrenOf(node *Node) []*Node {
+	ret := make([]*Node, len(node.Children))
+	for i := range node.Children {
+		ret[i] = &node.Children[i]
+	}
+	return ret
+}
+
+// XPath string value of an element - concatenated texts of the element and its descendants.
+func stringValue(node *Node) string {
+	var sb strings.Builder
+	node.walk(func(n *Node) bool {
+		sb.WriteString(n.CharData)
+		return true
+	})
+	return sb.String()
+}
+
+//------- values -------
+
+func (value xpathValue) toBool() bool {
+	switch value.kind {
+	case valueBool:
+		return value.boolean
+	case valueNumber:
+		return value.number != 0 && !math.IsNaN(value.number)
+	case valueString:
+		return value.str != ""
+	default:
+		return len(value.strs) > 0
+	}
+}
+
+func (value xpathValue) toNumber() float64 {
+	switch value.kind {
+	case valueBool:
+		if value.boolean {
+			return 1
+		}
+		return 0
+	case valueNumber:
+		return value.number
+	default:
+		return stringToNumber(value.toString())
+	}
+}
+
+func (value xpathValue) toString() string {
+	switch value.kind {
+	case valueBool:
+		return strconv.FormatBool(value.boolean)
+	case valueNumber:
+		return strconv.FormatFloat(value.number, 'f', -1, 64)
+	case valueString:
+		return value.str
+	default:
+		if len(value.strs) == 0 {
+			return ""
+		}
+		return value.strs[0]
+	}
+}
+
+func stringToNumber(s string) float64 {
+	number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return number
+}
+
+func compareValues(left xpathValue, op string, right xpathValue) bool {
+	// Node-set comparisons are existential
+	if left.kind == valueNodes {
+		for _, s := range left.strs {
+			if compareValues(xpathValue{kind: valueString, str: s}, op, right) {
+				return true
+			}
+		}
+		return false
+	}
+	if right.kind == valueNodes {
+		for _, s := range right.strs {
+			if compareValues(left, op, xpathValue{kind: valueString, str: s}) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case "=", "!=":
+		var equal bool
+		switch {
+		case left.kind == valueBool || right.kind == valueBool:
+			equal = left.toBool() == right.toBool()
+		case left.kind == valueNumber || right.kind == valueNumber:
+			equal = left.toNumber() == right.toNumber()
+		default:
+			equal = left.toString() == right.toString()
+		}
+		return equal == (op == "=")
+	case "<":
+		return left.toNumber() < right.toNumber()
+	case "<=":
+		return left.toNumber() <= right.toNumber()
+	case ">":
+		return left.toNumber() > right.toNumber()
+	default:
+		return left.toNumber() >= right.toNumber()
+	}
+}
+
+//------- expressions -------
+
+type xpathLiteral struct{ value xpathValue }
+
+type xpathBinary struct {
+	op          string
+	left, right xpathExpr
+}
+
+type xpathNot struct{ expr xpathExpr }
+
+type xpathAttribute struct{ name string }
+
+type xpathText struct{}
+
+type xpathPosition struct{}
+
+type xpathLast struct{}
+
+type xpathRelative struct{ path *xpathPath }
+
+func (expr xpathLiteral) eval(_ *xpathContext) xpathValue {
+	return expr.value
+}
+
+func (expr xpathBinary) eval(ctx *xpathContext) xpathValue {
+	switch expr.op {
+	case "or":
+		return xpathValue{kind: valueBool, boolean: expr.left.eval(ctx).toBool() || expr.right.eval(ctx).toBool()}
+	case "and":
+		return xpathValue{kind: valueBool, boolean: expr.left.eval(ctx).toBool() && expr.right.eval(ctx).toBool()}
+	default:
+		return xpathValue{kind: valueBool, boolean: compareValues(expr.left.eval(ctx), expr.op, expr.right.eval(ctx))}
+	}
+}
+
+func (expr xpathNot) eval(ctx *xpathContext) xpathValue {
+	return xpathValue{kind: valueBool, boolean: !expr.expr.eval(ctx).toBool()}
+}
+
+func (expr xpathAttribute) eval(ctx *xpathContext) xpathValue {
+	values := make([]string, 0)
+	for i := range ctx.node.Attrs {
+		attr := &ctx.node.Attrs[i]
+		if !isNameSpaceAttr(attr) && (expr.name == "*" || expr.name == attrName(attr)) {
+			values = append(values, attr.Value)
+		}
+	}
+	return xpathValue{kind: valueNodes, strs: values}
+}
+
+func (expr xpathText) eval(ctx *xpathContext) xpathValue {
+	if ctx.node.CharData == "" {
+		return xpathValue{kind: valueNodes, strs: []string{}}
+	}
+	return xpathValue{kind: valueNodes, strs: []string{ctx.node.CharData}}
+}
+
+func (expr xpathPosition) eval(ctx *xpathContext) xpathValue {
+	return xpathValue{kind: valueNumber, number: float64(ctx.position)}
+}
+
+func (expr xpathLast) eval(ctx *xpathContext) xpathValue {
+	return xpathValue{kind: valueNumber, number: float64(ctx.size)}
+}
+
+func (expr xpathRelative) eval(ctx *xpathContext) xpathValue {
+	root := ctx.node
+	for expr.path.absolute && root.Parent != nil {
+		root = root.Parent
+	}
+	nodes := expr.path.selectNodes(root)
+	if expr.path.attribute != "" {
+		values := make([]string, 0)
+		for _, n := range nodes {
+			values = append(values, xpathAttribute{expr.path.attribute}.eval(&xpathContext{node: n}).strs...)
+		}
+		return xpathValue{kind: valueNodes, strs: values}
+	}
+
+	values := make([]string, len(nodes))
+	for i, n := range nodes {
+		values[i] = stringValue(n)
+	}
+	return xpathValue{kind: valueNodes, strs: values}
+}
+
+//------- parser -------
+
+type xpathParser struct {
+	input string
+	pos   int
+}
+
+func (parser *xpathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid XPath '%s' at %d: %s", parser.input, parser.pos, fmt.Sprintf(format, args...))
+}
+
+func (parser *xpathParser) skipSpaces() {
+	for parser.pos < len(parser.input) && unicode.IsSpace(rune(parser.input[parser.pos])) {
+		parser.pos++
+	}
+}
+
+func (parser *xpathParser) atEnd() bool {
+	parser.skipSpaces()
+	return parser.pos >= len(parser.input)
+}
+
+func (parser *xpathParser) peek(token string) bool {
+	parser.skipSpaces()
+	return strings.HasPrefix(parser.input[parser.pos:], token)
+}
+
+func (parser *xpathParser) accept(token string) bool {
+	if parser.peek(token) {
+		parser.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (parser *xpathParser) parsePath() (*xpathPath, error) {
+	path := &xpathPath{steps: make([]xpathStep, 0)}
+	axis := axisChild
+	switch {
+	case parser.accept("//"):
+		path.absolute = true
+		axis = axisDescendant
+	case parser.accept("/"):
+		path.absolute = true
+	}
+
+	for {
+		if axis == axisChild && len(path.steps) > 0 && parser.accept("@") {
+			path.attribute = parser.parseName()
+			if path.attribute == "" {
+				return nil, parser.errorf("attribute name expected")
+			}
+			return path, nil
+		}
+
+		step, err := parser.parseStep(axis)
+		if err != nil {
+			return nil, err
+		}
+		path.steps = append(path.steps, step)
+
+		switch {
+		case parser.accept("//"):
+			axis = axisDescendant
+		case parser.accept("/"):
+			axis = axisChild
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (parser *xpathParser) parseStep(axis xpathAxis) (xpathStep, error) {
+	switch {
+	case parser.accept(".."):
+		return xpathStep{axis: axisParent, name: "*"}, nil
+	case parser.accept("."):
+		return xpathStep{axis: axisSelf, name: "*"}, nil
+	}
+
+	name := parser.parseName()
+	if name == "" {
+		return xpathStep{}, parser.errorf("element name expected")
+	}
+	step := xpathStep{axis: axis, name: name, predicates: make([]xpathExpr, 0)}
+
+	for parser.accept("[") {
+		expr, err := parser.parseOr()
+		if err != nil {
+			return xpathStep{}, err
+		}
+		if !parser.accept("]") {
+			return xpathStep{}, parser.errorf("']' expected")
+		}
+		step.predicates = append(step.predicates, expr)
+	}
+	return step, nil
+}
+
+// Parses a name test; namespace prefix is dropped.
+func (parser *xpathParser) parseName() string {
+	parser.skipSpaces()
+	if parser.accept("*") {
+		return "*"
+	}
+
+	start := parser.pos
+	for parser.pos < len(parser.input) {
+		c, size := utf8.DecodeRuneInString(parser.input[parser.pos:])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' && c != '.' && c != ':' {
+			break
+		}
+		parser.pos += size
+	}
+	name := parser.input[start:parser.pos]
+	if idx := strings.LastIndex(name, ":"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+func (parser *xpathParser) parseOr() (xpathExpr, error) {
+	left, err := parser.parseAnd()
+	for err == nil && parser.acceptKeyword("or") {
+		var right xpathExpr
+		if right, err = parser.parseAnd(); err == nil {
+			left = xpathBinary{op: "or", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (parser *xpathParser) parseAnd() (xpathExpr, error) {
+	left, err := parser.parseComparison()
+	for err == nil && parser.acceptKeyword("and") {
+		var right xpathExpr
+		if right, err = parser.parseComparison(); err == nil {
+			left = xpathBinary{op: "and", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (parser *xpathParser) acceptKeyword(keyword string) bool {
+	if !parser.peek(keyword) {
+		return false
+	}
+	end := parser.pos + len(keyword)
+	if end < len(parser.input) && !unicode.IsSpace(rune(parser.input[end])) && parser.input[end] != '(' {
+		return false
+	}
+	parser.pos = end
+	return true
+}
+
+func (parser *xpathParser) parseComparison() (xpathExpr, error) {
+	left, err := parser.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"!=", "<=", ">=", "=", "<", ">"} {
+		if parser.accept(op) {
+			right, err := parser.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return xpathBinary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+//nolint:cyclop // a flat list of alternatives
+func (parser *xpathParser) parsePrimary() (xpathExpr, error) {
+	parser.skipSpaces()
+	if parser.pos >= len(parser.input) {
+		return nil, parser.errorf("expression expected")
+	}
+
+	c := parser.input[parser.pos]
+	switch {
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(parser.input[parser.pos+1:], c)
+		if end < 0 {
+			return nil, parser.errorf("unterminated literal")
+		}
+		literal := parser.input[parser.pos+1 : parser.pos+1+end]
+		parser.pos += end + 2
+		return xpathLiteral{xpathValue{kind: valueString, str: literal}}, nil
+	case c >= '0' && c <= '9':
+		start := parser.pos
+		for parser.pos < len(parser.input) && (parser.input[parser.pos] >= '0' && parser.input[parser.pos] <= '9' || parser.input[parser.pos] == '.') {
+			parser.pos++
+		}
+		return xpathLiteral{xpathValue{kind: valueNumber, number: stringToNumber(parser.input[start:parser.pos])}}, nil
+	case c == '@':
+		parser.pos++
+		return xpathAttribute{name: parser.parseName()}, nil
+	case c == '(':
+		parser.pos++
+		expr, err := parser.parseOr()
+		if err == nil && !parser.accept(")") {
+			err = parser.errorf("')' expected")
+		}
+		return expr, err
+	case parser.accept("text()"):
+		return xpathText{}, nil
+	case parser.accept("position()"):
+		return xpathPosition{}, nil
+	case parser.accept("last()"):
+		return xpathLast{}, nil
+	case parser.accept("not("):
+		expr, err := parser.parseOr()
+		if err == nil && !parser.accept(")") {
+			err = parser.errorf("')' expected")
+		}
+		return xpathNot{expr}, err
+	default:
+		path, err := parser.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return xpathRelative{path}, nil
+	}
+}
@@ -0,0 +1,168 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func selectNames(assertT *assert.Assertions, root *Node, expr string) []string {
+	path, err := compileXPath(expr)
+	assertT.Nil(err)
+
+	names := make([]string, 0)
+	for _, node := range path.selectNodes(root) {
+		names = append(names, node.path())
+	}
+	return names
+}
+
+func TestXPathLocationPaths(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(xmlString2)
+
+	assertT.Equal([]string{"/root"}, selectNames(assertT, root, "/root"))
+	assertT.Equal([]string{}, selectNames(assertT, root, "/animal"))
+	assertT.Equal([]string{"/root/animal[0]", "/root/animal[2]"}, selectNames(assertT, root, "/root/animal"))
+	assertT.Equal([]string{"/root/animal[0]/dog[1]/p"}, selectNames(assertT, root, "//dog/p"))
+	assertT.Equal(5, len(selectNames(assertT, root, "//p")))
+	assertT.Equal([]string{"/root/animal[0]/p[0]", "/root/animal[2]/p"}, selectNames(assertT, root, "/root/animal/p"))
+	assertT.Equal([]string{"/root/animal[0]", "/root/birds[1]", "/root/animal[2]"}, selectNames(assertT, root, "/root/*"))
+	assertT.Equal([]string{"/root/animal[0]"}, selectNames(assertT, root, "//dog/.."))
+	assertT.Equal([]string{"/root/animal[0]/dog[1]"}, selectNames(assertT, root, "//dog/."))
+	assertT.Equal([]string{"/root/animal[0]/dog[1]/p"}, selectNames(assertT, root, "animal/dog/p"))
+	assertT.Equal([]string{"/root"}, selectNames(assertT, root, "/x:root"))
+}
+
+func TestXPathPredicates(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(xmlString2)
+
+	assertT.Equal([]string{"/root/animal[2]"}, selectNames(assertT, root, "/root/animal[2]"))
+	assertT.Equal([]string{"/root/animal[2]"}, selectNames(assertT, root, "/root/animal[last()]"))
+	assertT.Equal([]string{"/root/animal[0]"}, selectNames(assertT, root, "/root/animal[position() < 2]"))
+	assertT.Equal([]string{"/root/birds[1]/p[0]"}, selectNames(assertT, root, "//p[@class]"))
+	assertT.Equal([]string{"/root/birds[1]/p[0]"}, selectNames(assertT, root, "//p[@class='bar']"))
+	assertT.Equal([]string{"/root/birds[1]/p[0]"}, selectNames(assertT, root, "//p[@*]"))
+	assertT.Equal([]string{}, selectNames(assertT, root, "//p[@class!='bar']"))
+	assertT.Equal(4, len(selectNames(assertT, root, "//p[not(@class)]")))
+	assertT.Equal([]string{"/root/animal[0]/dog[1]/p"}, selectNames(assertT, root, "//p[text()='tommy']"))
+	assertT.Equal([]string{"/root/animal[0]"}, selectNames(assertT, root, "/root/animal[dog/p='tommy']"))
+	assertT.Equal([]string{"/root/animal[0]", "/root/animal[2]"},
+		selectNames(assertT, root, "/root/animal[p='This is dog' or p = \"this is animals\"]"))
+	assertT.Equal([]string{"/root/animal[0]"}, selectNames(assertT, root, "/root/animal[p and dog]"))
+	assertT.Equal([]string{"/root/birds[1]/p[1]"}, selectNames(assertT, root, "//birds/p[2][not(@class)]"))
+	assertT.Equal([]string{"/root"}, selectNames(assertT, root, "/root[@type='vet_hospital']"))
+	assertT.Equal([]string{"/root/birds[1]"}, selectNames(assertT, root, "//*[p/@class = /root/birds/p/@class]"))
+}
+
+func TestXPathDescendantPositions(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<r><a><item>1</item><item>2</item></a><b><item>3</item><c><item>4</item><item>5</item></c></b></r>`)
+
+	// Positions are counted among children of each parent
+	assertT.Equal([]string{"/r/a[0]/item[0]", "/r/b[1]/item[0]", "/r/b[1]/c[1]/item[0]"}, selectNames(assertT, root, "//item[1]"))
+	assertT.Equal([]string{"/r/a[0]/item[1]", "/r/b[1]/item[0]", "/r/b[1]/c[1]/item[1]"}, selectNames(assertT, root, "//item[last()]"))
+	assertT.Equal([]string{"/r/a[0]/item[1]", "/r/b[1]/c[1]/item[1]"}, selectNames(assertT, root, "//item[2]"))
+	assertT.Equal([]string{"/r/b[1]/c[1]/item[0]"}, selectNames(assertT, root, "/r/b//item[1][. = 4]"))
+	assertT.Equal([]string{"/r"}, selectNames(assertT, root, "//r[1]"))
+	assertT.Equal([]string{"/r/b[1]/c[1]/item[1]"}, selectNames(assertT, root, "//c//item[2]"))
+	// Document order regardless of nesting
+	assertT.Equal([]string{"/r/a[0]", "/r/b[1]", "/r/b[1]/c[1]"}, selectNames(assertT, root, "//*[item]"))
+}
+
+func TestXPathNumbers(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<a><b v="1.5">10</b><b v="3">2</b><b/></a>`)
+
+	assertT.Equal([]string{"/a/b[1]"}, selectNames(assertT, root, "/a/b[@v > 2]"))
+	assertT.Equal([]string{"/a/b[0]", "/a/b[1]"}, selectNames(assertT, root, "/a/b[@v <= 3]"))
+	assertT.Equal([]string{"/a/b[0]"}, selectNames(assertT, root, "/a/b[. >= 10]"))
+	assertT.Equal([]string{"/a/b[0]"}, selectNames(assertT, root, "/a/b[@v = 1.5]"))
+	assertT.Equal([]string{"/a/b[1]"}, selectNames(assertT, root, "/a/b[(@v = 3)]"))
+}
+
+func TestXPathErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	for _, expr := range []string{"", "/", "/a[", "/a[@b='c]", "/a[(@b]", "/a[not(@b]", "/a]", "/a[]", "/a/@b", "/a[b/@]"} {
+		_, err := compileXPath(expr)
+		assertT.NotNil(err, expr)
+	}
+	assertT.Panics(func() { mustCompileXPath("/a[") })
+}
+
+func TestIgnoredXPath(t *testing.T) {
+	assertT := assert.New(t)
+
+	xmlSample1 := `<response><metadata><requestId>1</requestId></metadata><data transient="true">x</data><data>y</data></response>`
+	xmlSample2 := `<response><data>y</data><metadata><requestId>2</requestId><time/></metadata><data transient="true">z</data></response>`
+
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithIgnoredXPath("//metadata", "//*[@transient='true']")).GetMessages())
+	assertT.Equal(emptyList, Compare(xmlSample1, xmlSample2, WithIgnoredXPath("/response")).GetMessages())
+	assertT.Equal([]string{"Children differ: counts 1 vs 2: time[1]:-1, path='/response/metadata[0]'",
+		"Node texts differ: '1' vs '2', path='/response/metadata[0]/requestId'"},
+		Compare(xmlSample1, xmlSample2, WithIgnoredXPath("//data"), WithIgnoreOrder()).GetMessages())
+
+	// The first item of each parent is ignored
+	assertT.Equal(emptyList, Compare(`<r><a><item>1</item><item>2</item></a><b><item>3</item></b></r>`,
+		`<r><a><item>7</item><item>2</item></a><b><item>8</item></b></r>`, WithIgnoredXPath("//item[1]")).GetMessages())
+}
+
+func TestSelectAll(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<orders><order status="open"><id>1</id></order><order status="closed"><id>2</id></order>` +
+		`<order status="open"><id>3</id></order></orders>`)
+
+	nodes, err := root.SelectAll("//order[@status='open']/id")
+	assertT.Nil(err)
+	assertT.Equal(2, len(nodes))
+	assertT.Equal("1", nodes[0].CharData)
+	assertT.Equal("3", nodes[1].CharData)
+
+	// Relative expressions start from the node, absolute - from the document root
+	order := &root.Children[1]
+	nodes, _ = order.SelectAll("id")
+	assertT.Equal([]*Node{&order.Children[0]}, nodes)
+	nodes, _ = order.SelectAll("//id")
+	assertT.Equal(3, len(nodes))
+	nodes, _ = order.SelectAll("../order[1]")
+	assertT.Equal([]*Node{&root.Children[0]}, nodes)
+
+	node, err := root.SelectFirst("//order[@status='open']")
+	assertT.Nil(err)
+	assertT.Same(&root.Children[0], node)
+	node, err = root.SelectFirst("//missing")
+	assertT.Nil(err)
+	assertT.Nil(node)
+
+	_, err = root.SelectAll("//order[")
+	assertT.NotNil(err)
+	_, err = root.SelectFirst("//order[")
+	assertT.NotNil(err)
+}
+
+func TestSelectAllPositionsPerParent(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, _ := parseXML(`<r><a><item>1</item><item>2</item></a><b><item>3</item></b><c><d><item>4</item><item>5</item></d></c></r>`)
+
+	nodes, err := root.SelectAll("//item[1]")
+	assertT.Nil(err)
+	texts := make([]string, len(nodes))
+	for i, node := range nodes {
+		texts[i] = node.CharData
+	}
+	assertT.Equal([]string{"1", "3", "4"}, texts)
+
+	nodes, _ = root.Children[2].SelectAll(".//item[last()]")
+	assertT.Equal([]*Node{&root.Children[2].Children[0].Children[1]}, nodes)
+
+	node, _ := root.SelectFirst("//item[2]")
+	assertT.Equal("2", node.CharData)
+}
@@ -0,0 +1,90 @@
+package xmlcomparator
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parses YAML string into a tree of nodes, so YAML documents are compared with the same options as XML.
+// The document is the element "root"; mapping keys become child elements in the order of the document, sequences
+// become elements with "item" children, scalars become texts and nulls become empty elements. Aliases are replaced
+// with anchored values; comments and tags are not compared.
+//   - yamlString - YAML string with a single document
+//
+// Returns: root node of the converted tree and error if any
+func UnmarshalYAMLString(yamlString string) (*Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlString), &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, errors.New("no YAML document")
+	}
+
+	root := Node{XMLName: xml.Name{Local: "root"}}
+	if err := fillYAMLElement(&root, doc.Content[0], 0); err != nil {
+		return nil, err
+	}
+	root.linkChildren()
+	root.hashCode()
+	return &root, nil
+}
+
+// Compares YAML documents converted to trees - see `UnmarshalYAMLString`. Paths in discrepancies start with "/root".
+//   - sample1 - first YAML string
+//   - sample2 - second YAML string
+//   - opts - comparison options
+//
+// Returns:
+// A list of detected discrepancies
+func CompareYAML(sample1 string, sample2 string, opts ...Option) DiffRecorder {
+	return NewComparator(opts...).CompareYAML(sample1, sample2)
+}
+
+// Compares YAML documents - see `CompareYAML`.
+func (cmp *Comparator) CompareYAML(sample1 string, sample2 string) DiffRecorder {
+	return cmp.CompareParsed(sample1, sample2, UnmarshalYAMLString)
+}
+
+// Limit of nested aliases guarding against recursive anchors
+const maxYAMLAliasDepth = 100
+
+// Converts the YAML value into content of the element.
+//   - aliases - count of aliases resolved on the way to the value
+func fillYAMLElement(node *Node, value *yaml.Node, aliases int) error {
+	switch value.Kind {
+	case yaml.AliasNode:
+		if aliases >= maxYAMLAliasDepth {
+			return fmt.Errorf("line %d: too deeply nested aliases", value.Line)
+		}
+		return fillYAMLElement(node, value.Alias, aliases+1)
+	case yaml.ScalarNode:
+		if value.Tag != "!!null" {
+			node.CharData = value.Value
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			child := Node{XMLName: xml.Name{Local: "item"}}
+			if err := fillYAMLElement(&child, item, aliases); err != nil {
+				return err
+			}
+			node.Children = append(node.Children, child)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key := value.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: scalar mapping key expected", key.Line)
+			}
+			child := Node{XMLName: xml.Name{Local: key.Value}}
+			if err := fillYAMLElement(&child, value.Content[i+1], aliases); err != nil {
+				return err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return nil
+}
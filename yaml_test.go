@@ -0,0 +1,51 @@
+package xmlcomparator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalYAMLString(t *testing.T) {
+	assertT := assert.New(t)
+
+	root, err := UnmarshalYAMLString(`
+# Service settings
+name: api
+ports: [80, 443]
+defaults: &defaults
+  timeout: 5s
+prod: *defaults
+empty: ~
+`)
+	assertT.NoError(err)
+	assertT.Equal("<root><name>api</name><ports><item>80</item><item>443</item></ports>"+
+		"<defaults><timeout>5s</timeout></defaults><prod><timeout>5s</timeout></prod><empty></empty></root>", root.Canonical())
+	assertT.Equal("/root/ports[1]/item[1]", root.Children[1].Children[1].path())
+
+	root, err = UnmarshalYAMLString(`- a`)
+	assertT.NoError(err)
+	assertT.Equal("<root><item>a</item></root>", root.Canonical())
+
+	_, err = UnmarshalYAMLString("a: [1")
+	assertT.Error(err)
+	_, err = UnmarshalYAMLString("")
+	assertT.EqualError(err, "no YAML document")
+	_, err = UnmarshalYAMLString("? [a]\n: 1\n")
+	assertT.EqualError(err, "line 1: scalar mapping key expected")
+}
+
+func TestCompareYAML(t *testing.T) {
+	assertT := assert.New(t)
+
+	yaml1 := "name: api\nversion: 1.0\nports: [80, 443]\n"
+	yaml2 := "name: api\nports:\n  - 443\n  - 80\nversion: 1.01\n"
+	assertT.Equal([]string{"Node texts differ: '1.0' vs '1.01', path='/root/version[1]'",
+		"Children order differ for 2 nodes, path='/root/ports[2]'"},
+		CompareYAML(yaml1, yaml2).GetMessages())
+	assertT.Empty(CompareYAML(yaml1, yaml2, WithIgnoreOrder(), WithNumericTolerance(0.02)).GetDiffs())
+
+	diffs := CompareYAML(yaml1, "a: [", WithIgnoreOrder()).GetStructuredDiffs()
+	assertT.Len(diffs, 1)
+	assertT.Equal(ParseFailed, diffs[0].Kind)
+}